@@ -1,15 +1,33 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
+	"time"
 
 	"modern_todo_plain/internal/app"
+	"modern_todo_plain/internal/store"
 )
 
 func main() {
-	application := app.New()
+	backend := flag.String("store", "memory", "todo storage backend: memory or file")
+	dataPath := flag.String("data", "todos.json", "data file used by the file backend")
+	autoComplete := flag.Bool("autocomplete", true, "complete a todo when all of its subtasks are done")
+	retention := flag.Duration("trash-retention", 30*24*time.Hour, "how long deleted todos stay in the trash (0 keeps them forever)")
+	flag.Parse()
+
+	todoStore, err := openStore(*backend, *dataPath, store.WithAutoComplete(*autoComplete))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *retention > 0 {
+		go purgeTrash(todoStore, *retention, min(*retention, time.Hour))
+	}
+
+	application := app.New(todoStore)
 
 	addr := ":8080"
 	fmt.Println("🚀 Modern Todo App Demo Server starting on :8080")
@@ -19,3 +37,32 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+func openStore(backend, dataPath string, opts ...store.Option) (*store.Store, error) {
+	switch backend {
+	case "memory":
+		return store.New(opts...), nil
+	case "file":
+		fmt.Printf("💾 Persisting todos to %s\n", dataPath)
+		return store.Open(store.NewFileBackend(dataPath), opts...)
+	default:
+		return nil, fmt.Errorf("unknown store backend %q (want memory or file)", backend)
+	}
+}
+
+// purgeTrash permanently removes todos that have been in the trash longer than
+// retention, checking once at startup and then every interval.
+func purgeTrash(todoStore *store.Store, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		removed, err := todoStore.PurgeTrash(time.Now().Add(-retention))
+		if err != nil {
+			log.Printf("purge trash: %v", err)
+		} else if removed > 0 {
+			log.Printf("purged %d todos from the trash", removed)
+		}
+		<-ticker.C
+	}
+}
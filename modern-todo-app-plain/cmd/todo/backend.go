@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"modern_todo_plain/internal/store"
+)
+
+// todoStore is what the commands need from the todos, whether they live in
+// the data file or behind a server.
+type todoStore interface {
+	List(query store.Query) ([]store.Todo, error)
+	Add(draft store.Draft) (store.Todo, error)
+	Patch(id string, patch store.Patch) (store.Todo, error)
+	Delete(id string) error
+}
+
+// fileStore changes the data file through the same store the server uses, so
+// validation, recurrence and the activity log behave as in the app.
+type fileStore struct {
+	store *store.Store
+}
+
+func (f fileStore) List(query store.Query) ([]store.Todo, error) {
+	return f.store.List(query), nil
+}
+
+func (f fileStore) Add(draft store.Draft) (store.Todo, error) {
+	todo, _, err := f.store.Add(draft)
+	return todo, err
+}
+
+func (f fileStore) Patch(id string, patch store.Patch) (store.Todo, error) {
+	todo, _, err := f.store.Patch(id, patch)
+	return todo, err
+}
+
+func (f fileStore) Delete(id string) error {
+	_, err := f.store.Delete(id)
+	return err
+}
+
+// apiPageSize is the page size ls asks the server for; pages are followed
+// until the list is complete.
+const apiPageSize = 200
+
+// apiStore talks to the /api/v1/todos resource of a running server.
+type apiStore struct {
+	base   string
+	client *http.Client
+}
+
+func newAPIStore(base string) *apiStore {
+	return &apiStore{
+		base:   strings.TrimSuffix(base, "/") + "/api/v1/todos",
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// apiError is the server's error envelope.
+type apiError struct {
+	Error struct {
+		Code    string            `json:"code"`
+		Message string            `json:"message"`
+		Fields  map[string]string `json:"fields"`
+	} `json:"error"`
+}
+
+func (a *apiStore) List(query store.Query) ([]store.Todo, error) {
+	params := url.Values{}
+	if query.Filter != "" {
+		params.Set("filter", string(query.Filter))
+	}
+	for _, tag := range query.Tags {
+		params.Add("tag", tag)
+	}
+	if query.Search != "" {
+		params.Set("q", query.Search)
+	}
+	if query.Sort != "" {
+		params.Set("sort", string(query.Sort))
+	}
+	if query.ProjectID != "" {
+		params.Set("list", query.ProjectID)
+	}
+	params.Set("limit", fmt.Sprint(apiPageSize))
+
+	todos := []store.Todo{}
+	for {
+		var page struct {
+			Data       []store.Todo `json:"data"`
+			NextCursor string       `json:"next_cursor"`
+		}
+		if err := a.do(http.MethodGet, "?"+params.Encode(), nil, &page); err != nil {
+			return nil, err
+		}
+		todos = append(todos, page.Data...)
+		if page.NextCursor == "" {
+			return todos, nil
+		}
+		params = url.Values{"cursor": {page.NextCursor}, "limit": {fmt.Sprint(apiPageSize)}}
+	}
+}
+
+func (a *apiStore) Add(draft store.Draft) (store.Todo, error) {
+	body := map[string]any{
+		"title":       draft.Title,
+		"description": draft.Description,
+		"priority":    draft.Priority,
+		"tags":        draft.Tags,
+		"recurrence":  draft.Recurrence,
+		"project_id":  draft.ProjectID,
+	}
+	if draft.DueAt != nil {
+		body["due_at"] = draft.DueAt
+		body["due_zone"] = draft.DueZone
+	}
+	if draft.Completed {
+		body["completed"] = true
+	}
+	var todo store.Todo
+	err := a.do(http.MethodPost, "", body, &todo)
+	return todo, err
+}
+
+func (a *apiStore) Patch(id string, patch store.Patch) (store.Todo, error) {
+	body := map[string]any{}
+	set := func(name string, value any, ok bool) {
+		if ok {
+			body[name] = value
+		}
+	}
+	set("title", patch.Title, patch.Title != nil)
+	set("description", patch.Description, patch.Description != nil)
+	set("priority", patch.Priority, patch.Priority != nil)
+	set("completed", patch.Completed, patch.Completed != nil)
+	set("due_at", patch.DueAt, patch.DueAt != nil || patch.ClearDue)
+	set("due_zone", patch.DueZone, patch.DueZone != nil)
+	set("tags", patch.Tags, patch.Tags != nil)
+	set("recurrence", patch.Recurrence, patch.Recurrence != nil)
+	set("project_id", patch.ProjectID, patch.ProjectID != nil)
+
+	var todo store.Todo
+	err := a.do(http.MethodPatch, "/"+url.PathEscape(id), body, &todo)
+	return todo, err
+}
+
+func (a *apiStore) Delete(id string) error {
+	return a.do(http.MethodDelete, "/"+url.PathEscape(id), nil, nil)
+}
+
+// do sends a request to the todos resource and decodes the JSON response into
+// out. Error responses are returned as errors carrying the server's message.
+func (a *apiStore) do(method, path string, body, out any) error {
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, a.base+path, &payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		var envelope apiError
+		if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil || envelope.Error.Message == "" {
+			return fmt.Errorf("%s %s: %s", method, req.URL.Path, resp.Status)
+		}
+		return envelope.err()
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// err formats the envelope like the store's own errors, listing any invalid
+// fields.
+func (e apiError) err() error {
+	if len(e.Error.Fields) == 0 {
+		return fmt.Errorf("%s", e.Error.Message)
+	}
+	names := make([]string, 0, len(e.Error.Fields))
+	for name := range e.Error.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + ": " + e.Error.Fields[name]
+	}
+	return fmt.Errorf("%s: %s", e.Error.Message, strings.Join(parts, "; "))
+}
@@ -0,0 +1,393 @@
+// Command todo manages todos from the terminal. It works on the server's data
+// file directly or, with -server, through the JSON API of a running server.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"modern_todo_plain/internal/store"
+)
+
+const usage = `Usage: todo [-data file | -server url] [-json] <command> [flags] [args]
+
+Commands:
+  add <text>           add a todo; the text is read like the quick-add box,
+                       e.g. todo add Pay invoice tomorrow 5pm !high #finance
+  ls                   list todos (-filter, -tag, -q, -sort, -list)
+  done <id>...         complete todos (-reopen to reopen them)
+  edit <id>            change a todo (-title, -desc, -priority, -due, -tags,
+                       -repeat, -list)
+  rm <id>...           move todos to the trash
+  export               write todos as json, csv, todotxt, md or ics (-format,
+                       -o, and the ls flags)
+
+Without -server the data file is changed directly, so do not use it while a
+server has the same file open; point -server at that server instead.
+
+Global flags:
+`
+
+// cli runs one command against a todo store.
+type cli struct {
+	store todoStore
+	out   io.Writer
+	json  bool
+	// now is read in zone, for relative due dates such as "tomorrow".
+	now  time.Time
+	zone string
+}
+
+func main() {
+	global := flag.NewFlagSet("todo", flag.ExitOnError)
+	dataPath := global.String("data", envOr("TODO_DATA", "todos.json"), "data file of the server's file backend (env TODO_DATA)")
+	server := global.String("server", os.Getenv("TODO_SERVER"), "base URL of a running server, e.g. http://localhost:8080 (env TODO_SERVER)")
+	asJSON := global.Bool("json", false, "print JSON instead of a table")
+	global.Usage = func() {
+		fmt.Fprint(global.Output(), usage)
+		global.PrintDefaults()
+	}
+	_ = global.Parse(os.Args[1:])
+
+	args := global.Args()
+	if len(args) == 0 {
+		global.Usage()
+		os.Exit(2)
+	}
+	run, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "todo: unknown command %q\n\n", args[0])
+		global.Usage()
+		os.Exit(2)
+	}
+
+	todoStore, err := openTodoStore(*dataPath, *server)
+	if err != nil {
+		fail(err)
+	}
+	zone := localZone()
+	loc, _ := time.LoadLocation(zone)
+	c := &cli{store: todoStore, out: os.Stdout, json: *asJSON, now: time.Now().In(loc), zone: zone}
+	if err := run(c, args[0], args[1:]); err != nil {
+		fail(err)
+	}
+}
+
+var commands = map[string]func(c *cli, name string, args []string) error{
+	"add":    (*cli).add,
+	"ls":     (*cli).list,
+	"done":   (*cli).done,
+	"edit":   (*cli).edit,
+	"rm":     (*cli).remove,
+	"export": (*cli).export,
+}
+
+func openTodoStore(dataPath, server string) (todoStore, error) {
+	if server != "" {
+		return newAPIStore(server), nil
+	}
+	// The CLI never creates the data file: a mistyped -data path would
+	// otherwise get a fresh file of demo todos.
+	s, err := store.Open(store.NewFileBackend(dataPath), store.WithSeed(false))
+	if errors.Is(err, store.ErrNoData) {
+		return nil, fmt.Errorf("no data file at %s; start the server once to create it, or pass -data or -server", dataPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return fileStore{s}, nil
+}
+
+// flags returns the flag set of a command. Every command accepts -json, so it
+// can follow the command name too.
+func (c *cli) flags(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.BoolVar(&c.json, "json", c.json, "print JSON instead of a table")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: todo %s [flags] %s\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func (c *cli) add(name string, args []string) error {
+	fs := c.flags(name, "<text>")
+	description := fs.String("desc", "", "description")
+	list := fs.String("list", "", "list id (default the inbox)")
+	_ = fs.Parse(args)
+
+	text := strings.Join(fs.Args(), " ")
+	if strings.TrimSpace(text) == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	draft := store.ParseQuickAdd(text, c.now)
+	if draft.DueAt != nil {
+		draft.DueZone = c.zone
+	}
+	draft.Description = *description
+	draft.ProjectID = *list
+
+	todo, err := c.store.Add(draft)
+	if err != nil {
+		return err
+	}
+	return c.print([]store.Todo{todo}, todo)
+}
+
+var (
+	filters = []store.Filter{store.FilterAll, store.FilterActive, store.FilterCompleted, store.FilterOverdue, store.FilterThisWeek, store.FilterTrash}
+	sorts   = []store.Sort{store.SortManual, store.SortCreated, store.SortPriority, store.SortDue}
+)
+
+// queryFlags registers the view flags shared by ls and export. The returned
+// function reads them after parsing; an unknown -filter or -sort is a usage
+// error, like an unknown flag.
+func queryFlags(fs *flag.FlagSet) func() store.Query {
+	filter := fs.String("filter", "all", "all, active, completed, overdue, week or trash")
+	var tags stringList
+	fs.Var(&tags, "tag", "only todos with this tag (repeatable)")
+	search := fs.String("q", "", "search text")
+	order := fs.String("sort", "manual", "manual, created, priority or due")
+	list := fs.String("list", "", "only todos in this list")
+	return func() store.Query {
+		query := store.Query{
+			Filter:    store.Filter(strings.ToLower(*filter)),
+			Tags:      store.NormalizeTags(tags),
+			Search:    *search,
+			Sort:      store.Sort(strings.ToLower(*order)),
+			ProjectID: *list,
+		}
+		if !slices.Contains(filters, query.Filter) {
+			usageError(fs, "unknown filter %q", *filter)
+		}
+		if !slices.Contains(sorts, query.Sort) {
+			usageError(fs, "unknown sort %q", *order)
+		}
+		return query
+	}
+}
+
+// usageError reports a bad flag value the way the flag package reports a bad
+// flag, and exits with status 2.
+func usageError(fs *flag.FlagSet, format string, args ...any) {
+	fmt.Fprintf(fs.Output(), format+"\n", args...)
+	fs.Usage()
+	os.Exit(2)
+}
+
+func (c *cli) list(name string, args []string) error {
+	fs := c.flags(name, "")
+	query := queryFlags(fs)
+	_ = fs.Parse(args)
+
+	todos, err := c.store.List(query())
+	if err != nil {
+		return err
+	}
+	return c.print(todos, todos)
+}
+
+func (c *cli) done(name string, args []string) error {
+	fs := c.flags(name, "<id>...")
+	reopen := fs.Bool("reopen", false, "reopen the todos instead")
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	completed := !*reopen
+	var todos []store.Todo
+	for _, id := range fs.Args() {
+		todo, err := c.store.Patch(id, store.Patch{Completed: &completed})
+		if err != nil {
+			return fmt.Errorf("%s: %w", id, err)
+		}
+		todos = append(todos, todo)
+	}
+	return c.print(todos, todos)
+}
+
+func (c *cli) edit(name string, args []string) error {
+	fs := c.flags(name, "<id>")
+	title := fs.String("title", "", "new title")
+	description := fs.String("desc", "", "new description")
+	priority := fs.String("priority", "", "low, medium or high")
+	due := fs.String("due", "", `due date, e.g. "friday 5pm" or 2024-06-01; "none" clears it`)
+	tags := fs.String("tags", "", "comma-separated tags, replacing the current ones")
+	repeat := fs.String("repeat", "", `RRULE such as "FREQ=WEEKLY;BYDAY=MO"; "none" stops repeating`)
+	list := fs.String("list", "", "move to this list")
+	// The id may come before or after the flags.
+	var id string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		id, args = args[0], args[1:]
+	}
+	_ = fs.Parse(args)
+	if id == "" && fs.NArg() == 1 {
+		id = fs.Arg(0)
+	} else if id == "" || fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var patch store.Patch
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "title":
+			patch.Title = title
+		case "desc":
+			patch.Description = description
+		case "priority":
+			p := store.Priority(strings.ToLower(*priority))
+			patch.Priority = &p
+		case "due":
+			err = c.setDue(&patch, *due)
+		case "tags":
+			list := splitTags(*tags)
+			patch.Tags = &list
+		case "repeat":
+			rule := *repeat
+			if strings.EqualFold(rule, "none") {
+				rule = ""
+			}
+			patch.Recurrence = &rule
+		case "list":
+			patch.ProjectID = list
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	todo, err := c.store.Patch(id, patch)
+	if err != nil {
+		return err
+	}
+	return c.print([]store.Todo{todo}, todo)
+}
+
+// setDue reads a due date with the quick-add grammar; "none" or an empty
+// value clears it.
+func (c *cli) setDue(patch *store.Patch, value string) error {
+	if value = strings.TrimSpace(value); value == "" || strings.EqualFold(value, "none") {
+		patch.ClearDue = true
+		return nil
+	}
+	draft := store.ParseQuickAdd(value, c.now)
+	if draft.DueAt == nil || draft.Title != "" || len(draft.Tags) > 0 {
+		return fmt.Errorf("unrecognised due date %q", value)
+	}
+	patch.DueAt = draft.DueAt
+	patch.DueZone = &c.zone
+	return nil
+}
+
+func (c *cli) remove(name string, args []string) error {
+	fs := c.flags(name, "<id>...")
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	for _, id := range fs.Args() {
+		if err := c.store.Delete(id); err != nil {
+			return fmt.Errorf("%s: %w", id, err)
+		}
+	}
+	if c.json {
+		return writeJSON(c.out, map[string][]string{"deleted": fs.Args()})
+	}
+	fmt.Fprintf(c.out, "Moved %s to the trash\n", countTodos(fs.NArg()))
+	return nil
+}
+
+func (c *cli) export(name string, args []string) error {
+	fs := c.flags(name, "")
+	query := queryFlags(fs)
+	format := fs.String("format", "json", "json, csv, todotxt, md or ics")
+	output := fs.String("o", "", "write to this file instead of standard output")
+	_ = fs.Parse(args)
+
+	parsed, ok := store.ParseFormat(*format)
+	if !ok {
+		return fmt.Errorf("unknown export format %q", *format)
+	}
+	todos, err := c.store.List(query())
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		return store.Export(c.out, parsed, todos)
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := store.Export(f, parsed, todos); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// stringList collects a repeatable flag.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func splitTags(value string) []string {
+	tags := []string{}
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// localZone names the time zone due dates are entered in: $TZ, else the
+// system zone, else UTC.
+func localZone() string {
+	candidates := []string{os.Getenv("TZ")}
+	if link, err := os.Readlink("/etc/localtime"); err == nil {
+		if _, name, ok := strings.Cut(link, "zoneinfo/"); ok {
+			candidates = append(candidates, name)
+		}
+	}
+	for _, name := range candidates {
+		if name == "" || name == "Local" {
+			continue
+		}
+		if _, err := time.LoadLocation(name); err == nil {
+			return name
+		}
+	}
+	return "UTC"
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "todo:", err)
+	os.Exit(1)
+}
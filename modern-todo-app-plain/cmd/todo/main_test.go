@@ -0,0 +1,197 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"modern_todo_plain/internal/store"
+)
+
+// patchRecorder is a todoStore that records the patches it is sent.
+type patchRecorder struct {
+	todoStore
+	patches []store.Patch
+}
+
+func (p *patchRecorder) Patch(id string, patch store.Patch) (store.Todo, error) {
+	p.patches = append(p.patches, patch)
+	return store.Todo{ID: id, Title: "Patched"}, nil
+}
+
+// testCLI returns a cli that writes nowhere, as of a fixed Wednesday noon in
+// UTC.
+func testCLI(s todoStore) *cli {
+	return &cli{store: s, out: io.Discard, now: time.Date(2024, time.March, 13, 12, 0, 0, 0, time.UTC), zone: "UTC"}
+}
+
+func strPtr(s string) *string { return &s }
+
+func priorityPtr(p store.Priority) *store.Priority { return &p }
+
+func TestQueryFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want store.Query
+	}{
+		{name: "defaults", want: store.Query{Filter: store.FilterAll, Sort: store.SortManual}},
+		{name: "case and tags", args: []string{"-filter", "Week", "-sort", "DUE", "-tag", "Work", "-tag", "home"}, want: store.Query{Filter: store.FilterThisWeek, Sort: store.SortDue, Tags: []string{"home", "work"}}},
+		{name: "search and list", args: []string{"-q", "invoice", "-list", "2", "-filter", "trash"}, want: store.Query{Filter: store.FilterTrash, Sort: store.SortManual, Search: "invoice", ProjectID: "2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("ls", flag.ContinueOnError)
+			query := queryFlags(fs)
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			if got := query(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("query = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestQueryFlagsUsage checks that unknown values exit with a usage error. The
+// check exits the process, so it runs in a child of the test binary.
+func TestQueryFlagsUsage(t *testing.T) {
+	if args := os.Getenv("TODO_QUERY_ARGS"); args != "" {
+		fs := flag.NewFlagSet("ls", flag.ContinueOnError)
+		query := queryFlags(fs)
+		if err := fs.Parse(strings.Fields(args)); err != nil {
+			t.Fatal(err)
+		}
+		query()
+		return
+	}
+
+	tests := []struct {
+		name string
+		args string
+		want string
+	}{
+		{name: "filter", args: "-filter soon", want: `unknown filter "soon"`},
+		{name: "sort", args: "-sort alphabetical", want: `unknown sort "alphabetical"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command(os.Args[0], "-test.run=^TestQueryFlagsUsage$")
+			cmd.Env = append(os.Environ(), "TODO_QUERY_ARGS="+tt.args)
+			out, err := cmd.CombinedOutput()
+			exit, ok := err.(*exec.ExitError)
+			if !ok || exit.ExitCode() != 2 {
+				t.Fatalf("err = %v, want exit status 2; output:\n%s", err, out)
+			}
+			if !strings.Contains(string(out), tt.want) {
+				t.Errorf("output does not contain %q:\n%s", tt.want, out)
+			}
+		})
+	}
+}
+
+func TestEditPatch(t *testing.T) {
+	due := time.Date(2024, time.March, 15, 17, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		args []string
+		want store.Patch
+	}{
+		{name: "title", args: []string{"7", "-title", "Pay rent"}, want: store.Patch{Title: strPtr("Pay rent")}},
+		{name: "id after the flags", args: []string{"-desc", "", "7"}, want: store.Patch{Description: strPtr("")}},
+		{name: "priority", args: []string{"7", "-priority", "HIGH"}, want: store.Patch{Priority: priorityPtr(store.PriorityHigh)}},
+		{name: "tags", args: []string{"7", "-tags", "work, home,,"}, want: store.Patch{Tags: &[]string{"work", "home"}}},
+		{name: "no tags", args: []string{"7", "-tags", ""}, want: store.Patch{Tags: &[]string{}}},
+		{name: "repeat", args: []string{"7", "-repeat", "FREQ=WEEKLY"}, want: store.Patch{Recurrence: strPtr("FREQ=WEEKLY")}},
+		{name: "stop repeating", args: []string{"7", "-repeat", "None"}, want: store.Patch{Recurrence: strPtr("")}},
+		{name: "list", args: []string{"7", "-list", "2"}, want: store.Patch{ProjectID: strPtr("2")}},
+		{name: "due", args: []string{"7", "-due", "friday 5pm"}, want: store.Patch{DueAt: &due, DueZone: strPtr("UTC")}},
+		{name: "clear due", args: []string{"7", "-due", "none"}, want: store.Patch{ClearDue: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &patchRecorder{}
+			if err := testCLI(recorder).edit("edit", tt.args); err != nil {
+				t.Fatal(err)
+			}
+			if len(recorder.patches) != 1 {
+				t.Fatalf("sent %d patches, want 1", len(recorder.patches))
+			}
+			if got := recorder.patches[0]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("patch = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetDue(t *testing.T) {
+	tests := []struct {
+		value     string
+		wantClear bool
+		wantErr   bool
+	}{
+		{value: "none", wantClear: true},
+		{value: " NONE ", wantClear: true},
+		{value: "", wantClear: true},
+		{value: "tomorrow"},
+		{value: "tomorrow and more", wantErr: true},
+		{value: "#work", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			var patch store.Patch
+			err := testCLI(nil).setDue(&patch, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error: %t", err, tt.wantErr)
+			}
+			if patch.ClearDue != tt.wantClear {
+				t.Errorf("ClearDue = %t, want %t", patch.ClearDue, tt.wantClear)
+			}
+			if set := patch.DueAt != nil; set != (!tt.wantClear && !tt.wantErr) {
+				t.Errorf("DueAt = %v, want it set: %t", patch.DueAt, !tt.wantClear && !tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOpenTodoStore(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "todos.json")
+	if _, err := store.Open(store.NewFileBackend(existing)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		server  string
+		wantErr string
+	}{
+		{name: "data file", data: existing},
+		{name: "missing data file", data: filepath.Join(dir, "typo.json"), wantErr: "no data file at"},
+		{name: "server", data: filepath.Join(dir, "typo.json"), server: "http://localhost:8080"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := openTodoStore(tt.data, tt.server)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				if _, statErr := os.Stat(tt.data); !os.IsNotExist(statErr) {
+					t.Errorf("a data file was created at %s", tt.data)
+				}
+				return
+			}
+			if err != nil || s == nil {
+				t.Fatalf("openTodoStore = %v, %v; want a store", s, err)
+			}
+		})
+	}
+}
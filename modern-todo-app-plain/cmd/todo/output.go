@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"modern_todo_plain/internal/store"
+)
+
+// maxTitleWidth truncates long titles in the table; -json prints them whole.
+const maxTitleWidth = 60
+
+// print writes todos as a table, or v as JSON with -json. Commands that
+// return one todo pass it as v so scripts get an object rather than a list.
+func (c *cli) print(todos []store.Todo, v any) error {
+	if c.json {
+		if list, ok := v.([]store.Todo); ok && list == nil {
+			v = []store.Todo{}
+		}
+		return writeJSON(c.out, v)
+	}
+	return writeTable(c.out, todos)
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func writeTable(w io.Writer, todos []store.Todo) error {
+	if len(todos) == 0 {
+		_, err := fmt.Fprintln(w, "No todos.")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tDONE\tPRIORITY\tDUE\tTITLE\tTAGS")
+	for _, todo := range todos {
+		done := "[ ]"
+		if todo.Completed {
+			done = "[x]"
+		}
+		due := "-"
+		if at, ok := todo.DueLocal(); ok {
+			due = at.Format("2006-01-02 15:04")
+		}
+		tags := "-"
+		if len(todo.Tags) > 0 {
+			tags = "#" + strings.Join(todo.Tags, " #")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", todo.ID, done, todo.Priority, due, truncate(todo.Title, maxTitleWidth), tags)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%s\n", countTodos(len(todos)))
+	return err
+}
+
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	return string([]rune(s)[:width-1]) + "…"
+}
+
+func countTodos(n int) string {
+	if n == 1 {
+		return "1 todo"
+	}
+	return fmt.Sprintf("%d todos", n)
+}
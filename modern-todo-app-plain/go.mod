@@ -1,6 +1,6 @@
 module modern_todo_plain
 
-go 1.21
+go 1.22
 
 require (
 	github.com/plainkit/html v0.9.0
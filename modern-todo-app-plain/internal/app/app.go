@@ -13,19 +13,55 @@ type App struct {
 	Mux http.Handler
 }
 
-func New() *App {
-	todoStore := store.New()
+func New(todoStore *store.Store) *App {
 	todos := handlers.NewTodoHandler(todoStore)
+	api := handlers.NewTodoAPI(todoStore)
 
+	// Everything that changes data is POST only, so links and prefetches can
+	// never modify todos; CSRF then checks that those POSTs come from the app.
 	mux := http.NewServeMux()
-	mux.HandleFunc("/assets/styles.css", cssHandler)
-	mux.HandleFunc("/", todos.Index)
-	mux.HandleFunc("/todos/create", todos.Create)
-	mux.HandleFunc("/todos/update", todos.Update)
-	mux.HandleFunc("/todos/toggle", todos.Toggle)
-	mux.HandleFunc("/todos/delete", todos.Delete)
+	mux.HandleFunc("GET /assets/styles.css", cssHandler)
+	mux.HandleFunc("GET /{$}", todos.Index)
+	mux.HandleFunc("POST /todos/create", todos.Create)
+	mux.HandleFunc("POST /todos/{id}/update", todos.Update)
+	mux.HandleFunc("POST /todos/{id}/toggle", todos.Toggle)
+	mux.HandleFunc("POST /todos/{id}/delete", todos.Delete)
+	mux.HandleFunc("POST /todos/{id}/reorder", todos.Reorder)
+	mux.HandleFunc("POST /todos/{id}/restore", todos.Restore)
+	mux.HandleFunc("POST /todos/{id}/purge", todos.Purge)
+	mux.HandleFunc("POST /todos/trash/empty", todos.EmptyTrash)
+	mux.HandleFunc("GET /todos/export", todos.Export)
+	mux.HandleFunc("POST /todos/import", todos.Import)
+	mux.HandleFunc("POST /todos/undo", todos.Undo)
+	mux.HandleFunc("POST /todos/redo", todos.Redo)
+	mux.HandleFunc("GET /todos/{id}/card", todos.Card)
+	mux.HandleFunc("GET /todos/more", todos.More)
+	mux.HandleFunc("POST /todos/batch", todos.Batch)
+	mux.HandleFunc("POST /todos/clear-completed", todos.ClearCompleted)
+	mux.HandleFunc("POST /todos/quick", todos.QuickAdd)
+	mux.HandleFunc("GET /todos/quick/preview", todos.QuickAddPreview)
+	mux.HandleFunc("GET /todos/{id}/activity", todos.TodoActivity)
+	mux.HandleFunc("GET /todos/reminders", todos.Reminders)
+	mux.HandleFunc("POST /todos/{id}/reminder/dismiss", todos.DismissReminder)
+	mux.HandleFunc("POST /todos/{id}/subtasks", todos.AddSubtask)
+	mux.HandleFunc("POST /todos/{id}/subtasks/{subtask}/toggle", todos.ToggleSubtask)
+	mux.HandleFunc("POST /todos/{id}/subtasks/{subtask}/delete", todos.DeleteSubtask)
+	mux.HandleFunc("POST /tags/rename", todos.RenameTag)
+	mux.HandleFunc("POST /tags/{name}/delete", todos.DeleteTag)
+	mux.HandleFunc("POST /lists/create", todos.CreateProject)
+	mux.HandleFunc("POST /lists/{id}/update", todos.UpdateProject)
+	mux.HandleFunc("POST /lists/{id}/delete", todos.DeleteProject)
+	mux.HandleFunc("GET /lists/{id}", todos.List)
+	mux.HandleFunc("POST /calendar/rotate", todos.RotateFeed)
+	mux.HandleFunc("GET /calendar/{feed}", todos.CalendarFeed)
+	mux.HandleFunc("GET /events", todos.Events)
+	mux.HandleFunc("GET /activity", todos.RecentActivity)
+	mux.HandleFunc("GET /stats", todos.Stats)
+	// The API answers unsupported methods itself, with its JSON envelope.
+	mux.HandleFunc("/api/v1/todos", api.Collection)
+	mux.HandleFunc("/api/v1/todos/{id}", api.Item)
 
-	return &App{Mux: mux}
+	return &App{Mux: handlers.CSRF(mux)}
 }
 
 func cssHandler(w http.ResponseWriter, _ *http.Request) {
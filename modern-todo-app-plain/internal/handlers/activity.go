@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"modern_todo_plain/internal/views"
+)
+
+// recentActivityLimit is how many events the Recent activity page shows.
+const recentActivityLimit = 200
+
+// TodoActivity renders the activity drawer of one todo.
+func (h *TodoHandler) TodoActivity(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	todo, err := h.store.Get(id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	events, err := h.store.Activity(id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeHTML(w, views.RenderActivityDrawer(todo, events, h.pageData(parseQuery(r))))
+}
+
+// RecentActivity renders the activity of every todo, newest first.
+func (h *TodoHandler) RecentActivity(w http.ResponseWriter, r *http.Request) {
+	events := h.store.RecentActivity(recentActivityLimit)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = fmt.Fprint(w, "<!DOCTYPE html>\n")
+	_, _ = fmt.Fprint(w, views.RenderActivityPage(events, h.pageData(parseQuery(r))))
+}
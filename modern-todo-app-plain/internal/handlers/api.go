@@ -0,0 +1,370 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"modern_todo_plain/internal/store"
+)
+
+const (
+	apiTodosPath = "/api/v1/todos"
+	maxAPIBody   = 1 << 20
+)
+
+// TodoAPI serves the versioned JSON resource at /api/v1/todos.
+type TodoAPI struct {
+	store *store.Store
+}
+
+func NewTodoAPI(store *store.Store) *TodoAPI {
+	return &TodoAPI{store: store}
+}
+
+type apiTodoInput struct {
+	Title       *string `json:"title"`
+	Description *string `json:"description"`
+	Priority    *string `json:"priority"`
+	Completed   *bool   `json:"completed"`
+	// DueAt is an RFC 3339 timestamp; an explicit null clears the due date.
+	DueAt   json.RawMessage `json:"due_at"`
+	DueZone *string         `json:"due_zone"`
+	// RemindMinutes asks for a reminder that many minutes before the due
+	// date; an explicit null removes the reminder.
+	RemindMinutes json.RawMessage `json:"remind_minutes"`
+	Tags          *[]string       `json:"tags"`
+	// Recurrence is an RRULE such as "FREQ=WEEKLY;BYDAY=MO"; "" stops repeating.
+	Recurrence *string `json:"recurrence"`
+	// ProjectID moves the todo to another list; "" means the inbox.
+	ProjectID *string `json:"project_id"`
+}
+
+type apiList struct {
+	Data  []store.Todo `json:"data"`
+	Stats apiStats     `json:"stats"`
+	// NextCursor fetches the following page; it is only set when a limit was
+	// given and more todos match.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+type apiStats struct {
+	Total     int `json:"total"`
+	Active    int `json:"active"`
+	Completed int `json:"completed"`
+	Overdue   int `json:"overdue"`
+	ThisWeek  int `json:"this_week"`
+	Trashed   int `json:"trashed"`
+}
+
+type apiError struct {
+	Error apiErrorBody `json:"error"`
+}
+
+type apiErrorBody struct {
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+// Collection handles /api/v1/todos.
+func (h *TodoAPI) Collection(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.list(w, r)
+	case http.MethodPost:
+		h.create(w, r)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+// Item handles /api/v1/todos/{id}.
+func (h *TodoAPI) Item(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	switch r.Method {
+	case http.MethodGet:
+		todo, err := h.store.Get(id)
+		if err != nil {
+			writeAPIStoreError(w, err)
+			return
+		}
+		w.Header().Set("ETag", etag(todo))
+		writeJSON(w, http.StatusOK, todo)
+	case http.MethodPatch:
+		h.patch(w, r, id)
+	case http.MethodDelete:
+		if _, err := h.store.Delete(id); err != nil {
+			writeAPIStoreError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeMethodNotAllowed(w, http.MethodGet, http.MethodPatch, http.MethodDelete)
+	}
+}
+
+// list returns the todos of a view. With ?limit= it returns them a page at a
+// time; ?cursor= continues from a previous page's next_cursor and replaces the
+// other view parameters.
+func (h *TodoAPI) list(w http.ResponseWriter, r *http.Request) {
+	cursor := store.Cursor{Query: parseQuery(r)}
+	if raw := r.Form.Get("cursor"); raw != "" {
+		var err error
+		if cursor, err = store.ParseCursor(raw); err != nil {
+			writeAPIError(w, http.StatusBadRequest, "invalid_cursor", "cursor must be a next_cursor returned by this endpoint", nil)
+			return
+		}
+	}
+	limit := 0
+	if raw := r.Form.Get("limit"); raw != "" {
+		var err error
+		if limit, err = strconv.Atoi(raw); err != nil || limit < 1 {
+			writeAPIError(w, http.StatusBadRequest, "invalid_limit", "limit must be a positive number", nil)
+			return
+		}
+	}
+
+	page := h.store.ListPage(cursor, limit)
+	stats := h.store.ProjectStats(cursor.Query.ProjectID)
+	list := apiList{
+		Data: page.Todos,
+		Stats: apiStats{
+			Total:     stats.Total,
+			Active:    stats.Active,
+			Completed: stats.Completed,
+			Overdue:   stats.Overdue,
+			ThisWeek:  stats.ThisWeek,
+			Trashed:   stats.Trashed,
+		},
+	}
+	if page.Next != nil {
+		list.NextCursor = page.Next.String()
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (h *TodoAPI) create(w http.ResponseWriter, r *http.Request) {
+	var in apiTodoInput
+	if !decodeJSON(w, r, &in) {
+		return
+	}
+	if in.Title == nil {
+		writeAPIError(w, http.StatusUnprocessableEntity, "validation_failed", "invalid todo", map[string]string{"title": "title is required"})
+		return
+	}
+
+	patch, fields := in.patch()
+	if len(fields) > 0 {
+		writeAPIError(w, http.StatusUnprocessableEntity, "validation_failed", "invalid todo", fields)
+		return
+	}
+
+	draft := store.Draft{Title: *patch.Title, Priority: store.PriorityMedium, DueAt: patch.DueAt, RemindMinutes: patch.RemindMinutes}
+	if patch.Description != nil {
+		draft.Description = *patch.Description
+	}
+	if patch.Priority != nil {
+		draft.Priority = *patch.Priority
+	}
+	if patch.DueZone != nil {
+		draft.DueZone = *patch.DueZone
+	}
+	if patch.Tags != nil {
+		draft.Tags = *patch.Tags
+	}
+	if patch.Recurrence != nil {
+		draft.Recurrence = *patch.Recurrence
+	}
+	if patch.ProjectID != nil {
+		draft.ProjectID = *patch.ProjectID
+	}
+
+	if patch.Completed != nil {
+		draft.Completed = *patch.Completed
+	}
+
+	todo, _, err := h.store.Add(draft)
+	if err != nil {
+		writeAPIStoreError(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", etag(todo))
+	w.Header().Set("Location", fmt.Sprintf("%s/%s", apiTodosPath, todo.ID))
+	writeJSON(w, http.StatusCreated, todo)
+}
+
+func (h *TodoAPI) patch(w http.ResponseWriter, r *http.Request, id string) {
+	var in apiTodoInput
+	if !decodeJSON(w, r, &in) {
+		return
+	}
+
+	patch, fields := in.patch()
+	if len(fields) > 0 {
+		writeAPIError(w, http.StatusUnprocessableEntity, "validation_failed", "invalid todo", fields)
+		return
+	}
+	version, ok := ifMatchVersion(r.Header.Get("If-Match"))
+	if !ok {
+		writeAPIError(w, http.StatusBadRequest, "invalid_if_match", `If-Match must be an ETag such as "3"`, nil)
+		return
+	}
+	patch.Version = version
+
+	todo, _, err := h.store.Patch(id, patch)
+	if err != nil {
+		writeAPIStoreError(w, err)
+		return
+	}
+	w.Header().Set("ETag", etag(todo))
+	writeJSON(w, http.StatusOK, todo)
+}
+
+// etag identifies a todo's version for conditional requests.
+func etag(todo store.Todo) string {
+	return strconv.Quote(strconv.FormatUint(todo.Version, 10))
+}
+
+// ifMatchVersion reads the version named by an If-Match header. An absent
+// header or "*" matches any version and yields 0.
+func ifMatchVersion(header string) (uint64, bool) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return 0, true
+	}
+	raw, err := strconv.Unquote(strings.TrimPrefix(header, "W/"))
+	if err != nil {
+		return 0, false
+	}
+	version, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil || version == 0 {
+		return 0, false
+	}
+	return version, true
+}
+
+// patch validates the input and converts it into a store patch. Field errors
+// are keyed by JSON field name.
+func (in apiTodoInput) patch() (store.Patch, map[string]string) {
+	fields := map[string]string{}
+	patch := store.Patch{
+		Description: in.Description,
+		Completed:   in.Completed,
+		DueZone:     in.DueZone,
+		Tags:        in.Tags,
+		Recurrence:  in.Recurrence,
+		ProjectID:   in.ProjectID,
+	}
+
+	patch.Title = in.Title
+	if in.Priority != nil {
+		priority := store.Priority(strings.ToLower(*in.Priority))
+		patch.Priority = &priority
+	}
+	if in.DueZone != nil && *in.DueZone != "" {
+		if _, err := time.LoadLocation(*in.DueZone); err != nil {
+			fields["due_zone"] = "due_zone must be an IANA time zone name"
+		}
+	}
+	if len(in.DueAt) > 0 {
+		if string(in.DueAt) == "null" {
+			patch.ClearDue = true
+		} else {
+			var due time.Time
+			if err := json.Unmarshal(in.DueAt, &due); err != nil {
+				fields["due_at"] = "due_at must be an RFC 3339 timestamp"
+			} else {
+				patch.DueAt = &due
+			}
+		}
+	}
+
+	if len(in.RemindMinutes) > 0 {
+		if string(in.RemindMinutes) == "null" {
+			patch.ClearRemind = true
+		} else {
+			var minutes int
+			if err := json.Unmarshal(in.RemindMinutes, &minutes); err != nil {
+				fields["remind_minutes"] = "remind_minutes must be a whole number of minutes"
+			} else {
+				patch.RemindMinutes = &minutes
+			}
+		}
+	}
+
+	var invalid *store.ValidationError
+	if errors.As(patch.Validate(), &invalid) {
+		for name, message := range apiFields(invalid.Fields) {
+			fields[name] = message
+		}
+	}
+	return patch, fields
+}
+
+// apiFields renames store field errors after the JSON fields that set them.
+func apiFields(fields map[string]string) map[string]string {
+	out := make(map[string]string, len(fields))
+	for name, message := range fields {
+		if name == "remind" {
+			name = "remind_minutes"
+		}
+		out[name] = message
+	}
+	return out
+}
+
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAPIBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_json", fmt.Sprintf("invalid JSON body: %v", err), nil)
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("encode response: %v", err)
+	}
+}
+
+func writeAPIError(w http.ResponseWriter, status int, code, message string, fields map[string]string) {
+	writeJSON(w, status, apiError{Error: apiErrorBody{Code: code, Message: message, Fields: fields}})
+}
+
+func writeAPIStoreError(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrNotFound) {
+		writeAPIError(w, http.StatusNotFound, "not_found", "todo not found", nil)
+		return
+	}
+	if errors.Is(err, store.ErrVersionConflict) {
+		writeAPIError(w, http.StatusConflict, "conflict", "todo was changed since the If-Match version; fetch it again and retry", nil)
+		return
+	}
+	var invalid *store.ValidationError
+	if errors.As(err, &invalid) {
+		writeAPIError(w, http.StatusUnprocessableEntity, "validation_failed", "invalid todo", apiFields(invalid.Fields))
+		return
+	}
+	if errors.Is(err, store.ErrUnknownProject) {
+		writeAPIError(w, http.StatusUnprocessableEntity, "validation_failed", "invalid todo", map[string]string{"project_id": err.Error()})
+		return
+	}
+	log.Printf("todo store: %v", err)
+	writeAPIError(w, http.StatusInternalServerError, "internal", "could not save changes", nil)
+}
+
+func writeMethodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeAPIError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed", nil)
+}
@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"modern_todo_plain/internal/store"
+)
+
+// apiRequest sends a JSON request to the API routes of h.
+func apiRequest(t *testing.T, h *TodoAPI, method, path, body string, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc(apiTodosPath, h.Collection)
+	mux.HandleFunc(apiTodosPath+"/{id}", h.Item)
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+// decodeAPI decodes a JSON response body into v.
+func decodeAPI(t *testing.T, rec *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	if err := json.NewDecoder(rec.Body).Decode(v); err != nil {
+		t.Fatalf("decode response %q: %v", rec.Body, err)
+	}
+}
+
+// apiCreate adds a todo through the API and returns it.
+func apiCreate(t *testing.T, h *TodoAPI, body string) store.Todo {
+	t.Helper()
+	rec := apiRequest(t, h, http.MethodPost, apiTodosPath, body, nil)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d, want 201: %s", rec.Code, rec.Body)
+	}
+	var todo store.Todo
+	decodeAPI(t, rec, &todo)
+	return todo
+}
+
+func TestAPICreate(t *testing.T) {
+	h := NewTodoAPI(store.New())
+	rec := apiRequest(t, h, http.MethodPost, apiTodosPath, `{"title":"Renew passport","priority":"HIGH","tags":["Travel"]}`, nil)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: %s", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "application/json") {
+		t.Errorf("Content-Type = %q, want JSON", got)
+	}
+
+	var todo store.Todo
+	decodeAPI(t, rec, &todo)
+	if todo.Title != "Renew passport" || todo.Priority != store.PriorityHigh || strings.Join(todo.Tags, ",") != "travel" {
+		t.Errorf("created %q, priority %q, tags %v; want the request's fields normalized", todo.Title, todo.Priority, todo.Tags)
+	}
+	if got, want := rec.Header().Get("Location"), apiTodosPath+"/"+todo.ID; got != want {
+		t.Errorf("Location = %q, want %q", got, want)
+	}
+	if got, want := rec.Header().Get("ETag"), `"1"`; got != want {
+		t.Errorf("ETag = %q, want %q", got, want)
+	}
+
+	rec = apiRequest(t, h, http.MethodGet, apiTodosPath+"/"+todo.ID, "", nil)
+	var fetched store.Todo
+	decodeAPI(t, rec, &fetched)
+	if rec.Code != http.StatusOK || fetched.Title != todo.Title {
+		t.Errorf("GET after create: status %d, title %q; want 200 and %q", rec.Code, fetched.Title, todo.Title)
+	}
+}
+
+func TestAPIList(t *testing.T) {
+	h := NewTodoAPI(store.New())
+	for _, title := range []string{"Feed the zebra", "Buy milk", "Brush the zebra"} {
+		apiCreate(t, h, `{"title":"`+title+`"}`)
+	}
+
+	rec := apiRequest(t, h, http.MethodGet, apiTodosPath+"?q=zebra&limit=1", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var page apiList
+	decodeAPI(t, rec, &page)
+	if len(page.Data) != 1 || page.NextCursor == "" {
+		t.Fatalf("first page has %d todos and cursor %q, want one todo and a cursor", len(page.Data), page.NextCursor)
+	}
+
+	rec = apiRequest(t, h, http.MethodGet, apiTodosPath+"?limit=1&cursor="+page.NextCursor, "", nil)
+	var next apiList
+	decodeAPI(t, rec, &next)
+	if len(next.Data) != 1 || next.Data[0].ID == page.Data[0].ID || next.NextCursor != "" {
+		t.Errorf("second page = %d todos, cursor %q; want the other match and no cursor", len(next.Data), next.NextCursor)
+	}
+}
+
+func TestAPIErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		wantCode int
+		wantErr  string
+	}{
+		{name: "unknown todo", method: http.MethodGet, path: apiTodosPath + "/nope", wantCode: http.StatusNotFound, wantErr: "not_found"},
+		{name: "delete unknown todo", method: http.MethodDelete, path: apiTodosPath + "/nope", wantCode: http.StatusNotFound, wantErr: "not_found"},
+		{name: "malformed body", method: http.MethodPost, path: apiTodosPath, body: `{"title":`, wantCode: http.StatusBadRequest, wantErr: "invalid_json"},
+		{name: "unknown field", method: http.MethodPost, path: apiTodosPath, body: `{"title":"Milk","colour":"red"}`, wantCode: http.StatusBadRequest, wantErr: "invalid_json"},
+		{name: "bad limit", method: http.MethodGet, path: apiTodosPath + "?limit=0", wantCode: http.StatusBadRequest, wantErr: "invalid_limit"},
+		{name: "bad cursor", method: http.MethodGet, path: apiTodosPath + "?cursor=%21", wantCode: http.StatusBadRequest, wantErr: "invalid_cursor"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := apiRequest(t, NewTodoAPI(store.New()), tt.method, tt.path, tt.body, nil)
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantCode, rec.Body)
+			}
+			var body apiError
+			decodeAPI(t, rec, &body)
+			if body.Error.Code != tt.wantErr || body.Error.Message == "" {
+				t.Errorf("error = %+v, want code %q with a message", body.Error, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAPIDelete(t *testing.T) {
+	h := NewTodoAPI(store.New())
+	todo := apiCreate(t, h, `{"title":"Cancel the gym"}`)
+
+	rec := apiRequest(t, h, http.MethodDelete, apiTodosPath+"/"+todo.ID, "", nil)
+	if rec.Code != http.StatusNoContent || rec.Body.Len() != 0 {
+		t.Fatalf("status = %d with body %q, want 204 and no body", rec.Code, rec.Body)
+	}
+
+	// The todo is in the trash: still readable, but gone from the list. A
+	// second delete changes nothing and succeeds.
+	rec = apiRequest(t, h, http.MethodGet, apiTodosPath+"/"+todo.ID, "", nil)
+	var trashed store.Todo
+	decodeAPI(t, rec, &trashed)
+	if rec.Code != http.StatusOK || !trashed.Trashed() {
+		t.Errorf("GET after delete: status %d, trashed %t; want 200 and in the trash", rec.Code, trashed.Trashed())
+	}
+	rec = apiRequest(t, h, http.MethodGet, apiTodosPath+"?q=gym", "", nil)
+	var list apiList
+	decodeAPI(t, rec, &list)
+	if len(list.Data) != 0 {
+		t.Errorf("list after delete has %d todos, want none", len(list.Data))
+	}
+	if rec := apiRequest(t, h, http.MethodDelete, apiTodosPath+"/"+todo.ID, "", nil); rec.Code != http.StatusNoContent {
+		t.Errorf("second DELETE: status = %d, want 204", rec.Code)
+	}
+}
+
+func TestAPIIfMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		ifMatch  string
+		wantCode int
+		wantErr  string
+	}{
+		{name: "current version", ifMatch: `"2"`, wantCode: http.StatusOK},
+		{name: "weak current version", ifMatch: `W/"2"`, wantCode: http.StatusOK},
+		{name: "any version", ifMatch: "*", wantCode: http.StatusOK},
+		{name: "no header", wantCode: http.StatusOK},
+		{name: "stale version", ifMatch: `"1"`, wantCode: http.StatusConflict, wantErr: "conflict"},
+		{name: "malformed", ifMatch: "2", wantCode: http.StatusBadRequest, wantErr: "invalid_if_match"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewTodoAPI(store.New())
+			todo := apiCreate(t, h, `{"title":"Draft the agenda"}`)
+
+			// Someone else edits the todo first, so version 1 is stale.
+			rec := apiRequest(t, h, http.MethodPatch, apiTodosPath+"/"+todo.ID, `{"priority":"high"}`, http.Header{"If-Match": {`"1"`}})
+			if got := rec.Header().Get("ETag"); rec.Code != http.StatusOK || got != `"2"` {
+				t.Fatalf("first edit: status %d, ETag %q; want 200 and \"2\": %s", rec.Code, got, rec.Body)
+			}
+
+			var header http.Header
+			if tt.ifMatch != "" {
+				header = http.Header{"If-Match": {tt.ifMatch}}
+			}
+			rec = apiRequest(t, h, http.MethodPatch, apiTodosPath+"/"+todo.ID, `{"title":"Send the agenda"}`, header)
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantCode, rec.Body)
+			}
+
+			want := "Draft the agenda"
+			if tt.wantErr == "" {
+				var patched store.Todo
+				decodeAPI(t, rec, &patched)
+				if got := rec.Header().Get("ETag"); got != `"3"` || patched.Version != 3 {
+					t.Errorf("ETag = %q, version %d; want \"3\"", got, patched.Version)
+				}
+				want = "Send the agenda"
+			} else {
+				var body apiError
+				decodeAPI(t, rec, &body)
+				if body.Error.Code != tt.wantErr {
+					t.Errorf("error code = %q, want %q", body.Error.Code, tt.wantErr)
+				}
+			}
+
+			rec = apiRequest(t, h, http.MethodGet, apiTodosPath+"/"+todo.ID, "", nil)
+			var current store.Todo
+			decodeAPI(t, rec, &current)
+			if current.Title != want {
+				t.Errorf("title = %q, want %q", current.Title, want)
+			}
+			if got := rec.Header().Get("ETag"); got != etag(current) {
+				t.Errorf("GET ETag = %q, want %q", got, etag(current))
+			}
+		})
+	}
+}
+
+func TestAPIValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		// wantFields lists the field errors expected, by JSON field name.
+		wantFields []string
+	}{
+		{name: "missing title", method: http.MethodPost, body: `{"priority":"low"}`, wantFields: []string{"title"}},
+		{name: "blank title", method: http.MethodPost, body: `{"title":"  "}`, wantFields: []string{"title"}},
+		{name: "long title", method: http.MethodPost, body: `{"title":"` + strings.Repeat("x", store.MaxTitleLength+1) + `"}`, wantFields: []string{"title"}},
+		{name: "every bad field", method: http.MethodPost, body: `{"title":"Plan","priority":"urgent","due_at":"friday","due_zone":"Mars/Olympus","remind_minutes":"soon"}`, wantFields: []string{"due_at", "due_zone", "priority", "remind_minutes"}},
+		{name: "reminder without due date", method: http.MethodPost, body: `{"title":"Plan","remind_minutes":30}`, wantFields: []string{"remind_minutes"}},
+		{name: "unknown list", method: http.MethodPost, body: `{"title":"Plan","project_id":"nope"}`, wantFields: []string{"project_id"}},
+		{name: "patch with a long description", method: http.MethodPatch, body: `{"description":"` + strings.Repeat("x", store.MaxDescriptionLength+1) + `"}`, wantFields: []string{"description"}},
+		{name: "patch with a bad repeat rule", method: http.MethodPatch, body: `{"recurrence":"FREQ=HOURLY"}`, wantFields: []string{"recurrence"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewTodoAPI(store.New())
+			path := apiTodosPath
+			if tt.method == http.MethodPatch {
+				path += "/" + apiCreate(t, h, `{"title":"Plan the trip"}`).ID
+			}
+
+			rec := apiRequest(t, h, tt.method, path, tt.body, nil)
+			if rec.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("status = %d, want 422: %s", rec.Code, rec.Body)
+			}
+			var body apiError
+			decodeAPI(t, rec, &body)
+			if body.Error.Code != "validation_failed" {
+				t.Errorf("error code = %q, want validation_failed", body.Error.Code)
+			}
+			var got []string
+			for name := range body.Error.Fields {
+				got = append(got, name)
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(tt.wantFields, ",") {
+				t.Errorf("field errors = %v, want %v", body.Error.Fields, tt.wantFields)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"modern_todo_plain/internal/store"
+)
+
+// Batch applies one action to every selected todo. The ids arrive as repeated
+// id fields from the checkboxes on the cards.
+func (h *TodoHandler) Batch(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	ids := r.PostForm["id"]
+	if len(ids) == 0 {
+		http.Error(w, "no todos selected", http.StatusBadRequest)
+		return
+	}
+
+	query := parseQuery(r)
+	batch := store.Batch{
+		Action:    store.BatchAction(strings.ToLower(r.FormValue("action"))),
+		Priority:  store.Priority(strings.ToLower(r.FormValue("priority"))),
+		ProjectID: r.FormValue("project"),
+		Tag:       r.FormValue("batch_tag"),
+	}
+
+	changed, commit, err := h.store.Apply(ids, batch)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	h.respondWithChanges(w, r, query, changed, commit)
+}
+
+// ClearCompleted moves the completed todos of the list being viewed, or of
+// every list, to the trash.
+func (h *TodoHandler) ClearCompleted(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	query := parseQuery(r)
+
+	changed, commit, err := h.store.ClearCompleted(query.ProjectID)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	h.respondWithChanges(w, r, query, changed, commit)
+}
+
+// respondWithChanges offers to undo a batch only when it changed something;
+// otherwise the latest history entry belongs to an earlier change.
+func (h *TodoHandler) respondWithChanges(w http.ResponseWriter, r *http.Request, query store.Query, changed int, commit store.Commit) {
+	if changed == 0 {
+		h.respondWithApp(w, r, query)
+		return
+	}
+	h.respondWithUndo(w, r, query, commit)
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"modern_todo_plain/internal/store"
+)
+
+// CalendarFeed serves every open and completed todo as an iCalendar feed at
+// /calendar/{token}.ics. Unknown tokens get a 404 so the URL itself is the
+// secret.
+func (h *TodoHandler) CalendarFeed(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimSuffix(r.PathValue("feed"), ".ics")
+	if !h.store.ValidFeedToken(token) {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", store.FormatICS.ContentType())
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	if err := store.Export(w, store.FormatICS, h.store.List(store.Query{Filter: store.FilterAll})); err != nil {
+		log.Printf("calendar feed: %v", err)
+	}
+}
+
+// RotateFeed replaces the calendar feed token, breaking existing
+// subscriptions.
+func (h *TodoHandler) RotateFeed(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	query := parseQuery(r)
+
+	if _, err := h.store.RotateFeedToken(); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	h.respondWithApp(w, r, query)
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+
+	"modern_todo_plain/internal/store"
+	"modern_todo_plain/internal/views"
+)
+
+// respondWithConflict answers an edit based on an outdated version with 409
+// and a dialog comparing the submitted changes with the current todo. htmx
+// requests get the dialog retargeted into the page; other clients get a
+// plain error.
+func (h *TodoHandler) respondWithConflict(w http.ResponseWriter, r *http.Request, query store.Query, id string, draft store.Draft) {
+	current, err := h.store.Get(id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	if !isHX(r) {
+		writeStoreError(w, store.ErrVersionConflict)
+		return
+	}
+
+	w.Header().Set("HX-Retarget", "#conflict-slot")
+	w.Header().Set("HX-Reswap", "innerHTML")
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusConflict)
+	_, _ = w.Write([]byte(views.RenderConflictDialog(current, draft, h.pageData(query))))
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"modern_todo_plain/internal/store"
+)
+
+var (
+	inputPattern  = regexp.MustCompile(`<input[^>]*>`)
+	reloadPattern = regexp.MustCompile(`<button[^>]*hx-get="[^"]*"[^>]*>`)
+	attrPattern   = regexp.MustCompile(`\b(name|value|hx-get)="([^"]*)"`)
+)
+
+// attrs returns the name, value and hx-get attributes of an HTML tag.
+func attrs(tag string) map[string]string {
+	out := map[string]string{}
+	for _, match := range attrPattern.FindAllStringSubmatch(tag, -1) {
+		out[match[1]] = html.UnescapeString(match[2])
+	}
+	return out
+}
+
+// conflictServer serves the routes the conflict dialog uses.
+func conflictServer(s *store.Store) http.Handler {
+	h := NewTodoHandler(s)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", h.Index)
+	mux.HandleFunc("POST /todos/{id}/update", h.Update)
+	return mux
+}
+
+func TestConflictDialog(t *testing.T) {
+	tests := []struct {
+		name string
+		// keep submits the dialog's form; otherwise "Discard mine" reloads
+		// the app.
+		keep      bool
+		wantTitle string
+	}{
+		{name: "keep mine", keep: true, wantTitle: "Book the venue today"},
+		{name: "discard mine", keep: false, wantTitle: "Book the venue for May"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := store.New()
+			todo, _, err := s.Add(store.Draft{Title: "Book the venue", Priority: store.PriorityMedium})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Someone else saves first.
+			theirs, _, err := s.Update(todo.ID, store.Draft{Title: "Book the venue for May", Priority: store.PriorityMedium})
+			if err != nil {
+				t.Fatal(err)
+			}
+			server := conflictServer(s)
+
+			stale := url.Values{"version": {"1"}, "title": {"Book the venue today"}, "priority": {"high"}}
+			req := httptest.NewRequest(http.MethodPost, "/todos/"+todo.ID+"/update", strings.NewReader(stale.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.Header.Set("HX-Request", "true")
+			rec := httptest.NewRecorder()
+			server.ServeHTTP(rec, req)
+			if rec.Code != http.StatusConflict {
+				t.Fatalf("stale edit: status = %d, want 409: %s", rec.Code, rec.Body)
+			}
+			if got := rec.Header().Get("HX-Retarget"); got != "#conflict-slot" {
+				t.Errorf("HX-Retarget = %q, want #conflict-slot", got)
+			}
+			dialog := rec.Body.String()
+			for _, title := range []string{"Book the venue today", theirs.Title} {
+				if !strings.Contains(dialog, title) {
+					t.Errorf("dialog does not show %q", title)
+				}
+			}
+			if got := savedTitle(t, s, todo.ID); got != theirs.Title {
+				t.Fatalf("stale edit saved %q", got)
+			}
+
+			if tt.keep {
+				form := url.Values{}
+				for _, input := range inputPattern.FindAllString(dialog, -1) {
+					a := attrs(input)
+					form.Set(a["name"], a["value"])
+				}
+				if got := form.Get("version"); got != "2" {
+					t.Errorf("dialog resubmits version %q, want the current version 2", got)
+				}
+				req = httptest.NewRequest(http.MethodPost, "/todos/"+todo.ID+"/update", strings.NewReader(form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				req.Header.Set("HX-Target", "todo-results")
+			} else {
+				discard := reloadPattern.FindString(dialog)
+				if discard == "" {
+					t.Fatal("dialog has no button reloading the app")
+				}
+				req = httptest.NewRequest(http.MethodGet, attrs(discard)["hx-get"], nil)
+				req.Header.Set("HX-Target", "todo-app")
+			}
+			req.Header.Set("HX-Request", "true")
+			rec = httptest.NewRecorder()
+			server.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+			}
+
+			if got := savedTitle(t, s, todo.ID); got != tt.wantTitle {
+				t.Errorf("title = %q, want %q", got, tt.wantTitle)
+			}
+			if !strings.Contains(rec.Body.String(), html.EscapeString(tt.wantTitle)) {
+				t.Errorf("response does not show %q", tt.wantTitle)
+			}
+		})
+	}
+}
+
+// savedTitle returns the saved title of a todo.
+func savedTitle(t *testing.T, s *store.Store, id string) string {
+	t.Helper()
+	todo, err := s.Get(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return todo.Title
+}
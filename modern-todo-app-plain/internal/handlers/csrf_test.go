@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"modern_todo_plain/internal/store"
+	"modern_todo_plain/internal/views"
+)
+
+// csrfServer wraps a handler that records whether it ran and the token it saw.
+func csrfServer(reached *bool, seen *string) http.Handler {
+	return CSRF(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*reached = true
+		*seen = csrfToken(r)
+	}))
+}
+
+func TestCSRF(t *testing.T) {
+	valid := newCSRFToken()
+	cookie := &http.Cookie{Name: csrfCookieName, Value: valid}
+
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		cookie      *http.Cookie
+		header      string
+		wantStatus  int
+	}{
+		{name: "form post with matching token", method: http.MethodPost, contentType: "application/x-www-form-urlencoded", cookie: cookie, header: valid, wantStatus: http.StatusOK},
+		{name: "form post without cookie or header", method: http.MethodPost, contentType: "application/x-www-form-urlencoded", wantStatus: http.StatusForbidden},
+		{name: "form post without header", method: http.MethodPost, contentType: "application/x-www-form-urlencoded", cookie: cookie, wantStatus: http.StatusForbidden},
+		{name: "form post without cookie", method: http.MethodPost, contentType: "application/x-www-form-urlencoded", header: valid, wantStatus: http.StatusForbidden},
+		{name: "form post with another token", method: http.MethodPost, contentType: "application/x-www-form-urlencoded", cookie: cookie, header: newCSRFToken(), wantStatus: http.StatusForbidden},
+		{name: "multipart post without token", method: http.MethodPost, contentType: "multipart/form-data; boundary=x", cookie: cookie, wantStatus: http.StatusForbidden},
+		{name: "post without a body type", method: http.MethodPost, cookie: cookie, wantStatus: http.StatusForbidden},
+		{name: "JSON post without token", method: http.MethodPost, contentType: "application/json", wantStatus: http.StatusOK},
+		{name: "get without cookie", method: http.MethodGet, wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/todos/create", strings.NewReader("title=Milk"))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			if tt.cookie != nil {
+				req.AddCookie(tt.cookie)
+			}
+			if tt.header != "" {
+				req.Header.Set(views.CSRFHeader, tt.header)
+			}
+
+			var reached bool
+			var seen string
+			rec := httptest.NewRecorder()
+			csrfServer(&reached, &seen).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if reached != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("handler reached = %t with status %d", reached, rec.Code)
+			}
+		})
+	}
+}
+
+func TestCSRFSetsCookie(t *testing.T) {
+	var reached bool
+	var seen string
+	rec := httptest.NewRecorder()
+	csrfServer(&reached, &seen).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusOK || !reached {
+		t.Fatalf("GET: status = %d, reached = %t; want it to pass", rec.Code, reached)
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != csrfCookieName {
+		t.Fatalf("cookies = %v, want one %s cookie", cookies, csrfCookieName)
+	}
+	if !cookies[0].HttpOnly || cookies[0].SameSite != http.SameSiteLaxMode {
+		t.Errorf("cookie = %+v, want HttpOnly and SameSite=Lax", cookies[0])
+	}
+	if seen != cookies[0].Value {
+		t.Errorf("page token = %q, want the cookie value %q", seen, cookies[0].Value)
+	}
+
+	// The next request reuses the cookie instead of issuing a new one, and a
+	// form post echoing it passes.
+	req := httptest.NewRequest(http.MethodPost, "/todos/create", strings.NewReader("title=Milk"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set(views.CSRFHeader, seen)
+	req.AddCookie(cookies[0])
+	rec = httptest.NewRecorder()
+	reached = false
+	csrfServer(&reached, &seen).ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !reached {
+		t.Errorf("post with the issued token: status = %d, reached = %t", rec.Code, reached)
+	}
+	if got := rec.Result().Cookies(); len(got) != 0 {
+		t.Errorf("cookies = %v, want none for a visitor that has one", got)
+	}
+}
+
+func TestAPIMethodNotAllowed(t *testing.T) {
+	api := NewTodoAPI(store.New())
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/todos", api.Collection)
+
+	rec := httptest.NewRecorder()
+	CSRF(mux).ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/api/v1/todos", nil))
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("status = %d, want 405", rec.Code)
+	}
+	if allow := rec.Header().Get("Allow"); allow != "GET, POST" {
+		t.Errorf("Allow = %q, want %q", allow, "GET, POST")
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("Content-Type = %q, want JSON", ct)
+	}
+	var body apiError
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("body is not JSON: %v", err)
+	}
+	if body.Error.Code != "method_not_allowed" {
+		t.Errorf("error code = %q, want method_not_allowed", body.Error.Code)
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"modern_todo_plain/internal/views"
+)
+
+// eventHeartbeat keeps idle event streams open through proxies.
+const eventHeartbeat = 25 * time.Second
+
+// Events streams store changes to open pages as Server-Sent Events. Each
+// change is a "todos-changed" event whose data is the JSON-encoded
+// store.Change; pages compare its revision with the one they rendered. The
+// first event carries the current revision, so a page that reconnects after
+// missing changes refreshes.
+func (h *TodoHandler) Events(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	changes, cancel := h.store.Subscribe()
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	_, _ = fmt.Fprintf(w, "retry: 3000\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			_, _ = fmt.Fprint(w, ": ping\n\n")
+		case change := <-changes:
+			data, err := json.Marshal(change)
+			if err != nil {
+				return
+			}
+			_, _ = fmt.Fprintf(w, "id: %d\nevent: todos-changed\ndata: %s\n\n", change.Revision, data)
+		}
+		flusher.Flush()
+	}
+}
+
+// Card renders a single todo card for the current view, so pages can refresh
+// a card another tab changed without re-rendering the whole app.
+func (h *TodoHandler) Card(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	todo, err := h.store.Get(id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeHTML(w, views.RenderTodoCard(todo, h.pageData(parseQuery(r))))
+}
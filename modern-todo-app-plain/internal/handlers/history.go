@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"modern_todo_plain/internal/store"
+	"modern_todo_plain/internal/views"
+)
+
+// Undo reverts the most recent add, edit, toggle or delete.
+func (h *TodoHandler) Undo(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	query := parseQuery(r)
+
+	label, err := h.store.Undo()
+	if errors.Is(err, store.ErrHistoryConflict) {
+		h.respondWithToast(w, r, query, store.Commit{}, &views.Toast{Message: historyConflict("undo", label), Failed: true})
+		return
+	}
+	if err != nil {
+		writeHistoryError(w, err)
+		return
+	}
+
+	h.respondWithToast(w, r, query, store.Commit{}, &views.Toast{Message: label, Undone: true})
+}
+
+// Redo reapplies the most recently undone change.
+func (h *TodoHandler) Redo(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	query := parseQuery(r)
+
+	label, err := h.store.Redo()
+	if errors.Is(err, store.ErrHistoryConflict) {
+		h.respondWithToast(w, r, query, store.Commit{}, &views.Toast{Message: historyConflict("redo", label), Failed: true})
+		return
+	}
+	if err != nil {
+		writeHistoryError(w, err)
+		return
+	}
+
+	h.respondWithToast(w, r, query, store.Commit{}, &views.Toast{Message: label})
+}
+
+// respondWithUndo is respondWithApp for reversible mutations: the re-rendered
+// shell carries a toast offering to undo the change, if this request recorded
+// one. The top of the shared history may be another page's step.
+func (h *TodoHandler) respondWithUndo(w http.ResponseWriter, r *http.Request, query store.Query, commit store.Commit) {
+	var toast *views.Toast
+	if commit.Undo != "" {
+		toast = &views.Toast{Message: commit.Undo}
+	}
+	h.respondWithToast(w, r, query, commit, toast)
+}
+
+// historyConflict explains a step the store dropped because its todo changed
+// after it was recorded.
+func historyConflict(verb, label string) string {
+	return fmt.Sprintf("Can't %s, the task has changed since: %s", verb, label)
+}
+
+func writeHistoryError(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrNothingToUndo) || errors.Is(err, store.ErrNothingToRedo) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	writeStoreError(w, err)
+}
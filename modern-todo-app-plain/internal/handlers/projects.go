@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"modern_todo_plain/internal/store"
+	"modern_todo_plain/internal/views"
+)
+
+// CreateProject adds a list from the sidebar dialog and switches to it.
+func (h *TodoHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form submission", http.StatusBadRequest)
+		return
+	}
+
+	query := parseQuery(r)
+
+	project, err := h.store.AddProject(r.FormValue("name"), r.FormValue("color"), r.FormValue("icon"))
+	if err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	query.ProjectID = project.ID
+	h.respondWithList(w, r, query)
+}
+
+// UpdateProject renames a list or changes its colour and icon.
+func (h *TodoHandler) UpdateProject(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form submission", http.StatusBadRequest)
+		return
+	}
+
+	id := r.PathValue("id")
+
+	query := parseQuery(r)
+
+	if _, err := h.store.UpdateProject(id, r.FormValue("name"), r.FormValue("color"), r.FormValue("icon")); err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	h.respondWithApp(w, r, query)
+}
+
+// DeleteProject removes a list; its todos move to the inbox.
+func (h *TodoHandler) DeleteProject(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	id := r.PathValue("id")
+
+	query := parseQuery(r)
+
+	if err := h.store.DeleteProject(id); err != nil {
+		writeProjectError(w, err)
+		return
+	}
+
+	if query.ProjectID == id {
+		query.ProjectID = store.InboxProjectID
+		h.respondWithList(w, r, query)
+		return
+	}
+	h.respondWithApp(w, r, query)
+}
+
+// respondWithList is respondWithApp for actions that change the list being
+// viewed, so the browser URL follows along.
+func (h *TodoHandler) respondWithList(w http.ResponseWriter, r *http.Request, query store.Query) {
+	if isHX(r) {
+		w.Header().Set("HX-Push-Url", views.ListURL(query))
+	}
+	h.respondWithApp(w, r, query)
+}
+
+func writeProjectError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, store.ErrInvalidProject), errors.Is(err, store.ErrInboxProject):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, store.ErrNotFound):
+		http.Error(w, "list not found", http.StatusNotFound)
+	default:
+		writeStoreError(w, err)
+	}
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"modern_todo_plain/internal/store"
+	"modern_todo_plain/internal/views"
+)
+
+// QuickAdd adds a todo typed on one line, reading its due date, priority and
+// tags out of the text.
+func (h *TodoHandler) QuickAdd(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form submission", http.StatusBadRequest)
+		return
+	}
+	query := parseQuery(r)
+
+	draft := quickDraft(r, query)
+	fields := draftErrors(draft.Validate())
+	var commit store.Commit
+	if fields == nil {
+		var err error
+		if _, commit, err = h.store.Add(draft); err != nil {
+			if fields = draftErrors(err); fields == nil {
+				writeStoreError(w, err)
+				return
+			}
+		}
+	}
+	if len(fields) > 0 {
+		if !isHX(r) {
+			h.renderRejected(w, r, query, views.RejectedForm{
+				Name:     "quick",
+				TodoForm: views.TodoForm{Values: r.PostForm, Errors: fields},
+				Draft:    draft,
+			})
+			return
+		}
+		w.Header().Set("HX-Retarget", "#quick-add-preview")
+		w.Header().Set("HX-Reswap", "innerHTML")
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(views.RenderQuickAddPreview(draft, fields)))
+		return
+	}
+	h.respondWithUndo(w, r, query, commit)
+}
+
+// QuickAddPreview shows how the quick-add text will be read. Empty text has
+// no preview.
+func (h *TodoHandler) QuickAddPreview(w http.ResponseWriter, r *http.Request) {
+	if strings.TrimSpace(r.FormValue("text")) == "" {
+		writeHTML(w, "")
+		return
+	}
+	draft := quickDraft(r, parseQuery(r))
+	writeHTML(w, views.RenderQuickAddPreview(draft, draftErrors(draft.Validate())))
+}
+
+// quickDraft parses the quick-add text in the browser's time zone, adding the
+// todo to the list being viewed.
+func quickDraft(r *http.Request, query store.Query) store.Draft {
+	loc, err := time.LoadLocation(strings.TrimSpace(r.FormValue("due_zone")))
+	if err != nil {
+		loc = time.UTC
+	}
+	draft := store.ParseQuickAdd(r.FormValue("text"), time.Now().In(loc))
+	draft.ProjectID = query.ProjectID
+	return draft
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"modern_todo_plain/internal/store"
+)
+
+func TestQuickAdd(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		hx   bool
+		// wantCode is the response status; wantAdded is whether a todo was
+		// added.
+		wantCode  int
+		wantAdded bool
+		// want lists text the response must contain.
+		want []string
+	}{
+		{
+			name:      "htmx",
+			text:      "Pay invoice tomorrow 5pm !high #finance",
+			hx:        true,
+			wantCode:  http.StatusOK,
+			wantAdded: true,
+			want:      []string{"Pay invoice"},
+		},
+		{
+			name:     "htmx without a title",
+			text:     "!high #finance",
+			hx:       true,
+			wantCode: http.StatusUnprocessableEntity,
+			want:     []string{"Title is required"},
+		},
+		{
+			name:      "plain post",
+			text:      "Pay invoice tomorrow 5pm !high #finance",
+			wantCode:  http.StatusSeeOther,
+			wantAdded: true,
+		},
+		{
+			name:     "plain post without a title",
+			text:     "!high #finance",
+			wantCode: http.StatusUnprocessableEntity,
+			want:     []string{"<!DOCTYPE html>", "Title is required", `value="!high #finance"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := store.New()
+			before := len(s.List(store.Query{}))
+			mux := http.NewServeMux()
+			mux.HandleFunc("POST /todos/quick", NewTodoHandler(s).QuickAdd)
+
+			form := url.Values{"text": {tt.text}, "due_zone": {"UTC"}}
+			req := httptest.NewRequest(http.MethodPost, "/todos/quick", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.hx {
+				req.Header.Set("HX-Request", "true")
+				req.Header.Set("HX-Target", "todo-results")
+			}
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantCode, rec.Body)
+			}
+			if tt.hx && !tt.wantAdded {
+				if got := rec.Header().Get("HX-Retarget"); got != "#quick-add-preview" {
+					t.Errorf("HX-Retarget = %q, want #quick-add-preview", got)
+				}
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(rec.Body.String(), want) {
+					t.Errorf("response does not contain %q", want)
+				}
+			}
+			if added := len(s.List(store.Query{})) > before; added != tt.wantAdded {
+				t.Errorf("todo added = %t, want %t", added, tt.wantAdded)
+			}
+		})
+	}
+}
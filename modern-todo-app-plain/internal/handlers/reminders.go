@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+
+	"modern_todo_plain/internal/store"
+	"modern_todo_plain/internal/views"
+)
+
+// Reminders renders the reminders panel, which polls it for reminders that
+// have gone off since the page loaded.
+func (h *TodoHandler) Reminders(w http.ResponseWriter, r *http.Request) {
+	if !isHX(r) {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+	writeHTML(w, views.RenderReminders(h.pageData(parseQuery(r))))
+}
+
+// DismissReminder removes a todo's reminder, leaving its due date.
+func (h *TodoHandler) DismissReminder(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	id := r.PathValue("id")
+
+	query := parseQuery(r)
+
+	_, commit, err := h.store.Patch(id, store.Patch{ClearRemind: true})
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	h.respondWithUndo(w, r, query, commit)
+}
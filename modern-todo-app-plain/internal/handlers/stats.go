@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"modern_todo_plain/internal/views"
+)
+
+// Stats renders the productivity dashboard, with days in the browser's time
+// zone; unknown or empty zones fall back to UTC.
+func (h *TodoHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	loc, err := time.LoadLocation(strings.TrimSpace(r.URL.Query().Get("zone")))
+	if err != nil {
+		loc = time.UTC
+	}
+	analytics := h.store.Analytics(time.Now(), loc)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = fmt.Fprint(w, "<!DOCTYPE html>\n")
+	_, _ = fmt.Fprint(w, views.RenderStatsPage(analytics))
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"modern_todo_plain/internal/store"
+)
+
+// AddSubtask appends a checklist item to a todo.
+func (h *TodoHandler) AddSubtask(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form submission", http.StatusBadRequest)
+		return
+	}
+
+	id := r.PathValue("id")
+
+	query := parseQuery(r)
+
+	if _, _, err := h.store.AddSubtask(id, r.FormValue("subtask")); err != nil {
+		writeSubtaskError(w, err)
+		return
+	}
+
+	h.respondWithApp(w, r, query)
+}
+
+// ToggleSubtask flips a checklist item.
+func (h *TodoHandler) ToggleSubtask(w http.ResponseWriter, r *http.Request) {
+	h.changeSubtask(w, r, h.store.ToggleSubtask)
+}
+
+// DeleteSubtask removes a checklist item.
+func (h *TodoHandler) DeleteSubtask(w http.ResponseWriter, r *http.Request) {
+	h.changeSubtask(w, r, h.store.DeleteSubtask)
+}
+
+func (h *TodoHandler) changeSubtask(w http.ResponseWriter, r *http.Request, change func(todoID, subtaskID string) (store.Todo, store.Commit, error)) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	id, subtaskID := r.PathValue("id"), r.PathValue("subtask")
+
+	query := parseQuery(r)
+
+	if _, _, err := change(id, subtaskID); err != nil {
+		writeSubtaskError(w, err)
+		return
+	}
+
+	h.respondWithApp(w, r, query)
+}
+
+func writeSubtaskError(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrInvalidSubtask) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeStoreError(w, err)
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"modern_todo_plain/internal/store"
+)
+
+// RenameTag renames a tag across all todos from the tag manager.
+func (h *TodoHandler) RenameTag(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form submission", http.StatusBadRequest)
+		return
+	}
+
+	from := store.NormalizeTag(r.FormValue("from"))
+	to := store.NormalizeTag(r.FormValue("to"))
+	query := parseQuery(r)
+
+	commit, err := h.store.RenameTag(from, to)
+	if err != nil {
+		writeTagError(w, err)
+		return
+	}
+
+	query.Tags = replaceTag(query.Tags, from, to)
+	h.respondWithUndo(w, r, query, commit)
+}
+
+// DeleteTag removes a tag from all todos from the tag manager.
+func (h *TodoHandler) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form submission", http.StatusBadRequest)
+		return
+	}
+
+	name := store.NormalizeTag(r.PathValue("name"))
+	query := parseQuery(r)
+
+	commit, err := h.store.DeleteTag(name)
+	if err != nil {
+		writeTagError(w, err)
+		return
+	}
+
+	query.Tags = replaceTag(query.Tags, name, "")
+	h.respondWithUndo(w, r, query, commit)
+}
+
+// replaceTag keeps the current tag selection in sync with a rename or delete.
+func replaceTag(tags []string, from, to string) []string {
+	out := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag == from {
+			tag = to
+		}
+		out = append(out, tag)
+	}
+	return store.NormalizeTags(out)
+}
+
+func writeTagError(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrInvalidTag) {
+		http.Error(w, "tag name is required", http.StatusBadRequest)
+		return
+	}
+	writeStoreError(w, err)
+}
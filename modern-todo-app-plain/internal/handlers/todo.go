@@ -1,9 +1,13 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"modern_todo_plain/internal/store"
 	"modern_todo_plain/internal/views"
@@ -18,14 +22,44 @@ func NewTodoHandler(store *store.Store) *TodoHandler {
 }
 
 func (h *TodoHandler) Index(w http.ResponseWriter, r *http.Request) {
-	filter := parseFilter(r.URL.Query().Get("filter"))
-	data := h.pageData(filter)
+	h.render(w, r, parseQuery(r))
+}
 
-	if isHX(r) && r.URL.Query().Get("partial") == "app" {
-		writeHTML(w, views.RenderAppShell(data))
+// List renders the todos of one list at /lists/{id}.
+func (h *TodoHandler) List(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := h.store.Project(id); err != nil {
+		http.NotFound(w, r)
 		return
 	}
 
+	query := parseQuery(r)
+	query.ProjectID = id
+	h.render(w, r, query)
+}
+
+func (h *TodoHandler) render(w http.ResponseWriter, r *http.Request, query store.Query) {
+	data := h.pageData(query)
+	data.CSRFToken = csrfToken(r)
+
+	if isHX(r) {
+		switch r.URL.Query().Get("partial") {
+		case "app":
+			// Form controls such as the sort select have no hx-push-url of
+			// their own, so push the canonical URL for the new view.
+			if r.Header.Get("HX-Trigger-Name") != "" {
+				w.Header().Set("HX-Push-Url", views.ListURL(query))
+			}
+			writeHTML(w, views.RenderAppShell(data))
+			return
+		case "list":
+			// Search refreshes only the list so the search box keeps focus.
+			w.Header().Set("HX-Replace-Url", views.ListURL(query))
+			writeHTML(w, views.RenderTodoList(data))
+			return
+		}
+	}
+
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	_, _ = fmt.Fprint(w, "<!DOCTYPE html>\n")
 	_, _ = fmt.Fprint(w, views.RenderFullPage(data))
@@ -36,17 +70,24 @@ func (h *TodoHandler) Create(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid form submission", http.StatusBadRequest)
 		return
 	}
-	title := strings.TrimSpace(r.FormValue("title"))
-	if title == "" {
-		http.Error(w, "title is required", http.StatusBadRequest)
+	query := parseQuery(r)
+
+	draft, fields := formDraft(r, query)
+	var commit store.Commit
+	if len(fields) == 0 {
+		var err error
+		if _, commit, err = h.store.Add(draft); err != nil {
+			if fields = draftErrors(err); fields == nil {
+				writeStoreError(w, err)
+				return
+			}
+		}
+	}
+	if len(fields) > 0 {
+		h.respondWithInvalid(w, r, query, "add", fields)
 		return
 	}
-	description := strings.TrimSpace(r.FormValue("description"))
-	priority := parsePriority(r.FormValue("priority"))
-	filter := parseFilter(r.FormValue("filter"))
-
-	h.store.Add(title, description, priority)
-	h.respondWithApp(w, r, filter)
+	h.respondWithUndo(w, r, query, commit)
 }
 
 func (h *TodoHandler) Update(w http.ResponseWriter, r *http.Request) {
@@ -55,88 +96,198 @@ func (h *TodoHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id := r.FormValue("id")
-	if id == "" {
-		http.Error(w, "invalid todo id", http.StatusBadRequest)
+	id := r.PathValue("id")
+	query := parseQuery(r)
+
+	draft, fields := formDraft(r, query)
+	draft.Version = parseVersion(r.FormValue("version"))
+	var commit store.Commit
+	if len(fields) == 0 {
+		var err error
+		if _, commit, err = h.store.Update(id, draft); err != nil {
+			if errors.Is(err, store.ErrVersionConflict) {
+				h.respondWithConflict(w, r, query, id, draft)
+				return
+			}
+			if fields = draftErrors(err); fields == nil {
+				writeStoreError(w, err)
+				return
+			}
+		}
+	}
+	if len(fields) > 0 {
+		h.respondWithInvalid(w, r, query, "edit", fields)
 		return
 	}
+	h.respondWithUndo(w, r, query, commit)
+}
 
-	title := strings.TrimSpace(r.FormValue("title"))
-	if title == "" {
-		http.Error(w, "title is required", http.StatusBadRequest)
+func (h *TodoHandler) Toggle(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
 		return
 	}
 
-	description := strings.TrimSpace(r.FormValue("description"))
-	priority := parsePriority(r.FormValue("priority"))
-	filter := parseFilter(r.FormValue("filter"))
+	id := r.PathValue("id")
 
-	if _, err := h.store.Update(id, title, description, priority); err != nil {
-		http.Error(w, "todo not found", http.StatusNotFound)
+	query := parseQuery(r)
+
+	_, commit, err := h.store.Toggle(id)
+	if err != nil {
+		writeStoreError(w, err)
 		return
 	}
 
-	h.respondWithApp(w, r, filter)
+	h.respondWithUndo(w, r, query, commit)
 }
 
-func (h *TodoHandler) Toggle(w http.ResponseWriter, r *http.Request) {
+func (h *TodoHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
 		http.Error(w, "invalid request", http.StatusBadRequest)
 		return
 	}
 
-	id := r.URL.Query().Get("id")
-	if id == "" {
-		http.Error(w, "invalid todo id", http.StatusBadRequest)
-		return
-	}
+	id := r.PathValue("id")
 
-	filter := parseFilter(r.FormValue("filter"))
+	query := parseQuery(r)
 
-	if _, err := h.store.Toggle(id); err != nil {
-		http.Error(w, "todo not found", http.StatusNotFound)
+	commit, err := h.store.Delete(id)
+	if err != nil {
+		writeStoreError(w, err)
 		return
 	}
 
-	h.respondWithApp(w, r, filter)
+	h.respondWithUndo(w, r, query, commit)
 }
 
-func (h *TodoHandler) Delete(w http.ResponseWriter, r *http.Request) {
+// Reorder moves a todo before or after another one in the manual order.
+func (h *TodoHandler) Reorder(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
 		http.Error(w, "invalid request", http.StatusBadRequest)
 		return
 	}
 
-	id := r.URL.Query().Get("id")
-	if id == "" {
-		http.Error(w, "invalid todo id", http.StatusBadRequest)
+	id := r.PathValue("id")
+
+	query := parseQuery(r)
+
+	_, commit, err := h.store.Move(id, r.FormValue("before"), r.FormValue("after"))
+	if err != nil {
+		if errors.Is(err, store.ErrInvalidMove) {
+			http.Error(w, "invalid move", http.StatusBadRequest)
+			return
+		}
+		writeStoreError(w, err)
 		return
 	}
 
-	filter := parseFilter(r.FormValue("filter"))
+	h.respondWithUndo(w, r, query, commit)
+}
 
-	if err := h.store.Delete(id); err != nil {
-		http.Error(w, "todo not found", http.StatusNotFound)
+func (h *TodoHandler) respondWithApp(w http.ResponseWriter, r *http.Request, query store.Query) {
+	h.respondWithToast(w, r, query, store.Commit{}, nil)
+}
+
+// respondWithToast re-renders whatever the htmx request targeted: a single
+// card, the list section or the whole app. Partial responses carry
+// out-of-band updates for the sidebar counts, progress bar and toast.
+//
+// commit is what the request changed. A card is swapped on its own only when
+// the commit touched no other todo; otherwise, as when completing a repeating
+// todo adds its next occurrence, the list is re-rendered, since the page will
+// skip the live update for a revision its own response already covers.
+func (h *TodoHandler) respondWithToast(w http.ResponseWriter, r *http.Request, query store.Query, commit store.Commit, toast *views.Toast) {
+	if !isHX(r) {
+		http.Redirect(w, r, views.ListURL(query), http.StatusSeeOther)
 		return
 	}
+	data := h.pageData(query)
+	data.Toast = toast
 
-	h.respondWithApp(w, r, filter)
+	id := r.PathValue("id")
+	switch target := r.Header.Get("HX-Target"); {
+	case target == "todo-results":
+		writeHTML(w, views.RenderResultsUpdate(data))
+	case id != "" && target == views.CardID(id):
+		if data.Total == 0 || !onlyTodo(commit, id) {
+			// The last card left the view, so the empty state shows, or
+			// other todos changed too.
+			w.Header().Set("HX-Retarget", "#todo-results")
+			w.Header().Set("HX-Reswap", "outerHTML")
+			writeHTML(w, views.RenderResultsUpdate(data))
+			return
+		}
+		todo, inView := h.store.InView(query, id)
+		writeHTML(w, views.RenderCardUpdate(todo, inView, data))
+	default:
+		writeHTML(w, views.RenderAppShell(data))
+	}
 }
 
-func (h *TodoHandler) respondWithApp(w http.ResponseWriter, r *http.Request, filter store.Filter) {
-	data := h.pageData(filter)
-	if isHX(r) {
-		writeHTML(w, views.RenderAppShell(data))
+// onlyTodo reports whether commit changed no todo other than id.
+func onlyTodo(commit store.Commit, id string) bool {
+	for _, changed := range commit.TodoIDs {
+		if changed != id {
+			return false
+		}
+	}
+	return true
+}
+
+// pageSize is the number of cards rendered at once; the list loads more as it
+// is scrolled.
+const pageSize = 50
+
+// More renders the page of cards after a cursor, for the list's infinite
+// scroll.
+func (h *TodoHandler) More(w http.ResponseWriter, r *http.Request) {
+	cursor, err := store.ParseCursor(r.URL.Query().Get("cursor"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	http.Redirect(w, r, fmt.Sprintf("/?filter=%s", filter), http.StatusSeeOther)
+	if !isHX(r) {
+		http.Redirect(w, r, views.ListURL(cursor.Query), http.StatusSeeOther)
+		return
+	}
+	writeHTML(w, views.RenderMoreTodos(h.viewData(cursor.Query, h.store.ListPage(cursor, pageSize))))
+}
+
+// pageData renders the first page of a view.
+func (h *TodoHandler) pageData(query store.Query) views.PageData {
+	return h.viewData(query, h.store.ListPage(store.Cursor{Query: query}, pageSize))
 }
 
-func (h *TodoHandler) pageData(filter store.Filter) views.PageData {
+func (h *TodoHandler) viewData(query store.Query, page store.Page) views.PageData {
 	return views.PageData{
-		Todos:  h.store.List(filter),
-		Filter: filter,
-		Stats:  h.store.Stats(),
+		Todos:     page.Todos,
+		Total:     page.Total,
+		Next:      page.Next,
+		Filter:    query.Filter,
+		Tags:      query.Tags,
+		Search:    query.Search,
+		Sort:      query.Sort,
+		ProjectID: query.ProjectID,
+		Projects:  h.store.Projects(),
+		Stats:     h.store.ProjectStats(query.ProjectID),
+		TagStats:  h.store.Tags(),
+		Reminders: h.store.Reminders(time.Now()),
+		FeedURL:   "/calendar/" + h.store.FeedToken() + ".ics",
+		Now:       time.Now(),
+		Revision:  h.store.Revision(),
+	}
+}
+
+// parseQuery reads the view state (filter, selected tags, search text, sort
+// order and list) from the query string or form body.
+func parseQuery(r *http.Request) store.Query {
+	_ = r.ParseForm()
+	return store.Query{
+		Filter:    parseFilter(r.Form.Get("filter")),
+		Tags:      store.NormalizeTags(r.Form["tag"]),
+		Search:    strings.TrimSpace(r.Form.Get("q")),
+		Sort:      parseSort(r.Form.Get("sort")),
+		ProjectID: r.Form.Get("list"),
 	}
 }
 
@@ -146,20 +297,110 @@ func parseFilter(raw string) store.Filter {
 		return store.FilterActive
 	case string(store.FilterCompleted):
 		return store.FilterCompleted
+	case string(store.FilterOverdue):
+		return store.FilterOverdue
+	case string(store.FilterThisWeek):
+		return store.FilterThisWeek
+	case string(store.FilterTrash):
+		return store.FilterTrash
 	default:
 		return store.FilterAll
 	}
 }
 
-func parsePriority(raw string) store.Priority {
+func parseSort(raw string) store.Sort {
 	switch strings.ToLower(raw) {
-	case string(store.PriorityLow):
-		return store.PriorityLow
-	case string(store.PriorityHigh):
-		return store.PriorityHigh
+	case string(store.SortCreated):
+		return store.SortCreated
+	case string(store.SortPriority):
+		return store.SortPriority
+	case string(store.SortDue):
+		return store.SortDue
 	default:
-		return store.PriorityMedium
+		return store.SortManual
+	}
+}
+
+// dueInputLayouts are the formats produced by datetime-local and date inputs.
+var dueInputLayouts = []string{"2006-01-02T15:04", "2006-01-02T15:04:05", "2006-01-02"}
+
+// parseDue reads a due date entered in the browser's local time. zone is the
+// browser's IANA time zone; unknown or empty zones fall back to UTC. An empty
+// value means no due date.
+func parseDue(value, zone string) (*time.Time, string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, "", nil
+	}
+
+	loc, err := time.LoadLocation(strings.TrimSpace(zone))
+	if err != nil {
+		loc = time.UTC
+	}
+
+	for _, layout := range dueInputLayouts {
+		due, err := time.ParseInLocation(layout, value, loc)
+		if err != nil {
+			continue
+		}
+		if layout == "2006-01-02" {
+			// A bare date is due at the end of that day.
+			y, m, d := due.Date()
+			due = time.Date(y, m, d, 23, 59, 0, 0, loc)
+		}
+		return &due, loc.String(), nil
+	}
+	return nil, "", fmt.Errorf("unrecognised due date %q", value)
+}
+
+// formProject reads the list chosen in a todo dialog, falling back to the list
+// being viewed.
+func formProject(r *http.Request, query store.Query) string {
+	if project := r.FormValue("project"); project != "" {
+		return project
+	}
+	return query.ProjectID
+}
+
+// formRecurrence reads the repeat preset, or the custom RRULE when the
+// "custom" preset is chosen.
+func formRecurrence(r *http.Request) string {
+	repeat := strings.TrimSpace(r.FormValue("repeat"))
+	if repeat == "custom" {
+		return strings.TrimSpace(r.FormValue("rrule"))
+	}
+	return repeat
+}
+
+// parseVersion reads the todo version an edit was based on. Missing or
+// malformed versions are 0, which skips the conflict check.
+func parseVersion(raw string) uint64 {
+	version, err := strconv.ParseUint(strings.TrimSpace(raw), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return version
+}
+
+func writeStoreError(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrNotFound) {
+		http.Error(w, "todo not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, store.ErrVersionConflict) || errors.Is(err, store.ErrNotTrashed) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if errors.Is(err, store.ErrInvalidTodo) {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if errors.Is(err, store.ErrInvalidRecurrence) || errors.Is(err, store.ErrUnknownProject) || errors.Is(err, store.ErrInvalidBatch) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
+	log.Printf("todo store: %v", err)
+	http.Error(w, "could not save changes", http.StatusInternalServerError)
 }
 
 func isHX(r *http.Request) bool {
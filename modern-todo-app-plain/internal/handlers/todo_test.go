@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"modern_todo_plain/internal/store"
+	"modern_todo_plain/internal/views"
+)
+
+// cardPost posts to a todo action the way a button on its card does.
+func cardPost(t *testing.T, h *TodoHandler, action, id string) *httptest.ResponseRecorder {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /todos/{id}/toggle", h.Toggle)
+	mux.HandleFunc("POST /todos/{id}/delete", h.Delete)
+
+	req := httptest.NewRequest(http.MethodPost, "/todos/"+id+"/"+action, strings.NewReader(""))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	req.Header.Set("HX-Target", views.CardID(id))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	return rec
+}
+
+func TestCardActions(t *testing.T) {
+	due := time.Now().Add(24 * time.Hour).UTC()
+	tests := []struct {
+		name   string
+		action string
+		draft  store.Draft
+		// wantList is set when the response must re-render the list rather
+		// than the card alone.
+		wantList bool
+	}{
+		{
+			name:   "toggle a todo from its card",
+			action: "toggle",
+			draft:  store.Draft{Title: "Send the invoice", Priority: store.PriorityMedium},
+		},
+		{
+			name:     "toggle a recurring todo from its card",
+			action:   "toggle",
+			draft:    store.Draft{Title: "Water the plants", Priority: store.PriorityMedium, DueAt: &due, DueZone: "UTC", Recurrence: "FREQ=DAILY"},
+			wantList: true,
+		},
+		{
+			name:   "delete a recurring todo from its card",
+			action: "delete",
+			draft:  store.Draft{Title: "Water the plants", Priority: store.PriorityMedium, DueAt: &due, DueZone: "UTC", Recurrence: "FREQ=DAILY"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := store.New()
+			todo, _, err := s.Add(tt.draft)
+			if err != nil {
+				t.Fatal(err)
+			}
+			before := len(s.List(store.Query{}))
+
+			rec := cardPost(t, NewTodoHandler(s), tt.action, todo.ID)
+
+			retarget := rec.Header().Get("HX-Retarget")
+			if got := retarget == "#todo-results"; got != tt.wantList {
+				t.Errorf("HX-Retarget = %q, want the list re-rendered: %t", retarget, tt.wantList)
+			}
+			if !tt.wantList {
+				return
+			}
+
+			// The next occurrence is in the response, so this page shows it
+			// even though it will skip the live update for this revision.
+			todos := s.List(store.Query{})
+			if len(todos) != before+1 {
+				t.Fatalf("list has %d todos, want the next occurrence added to %d", len(todos), before)
+			}
+			for _, next := range todos {
+				if next.Title == todo.Title && next.ID != todo.ID {
+					if !strings.Contains(rec.Body.String(), `id="`+views.CardID(next.ID)+`"`) {
+						t.Errorf("response has no card for the next occurrence %s", next.ID)
+					}
+					return
+				}
+			}
+			t.Error("no next occurrence was added")
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"modern_todo_plain/internal/store"
+	"modern_todo_plain/internal/views"
+)
+
+// maxImportSize limits uploaded import files.
+const maxImportSize = 5 << 20
+
+// Export downloads the todos in the current view as JSON, CSV, todo.txt,
+// Markdown or iCalendar.
+func (h *TodoHandler) Export(w http.ResponseWriter, r *http.Request) {
+	format, ok := store.ParseFormat(r.URL.Query().Get("format"))
+	if !ok {
+		http.Error(w, "format must be json, csv, todotxt, md or ics", http.StatusBadRequest)
+		return
+	}
+
+	todos := h.store.List(parseQuery(r))
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "todos."+format.Extension()))
+	if err := store.Export(w, format, todos); err != nil {
+		log.Printf("export todos: %v", err)
+	}
+}
+
+// Import reads an uploaded export into a list. With dry_run set it renders a
+// preview of what would be added and which items are duplicates.
+func (h *TodoHandler) Import(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	if err := r.ParseMultipartForm(maxImportSize); err != nil {
+		http.Error(w, "invalid upload", http.StatusBadRequest)
+		return
+	}
+
+	query := parseQuery(r)
+	dryRun := r.FormValue("dry_run") != ""
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		h.importFailed(w, r, dryRun, "Choose a file to import.")
+		return
+	}
+	defer file.Close()
+
+	format, ok := store.ParseFormat(r.FormValue("format"))
+	if !ok {
+		if format, ok = store.FormatForFile(header.Filename); !ok {
+			h.importFailed(w, r, dryRun, "Could not tell the file format; pick one from the list.")
+			return
+		}
+	}
+
+	// Bare dates in the file are read in the browser's time zone.
+	loc, err := time.LoadLocation(strings.TrimSpace(r.FormValue("due_zone")))
+	if err != nil {
+		loc = time.UTC
+	}
+	todos, err := store.Decode(file, format, loc)
+	if err != nil {
+		h.importFailed(w, r, dryRun, err.Error())
+		return
+	}
+
+	result, err := h.store.Import(todos, formProject(r, query), dryRun)
+	if err != nil {
+		if errors.Is(err, store.ErrInvalidImport) || errors.Is(err, store.ErrUnknownProject) {
+			h.importFailed(w, r, dryRun, err.Error())
+			return
+		}
+		writeStoreError(w, err)
+		return
+	}
+
+	if dryRun {
+		writeHTML(w, views.RenderImportPreview(result))
+		return
+	}
+
+	message := fmt.Sprintf("Imported %d tasks", len(result.Added))
+	if n := len(result.Duplicates); n > 0 {
+		message += fmt.Sprintf(", skipped %d duplicates", n)
+	}
+	h.respondWithToast(w, r, query, store.Commit{}, &views.Toast{Message: message})
+}
+
+// importFailed shows the problem in the preview area. A dry run targets the
+// preview already; a real import is retargeted there as a 422 so the dialog
+// stays open. Without htmx the import is rejected outright.
+func (h *TodoHandler) importFailed(w http.ResponseWriter, r *http.Request, dryRun bool, message string) {
+	switch {
+	case dryRun:
+		writeHTML(w, views.RenderImportError(message))
+	case isHX(r):
+		w.Header().Set("HX-Retarget", "#import-preview")
+		w.Header().Set("HX-Reswap", "innerHTML")
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(views.RenderImportError(message)))
+	default:
+		http.Error(w, message, http.StatusBadRequest)
+	}
+}
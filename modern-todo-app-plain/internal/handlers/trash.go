@@ -0,0 +1,59 @@
+package handlers
+
+import "net/http"
+
+// Restore takes a todo out of the trash.
+func (h *TodoHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	id := r.PathValue("id")
+
+	query := parseQuery(r)
+
+	_, commit, err := h.store.Restore(id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	h.respondWithUndo(w, r, query, commit)
+}
+
+// Purge permanently deletes one todo from the trash.
+func (h *TodoHandler) Purge(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	id := r.PathValue("id")
+
+	query := parseQuery(r)
+
+	if err := h.store.Purge(id); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	h.respondWithApp(w, r, query)
+}
+
+// EmptyTrash permanently deletes every todo in the trash.
+func (h *TodoHandler) EmptyTrash(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	query := parseQuery(r)
+
+	if _, err := h.store.EmptyTrash(); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	h.respondWithApp(w, r, query)
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"modern_todo_plain/internal/store"
+	"modern_todo_plain/internal/views"
+)
+
+// formDraft reads the add and edit dialogs into a draft and validates it.
+// Field errors are keyed as in store.ValidationError, plus "due" for dates
+// that cannot be parsed.
+func formDraft(r *http.Request, query store.Query) (store.Draft, map[string]string) {
+	fields := map[string]string{}
+
+	dueAt, dueZone, err := parseDue(r.FormValue("due"), r.FormValue("due_zone"))
+	if err != nil {
+		fields["due"] = "enter a valid date and time"
+	}
+
+	remind, err := parseRemind(r.FormValue("remind"))
+	if err != nil {
+		fields["remind"] = "choose when to be reminded"
+	}
+
+	priority := store.Priority(strings.ToLower(strings.TrimSpace(r.FormValue("priority"))))
+	if priority == "" {
+		priority = store.PriorityMedium
+	}
+
+	draft := store.Draft{
+		Title:         strings.TrimSpace(r.FormValue("title")),
+		Description:   strings.TrimSpace(r.FormValue("description")),
+		Priority:      priority,
+		DueAt:         dueAt,
+		DueZone:       dueZone,
+		RemindMinutes: remind,
+		Tags:          store.ParseTags(r.FormValue("tags")),
+		Recurrence:    formRecurrence(r),
+		ProjectID:     formProject(r, query),
+	}
+	for name, message := range draftErrors(draft.Validate()) {
+		fields[name] = message
+	}
+	return draft, fields
+}
+
+// parseRemind reads the reminder choice, in minutes before the due date; an
+// empty choice is no reminder.
+func parseRemind(value string) (*int, error) {
+	if value == "" {
+		return nil, nil
+	}
+	minutes, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+	return &minutes, nil
+}
+
+// draftErrors converts store errors about a draft into field errors. It
+// returns nil for errors that are not the submitter's to fix.
+func draftErrors(err error) map[string]string {
+	var invalid *store.ValidationError
+	switch {
+	case errors.As(err, &invalid):
+		return invalid.Fields
+	case errors.Is(err, store.ErrUnknownProject):
+		return map[string]string{"project": "choose one of your lists"}
+	}
+	return nil
+}
+
+// respondWithInvalid rejects a todo form with 422 and shows it again with the
+// values kept and a message under each invalid field. htmx requests get the
+// form alone, retargeted over the one submitted; plain form posts get the
+// full page with the form's dialog open.
+func (h *TodoHandler) respondWithInvalid(w http.ResponseWriter, r *http.Request, query store.Query, form string, fields map[string]string) {
+	state := views.TodoForm{Values: r.PostForm, Errors: fields}
+	if form == "edit" {
+		// The form posts to the edited todo's path, not its own fields.
+		state.Values.Set("id", r.PathValue("id"))
+	}
+	if !isHX(r) {
+		h.renderRejected(w, r, query, views.RejectedForm{Name: form, TodoForm: state})
+		return
+	}
+
+	data := h.pageData(query)
+	html := views.RenderAddTodoForm(data, state)
+	if form == "edit" {
+		html = views.RenderEditTodoForm(data, state)
+	}
+
+	w.Header().Set("HX-Retarget", "#"+form+"-form")
+	w.Header().Set("HX-Reswap", "outerHTML")
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	_, _ = w.Write([]byte(html))
+}
+
+// renderRejected answers a plain form post that failed validation with 422
+// and the full page, showing the rejected form again.
+func (h *TodoHandler) renderRejected(w http.ResponseWriter, r *http.Request, query store.Query, rejected views.RejectedForm) {
+	data := h.pageData(query)
+	data.CSRFToken = csrfToken(r)
+	data.Rejected = &rejected
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	_, _ = fmt.Fprint(w, "<!DOCTYPE html>\n")
+	_, _ = fmt.Fprint(w, views.RenderFullPage(data))
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"modern_todo_plain/internal/store"
+)
+
+// formPost posts a todo form to the create and update routes of h.
+func formPost(t *testing.T, h *TodoHandler, path string, form url.Values, hx bool) *httptest.ResponseRecorder {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /todos/create", h.Create)
+	mux.HandleFunc("POST /todos/{id}/update", h.Update)
+
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if hx {
+		req.Header.Set("HX-Request", "true")
+		req.Header.Set("HX-Target", "todo-results")
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestFormValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		// edit posts to the todo's update path instead of creating one.
+		edit         bool
+		form         url.Values
+		wantRetarget string
+		// want lists text the re-rendered form must contain: messages and
+		// the values kept from the submission.
+		want []string
+	}{
+		{
+			name:         "add without a title",
+			form:         url.Values{"title": {" "}, "description": {"Ask about the deposit"}},
+			wantRetarget: "#add-form",
+			want:         []string{"title is required", "Ask about the deposit"},
+		},
+		{
+			name:         "add with a bad due date and priority",
+			form:         url.Values{"title": {"Call the landlord"}, "due": {"someday"}, "priority": {"urgent"}},
+			wantRetarget: "#add-form",
+			want:         []string{"enter a valid date and time", "priority must be low, medium, or high", "Call the landlord"},
+		},
+		{
+			name:         "edit with a long title",
+			edit:         true,
+			form:         url.Values{"title": {strings.Repeat("x", store.MaxTitleLength+1)}},
+			wantRetarget: "#edit-form",
+			want:         []string{"title must be at most", "/update"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := store.New()
+			todo, _, err := s.Add(store.Draft{Title: "Call the landlord", Priority: store.PriorityMedium})
+			if err != nil {
+				t.Fatal(err)
+			}
+			before := len(s.List(store.Query{}))
+			path := "/todos/create"
+			if tt.edit {
+				path = "/todos/" + todo.ID + "/update"
+			}
+
+			rec := formPost(t, NewTodoHandler(s), path, tt.form, true)
+			if rec.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("status = %d, want 422: %s", rec.Code, rec.Body)
+			}
+			if got := rec.Header().Get("HX-Retarget"); got != tt.wantRetarget {
+				t.Errorf("HX-Retarget = %q, want %q", got, tt.wantRetarget)
+			}
+			if got := rec.Header().Get("HX-Reswap"); got != "outerHTML" {
+				t.Errorf("HX-Reswap = %q, want outerHTML", got)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(rec.Body.String(), want) {
+					t.Errorf("form does not contain %q", want)
+				}
+			}
+
+			if got := len(s.List(store.Query{})); got != before {
+				t.Errorf("list has %d todos, want %d", got, before)
+			}
+			if saved, _ := s.Get(todo.ID); saved.Version != todo.Version {
+				t.Errorf("todo saved as version %d, want it untouched", saved.Version)
+			}
+		})
+	}
+}
+
+func TestPlainFormValidation(t *testing.T) {
+	s := store.New()
+	form := url.Values{"title": {" "}, "description": {"Ask about the deposit"}}
+	rec := formPost(t, NewTodoHandler(s), "/todos/create", form, false)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want 422: %s", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/html") {
+		t.Errorf("Content-Type = %q, want the HTML page", got)
+	}
+
+	page := rec.Body.String()
+	if !strings.HasPrefix(page, "<!DOCTYPE html>") {
+		t.Errorf("response is not a full page: %.60s", page)
+	}
+	// The add dialog renders open, so no script is needed to show it.
+	dialog := regexp.MustCompile(`<dialog[^>]*id="add-dialog"[^>]*>`).FindString(page)
+	if !strings.Contains(dialog, "open") {
+		t.Errorf("add dialog tag = %q, want it open", dialog)
+	}
+	for _, want := range []string{"title is required", "Ask about the deposit"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page does not contain %q", want)
+		}
+	}
+}
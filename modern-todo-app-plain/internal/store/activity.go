@@ -0,0 +1,191 @@
+package store
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxEvents bounds the activity log; the oldest events are dropped first.
+const maxEvents = 2000
+
+// EventKind says what happened to a todo.
+type EventKind string
+
+const (
+	EventCreated   EventKind = "created"
+	EventEdited    EventKind = "edited"
+	EventCompleted EventKind = "completed"
+	EventReopened  EventKind = "reopened"
+	EventDeleted   EventKind = "deleted"
+	EventRestored  EventKind = "restored"
+	// EventRemoved is logged when a todo disappears for good: purged from the
+	// trash, or an add that was undone.
+	EventRemoved EventKind = "removed"
+)
+
+// Event is one entry in the append-only activity log. Events are derived from
+// every committed change, including undo and redo, and are never rewritten.
+type Event struct {
+	TodoID string    `json:"todo_id"`
+	Kind   EventKind `json:"kind"`
+	At     time.Time `json:"at"`
+	// Title is the todo's title after the change (before it, for removals),
+	// so the log still reads well once the todo is gone.
+	Title string `json:"title"`
+	// Changes lists the edited fields; it is only set for EventEdited.
+	Changes []FieldChange `json:"changes,omitempty"`
+}
+
+// FieldChange is the before and after value of one edited field, formatted
+// for display. An empty value means the field was blank.
+type FieldChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// Activity returns the events of one todo, newest first.
+func (s *Store) Activity(id string) ([]Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, err := s.findLocked(id); err != nil {
+		return nil, err
+	}
+	var events []Event
+	for i := len(s.events) - 1; i >= 0; i-- {
+		if s.events[i].TodoID == id {
+			events = append(events, s.events[i])
+		}
+	}
+	return events, nil
+}
+
+// RecentActivity returns up to limit events across all todos, newest first.
+// A negative limit is treated as zero.
+func (s *Store) RecentActivity(limit int) []Event {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	limit = max(limit, 0)
+	events := make([]Event, 0, min(limit, len(s.events)))
+	for i := len(s.events) - 1; i >= 0 && len(events) < limit; i-- {
+		events = append(events, s.events[i])
+	}
+	return events
+}
+
+// logLocked appends an event for every todo that differs from prev. Moves in
+// the manual order are not logged.
+func (s *Store) logLocked(prev Snapshot, now time.Time) {
+	before := make(map[string]*Todo, len(prev.Todos))
+	for i := range prev.Todos {
+		before[prev.Todos[i].ID] = &prev.Todos[i]
+	}
+
+	for _, todo := range s.todos {
+		old, existed := before[todo.ID]
+		delete(before, todo.ID)
+		if !existed {
+			s.events = append(s.events, Event{TodoID: todo.ID, Kind: EventCreated, At: now, Title: todo.Title})
+			continue
+		}
+		s.events = append(s.events, todoEvents(*old, *todo, now)...)
+	}
+
+	// Removed todos are logged in a stable order.
+	removed := make([]*Todo, 0, len(before))
+	for _, old := range before {
+		removed = append(removed, old)
+	}
+	sort.Slice(removed, func(i, j int) bool { return removed[i].ID < removed[j].ID })
+	for _, old := range removed {
+		s.events = append(s.events, Event{TodoID: old.ID, Kind: EventRemoved, At: now, Title: old.Title})
+	}
+
+	// Reslicing drops the oldest events without copying the rest; the next
+	// append that outgrows the array copies only the kept window.
+	if len(s.events) > maxEvents {
+		s.events = s.events[len(s.events)-maxEvents:]
+	}
+}
+
+// todoEvents describes the change from old to todo: an edit listing the
+// changed fields, then completion and trash changes.
+func todoEvents(old, todo Todo, now time.Time) []Event {
+	var events []Event
+	if changes := fieldChanges(old, todo); len(changes) > 0 {
+		events = append(events, Event{TodoID: todo.ID, Kind: EventEdited, At: now, Title: todo.Title, Changes: changes})
+	}
+	if old.Completed != todo.Completed {
+		kind := EventReopened
+		if todo.Completed {
+			kind = EventCompleted
+		}
+		events = append(events, Event{TodoID: todo.ID, Kind: kind, At: now, Title: todo.Title})
+	}
+	if old.Trashed() != todo.Trashed() {
+		kind := EventRestored
+		if todo.Trashed() {
+			kind = EventDeleted
+		}
+		events = append(events, Event{TodoID: todo.ID, Kind: kind, At: now, Title: todo.Title})
+	}
+	return events
+}
+
+// fieldChanges compares the user-editable fields of two versions of a todo.
+func fieldChanges(old, todo Todo) []FieldChange {
+	fields := []struct {
+		name  string
+		value func(Todo) string
+	}{
+		{"title", func(t Todo) string { return t.Title }},
+		{"description", func(t Todo) string { return t.Description }},
+		{"priority", func(t Todo) string { return string(t.Priority) }},
+		{"due", formatEventDue},
+		{"reminder", formatEventRemind},
+		{"tags", func(t Todo) string { return strings.Join(t.Tags, ", ") }},
+		{"repeat", func(t Todo) string { return t.Recurrence }},
+		{"list", func(t Todo) string { return t.ProjectID }},
+		{"subtasks", formatEventSubtasks},
+	}
+
+	var changes []FieldChange
+	for _, field := range fields {
+		before, after := field.value(old), field.value(todo)
+		if before != after {
+			changes = append(changes, FieldChange{Field: field.name, Before: before, After: after})
+		}
+	}
+	return changes
+}
+
+func formatEventDue(t Todo) string {
+	due, ok := t.DueLocal()
+	if !ok {
+		return ""
+	}
+	return due.Format("2006-01-02 15:04 MST")
+}
+
+func formatEventRemind(t Todo) string {
+	if t.RemindMinutes == nil {
+		return ""
+	}
+	return DescribeRemind(*t.RemindMinutes)
+}
+
+// formatEventSubtasks lists subtasks as "[x] done; [ ] open".
+func formatEventSubtasks(t Todo) string {
+	items := make([]string, len(t.Subtasks))
+	for i, subtask := range t.Subtasks {
+		mark := "[ ] "
+		if subtask.Done {
+			mark = "[x] "
+		}
+		items[i] = mark + subtask.Title
+	}
+	return strings.Join(items, "; ")
+}
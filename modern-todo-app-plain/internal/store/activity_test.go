@@ -0,0 +1,195 @@
+package store
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRecentActivityLimit(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int
+		want  int
+	}{
+		{name: "negative", limit: -1, want: 0},
+		{name: "zero", limit: 0, want: 0},
+		{name: "fewer than logged", limit: 2, want: 2},
+		{name: "more than logged", limit: 10, want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := openEmpty(t)
+			todo, _, err := s.Add(Draft{Title: "Call the plumber", Priority: PriorityMedium})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Logs created, completed and reopened.
+			for range 2 {
+				if _, _, err := s.Toggle(todo.ID); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got := s.RecentActivity(tt.limit); len(got) != tt.want {
+				t.Errorf("RecentActivity(%d) returned %d events, want %d", tt.limit, len(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestActivity(t *testing.T) {
+	tests := []struct {
+		name string
+		// steps changes the todo after it is added as "Plan".
+		steps func(t *testing.T, s *Store, id string)
+		// want lists the todo's events newest first, as kind or
+		// kind:changed fields.
+		want string
+	}{
+		{
+			name:  "added",
+			steps: func(t *testing.T, s *Store, id string) {},
+			want:  "created",
+		},
+		{
+			name: "edited, completed and reopened",
+			steps: func(t *testing.T, s *Store, id string) {
+				if _, _, err := s.Update(id, Draft{Title: "Plan the trip", Priority: PriorityHigh}); err != nil {
+					t.Fatal(err)
+				}
+				for range 2 {
+					if _, _, err := s.Toggle(id); err != nil {
+						t.Fatal(err)
+					}
+				}
+			},
+			want: "reopened completed edited:title,priority created",
+		},
+		{
+			name: "deleted and restored",
+			steps: func(t *testing.T, s *Store, id string) {
+				if _, err := s.Delete(id); err != nil {
+					t.Fatal(err)
+				}
+				if _, _, err := s.Restore(id); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: "restored deleted created",
+		},
+		{
+			name: "undone edit",
+			steps: func(t *testing.T, s *Store, id string) {
+				if _, _, err := s.Update(id, Draft{Title: "Plan the trip", Priority: PriorityMedium}); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := s.Undo(); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: "edited:title edited:title created",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := openEmpty(t)
+			todo, _, err := s.Add(Draft{Title: "Plan", Priority: PriorityMedium})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Another todo's events are logged in between but not listed.
+			if _, _, err := s.Add(Draft{Title: "Other", Priority: PriorityMedium}); err != nil {
+				t.Fatal(err)
+			}
+			tt.steps(t, s, todo.ID)
+
+			events, err := s.Activity(todo.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]string, len(events))
+			for i, event := range events {
+				got[i] = string(event.Kind)
+				if len(event.Changes) > 0 {
+					fields := make([]string, len(event.Changes))
+					for j, change := range event.Changes {
+						fields[j] = change.Field
+					}
+					got[i] += ":" + strings.Join(fields, ",")
+				}
+			}
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("events = %s, want %s", strings.Join(got, " "), tt.want)
+			}
+		})
+	}
+}
+
+func TestRecentActivityOrder(t *testing.T) {
+	s := openEmpty(t)
+	first, _, err := s.Add(Draft{Title: "First", Priority: PriorityMedium})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.Add(Draft{Title: "Second", Priority: PriorityMedium}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.Toggle(first.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, event := range s.RecentActivity(10) {
+		got = append(got, event.Title+" "+string(event.Kind))
+	}
+	if want := "First completed, Second created, First created"; strings.Join(got, ", ") != want {
+		t.Errorf("recent activity = %s, want %s", strings.Join(got, ", "), want)
+	}
+}
+
+func TestActivityTrim(t *testing.T) {
+	backend := NewMemoryBackend()
+	if err := backend.Save(Snapshot{NextID: 1, NextProjectID: 1, Projects: []Project{inboxProject()}}); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Open(backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	todo, _, err := s.Add(Draft{Title: "Flip", Priority: PriorityMedium})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// One created event plus maxEvents toggles, the first a completion and
+	// the last a reopening.
+	for range maxEvents {
+		if _, _, err := s.Toggle(todo.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	events := s.RecentActivity(maxEvents + 10)
+	if len(events) != maxEvents {
+		t.Fatalf("log has %d events, want %d", len(events), maxEvents)
+	}
+	if events[0].Kind != EventReopened || events[len(events)-1].Kind != EventCompleted {
+		t.Errorf("newest %s, oldest %s; want the created event dropped", events[0].Kind, events[len(events)-1].Kind)
+	}
+	saved, _, _ := backend.Load()
+	if len(saved.Events) != maxEvents {
+		t.Errorf("saved %d events, want %d", len(saved.Events), maxEvents)
+	}
+
+	// A store loaded from the backend keeps the trimmed log and trims it
+	// further.
+	loaded, err := Open(backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := loaded.Toggle(todo.ID); err != nil {
+		t.Fatal(err)
+	}
+	if events := loaded.RecentActivity(maxEvents + 10); len(events) != maxEvents || events[0].Kind != EventCompleted {
+		t.Errorf("after loading: %d events, newest %s; want %d, newest completed", len(events), events[0].Kind, maxEvents)
+	}
+}
@@ -0,0 +1,153 @@
+package store
+
+import (
+	"sort"
+	"time"
+)
+
+// The periods covered by Analytics.
+const (
+	AnalyticsDays  = 14
+	AnalyticsWeeks = 8
+)
+
+// Analytics summarises completed work over time. Only todos with a recorded
+// completion time count towards completions, averages and streaks; trashed
+// todos still count, since clearing finished work should not erase it.
+type Analytics struct {
+	// Days covers the last AnalyticsDays days up to today, oldest first.
+	Days []DayStats
+	// Weeks covers the last AnalyticsWeeks weeks, starting on Mondays, oldest
+	// first.
+	Weeks []WeekStats
+	// AverageCompletion is the mean time from creation to completion, over
+	// Timed todos.
+	AverageCompletion time.Duration
+	Timed             int
+	// CurrentStreak counts consecutive days with a completion, ending today
+	// or, when nothing has been completed yet today, yesterday.
+	CurrentStreak int
+	LongestStreak int
+}
+
+// DayStats is one day of activity.
+type DayStats struct {
+	Date      time.Time
+	Completed int
+	// Open counts the todos still open at the end of the day, by priority;
+	// for today, the todos open now.
+	Open map[Priority]int
+}
+
+// WeekStats is the number of todos completed in the week starting Start.
+type WeekStats struct {
+	Start     time.Time
+	Completed int
+}
+
+// Analytics computes the stats page figures, bucketing days in loc, the
+// viewer's time zone.
+func (s *Store) Analytics(now time.Time, loc *time.Location) Analytics {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now = now.In(loc)
+	today := startOfDay(now)
+	first := today.AddDate(0, 0, 1-AnalyticsDays)
+
+	days := make([]DayStats, AnalyticsDays)
+	for i := range days {
+		days[i] = DayStats{Date: first.AddDate(0, 0, i), Open: map[Priority]int{}}
+	}
+	weekStart := today.AddDate(0, 0, -mondayIndex(today.Weekday()))
+	firstWeek := weekStart.AddDate(0, 0, -7*(AnalyticsWeeks-1))
+	weeks := make([]WeekStats, AnalyticsWeeks)
+	for i := range weeks {
+		weeks[i] = WeekStats{Start: firstWeek.AddDate(0, 0, 7*i)}
+	}
+
+	var (
+		active = map[string]bool{}
+		total  time.Duration
+		result Analytics
+	)
+	for _, todo := range s.todos {
+		if todo.CompletedAt != nil {
+			done := todo.CompletedAt.In(loc)
+			active[done.Format("2006-01-02")] = true
+			if elapsed := done.Sub(todo.CreatedAt); elapsed >= 0 {
+				total += elapsed
+				result.Timed++
+			}
+			if i := daysBetween(first, startOfDay(done)); i >= 0 && i < AnalyticsDays {
+				days[i].Completed++
+			}
+			if i := daysBetween(firstWeek, startOfDay(done)) / 7; !done.Before(firstWeek) && i < AnalyticsWeeks {
+				weeks[i].Completed++
+			}
+		}
+
+		for i := range days {
+			end := days[i].Date.AddDate(0, 0, 1)
+			if i == len(days)-1 {
+				end = now
+			}
+			if todo.openAt(end) {
+				days[i].Open[todo.Priority]++
+			}
+		}
+	}
+
+	result.Days = days
+	result.Weeks = weeks
+	if result.Timed > 0 {
+		result.AverageCompletion = total / time.Duration(result.Timed)
+	}
+	result.CurrentStreak, result.LongestStreak = streaks(active, today)
+	return result
+}
+
+// openAt reports whether the todo existed, open and out of the trash, at t.
+// Todos completed without a recorded time are treated as long finished.
+func (t *Todo) openAt(at time.Time) bool {
+	switch {
+	case t.CreatedAt.After(at):
+		return false
+	case t.DeletedAt != nil && !t.DeletedAt.After(at):
+		return false
+	case t.CompletedAt != nil:
+		return t.CompletedAt.After(at)
+	}
+	return !t.Completed
+}
+
+// streaks measures runs of consecutive days in active, keyed as 2006-01-02.
+func streaks(active map[string]bool, today time.Time) (current, longest int) {
+	day := today
+	if !active[day.Format("2006-01-02")] {
+		day = day.AddDate(0, 0, -1)
+	}
+	for active[day.Format("2006-01-02")] {
+		current++
+		day = day.AddDate(0, 0, -1)
+	}
+
+	keys := make([]string, 0, len(active))
+	for key := range active {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	run := 0
+	var last time.Time
+	for _, key := range keys {
+		day, _ := time.Parse("2006-01-02", key)
+		if run > 0 && daysBetween(last, day) == 1 {
+			run++
+		} else {
+			run = 1
+		}
+		last = day
+		longest = max(longest, run)
+	}
+	return current, longest
+}
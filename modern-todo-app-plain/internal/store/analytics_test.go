@@ -0,0 +1,149 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// importDone adds completed todos with the given completion times. Titles
+// differ so the import does not skip any as duplicates.
+func importDone(t *testing.T, s *Store, done ...time.Time) {
+	t.Helper()
+	todos := make([]Todo, len(done))
+	for i, at := range done {
+		todos[i] = Todo{Title: fmt.Sprintf("Done %d", i), Completed: true, CreatedAt: at.Add(-time.Hour), CompletedAt: timePtr(at)}
+	}
+	if _, err := s.Import(todos, "", false); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAnalyticsZone(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip("no time zone data:", err)
+	}
+	now := time.Date(2024, time.March, 11, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		loc  *time.Location
+		// wantDay is the index in Days that the completion falls on.
+		wantDay int
+	}{
+		// 23:30 UTC on the 10th is yesterday in UTC...
+		{name: "UTC", loc: time.UTC, wantDay: AnalyticsDays - 2},
+		// ...but 08:30 on the 11th, today, in Tokyo.
+		{name: "Tokyo", loc: tokyo, wantDay: AnalyticsDays - 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := openEmpty(t)
+			importDone(t, s, time.Date(2024, time.March, 10, 23, 30, 0, 0, time.UTC))
+
+			stats := s.Analytics(now, tt.loc)
+			if got := stats.Days[0].Date.Location(); got != tt.loc {
+				t.Errorf("days are in %s, want %s", got, tt.loc)
+			}
+			for i, day := range stats.Days {
+				want := 0
+				if i == tt.wantDay {
+					want = 1
+				}
+				if day.Completed != want {
+					t.Errorf("day %d (%s): completed %d, want %d", i, day.Date.Format("Jan 2"), day.Completed, want)
+				}
+			}
+			if stats.CurrentStreak != 1 {
+				t.Errorf("current streak = %d, want 1", stats.CurrentStreak)
+			}
+		})
+	}
+}
+
+// analyticsNow is a Wednesday; its week started on Monday the 11th.
+var analyticsNow = time.Date(2024, time.March, 13, 12, 0, 0, 0, time.UTC)
+
+// daysAgo returns noon n days before analyticsNow.
+func daysAgo(n int) time.Time {
+	return analyticsNow.AddDate(0, 0, -n)
+}
+
+func TestAnalyticsStreaks(t *testing.T) {
+	tests := []struct {
+		name        string
+		done        []time.Time
+		wantCurrent int
+		wantLongest int
+	}{
+		{name: "nothing done", wantCurrent: 0, wantLongest: 0},
+		{name: "today", done: []time.Time{daysAgo(0)}, wantCurrent: 1, wantLongest: 1},
+		{name: "twice today", done: []time.Time{daysAgo(0), daysAgo(0).Add(-time.Hour)}, wantCurrent: 1, wantLongest: 1},
+		{name: "up to yesterday", done: []time.Time{daysAgo(1), daysAgo(2)}, wantCurrent: 2, wantLongest: 2},
+		{name: "broken two days ago", done: []time.Time{daysAgo(2), daysAgo(3)}, wantCurrent: 0, wantLongest: 2},
+		{name: "longer run earlier", done: []time.Time{daysAgo(0), daysAgo(2), daysAgo(3), daysAgo(4)}, wantCurrent: 1, wantLongest: 3},
+		{name: "across a month end", done: []time.Time{daysAgo(12), daysAgo(13), daysAgo(14)}, wantCurrent: 0, wantLongest: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := openEmpty(t)
+			if len(tt.done) > 0 {
+				importDone(t, s, tt.done...)
+			}
+
+			stats := s.Analytics(analyticsNow, time.UTC)
+			if stats.CurrentStreak != tt.wantCurrent || stats.LongestStreak != tt.wantLongest {
+				t.Errorf("streaks = %d current, %d longest; want %d, %d",
+					stats.CurrentStreak, stats.LongestStreak, tt.wantCurrent, tt.wantLongest)
+			}
+		})
+	}
+}
+
+func TestAnalyticsBuckets(t *testing.T) {
+	tests := []struct {
+		name string
+		done time.Time
+		// wantDay and wantWeek are the Days and Weeks indexes the completion
+		// counts in; -1 means none.
+		wantDay  int
+		wantWeek int
+	}{
+		{name: "today", done: daysAgo(0), wantDay: AnalyticsDays - 1, wantWeek: AnalyticsWeeks - 1},
+		{name: "this Monday", done: daysAgo(2), wantDay: AnalyticsDays - 3, wantWeek: AnalyticsWeeks - 1},
+		{name: "last Sunday", done: daysAgo(3), wantDay: AnalyticsDays - 4, wantWeek: AnalyticsWeeks - 2},
+		{name: "first day shown", done: daysAgo(AnalyticsDays - 1), wantDay: 0, wantWeek: AnalyticsWeeks - 3},
+		{name: "before the days shown", done: daysAgo(AnalyticsDays), wantDay: -1, wantWeek: AnalyticsWeeks - 3},
+		// The first week shown started on Monday, January 22.
+		{name: "first week shown", done: time.Date(2024, time.January, 22, 0, 0, 0, 0, time.UTC), wantDay: -1, wantWeek: 0},
+		{name: "before the weeks shown", done: time.Date(2024, time.January, 21, 23, 59, 0, 0, time.UTC), wantDay: -1, wantWeek: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := openEmpty(t)
+			importDone(t, s, tt.done)
+
+			stats := s.Analytics(analyticsNow, time.UTC)
+			for i, day := range stats.Days {
+				if want := boolCount(i == tt.wantDay); day.Completed != want {
+					t.Errorf("day %d (%s): completed %d, want %d", i, day.Date.Format("Jan 2"), day.Completed, want)
+				}
+			}
+			for i, week := range stats.Weeks {
+				if want := boolCount(i == tt.wantWeek); week.Completed != want {
+					t.Errorf("week %d (from %s): completed %d, want %d", i, week.Start.Format("Jan 2"), week.Completed, want)
+				}
+			}
+			if stats.Timed != 1 || stats.AverageCompletion != time.Hour {
+				t.Errorf("average = %v over %d todos, want 1h over 1", stats.AverageCompletion, stats.Timed)
+			}
+		})
+	}
+}
+
+func boolCount(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,50 @@
+package store
+
+import "sync"
+
+// Snapshot is the complete persisted state of a Store.
+type Snapshot struct {
+	NextID        uint64    `json:"next_id"`
+	Todos         []Todo    `json:"todos"`
+	NextProjectID uint64    `json:"next_project_id"`
+	Projects      []Project `json:"projects"`
+	// FeedToken is the secret in the calendar feed URL.
+	FeedToken string `json:"feed_token,omitempty"`
+	// Events is the activity log, oldest first.
+	Events []Event `json:"events,omitempty"`
+}
+
+// Backend persists store snapshots. Save must be atomic: after a crash, Load
+// returns either the previous snapshot or the new one, never a mix.
+type Backend interface {
+	// Load returns the last saved snapshot. found is false when nothing has
+	// been saved yet.
+	Load() (snapshot Snapshot, found bool, err error)
+	Save(snapshot Snapshot) error
+}
+
+// MemoryBackend keeps the latest snapshot in memory. It is the default for
+// demos and tests.
+type MemoryBackend struct {
+	mu       sync.Mutex
+	snapshot Snapshot
+	found    bool
+}
+
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{}
+}
+
+func (b *MemoryBackend) Load() (Snapshot, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.snapshot, b.found, nil
+}
+
+func (b *MemoryBackend) Save(snapshot Snapshot) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.snapshot = snapshot
+	b.found = true
+	return nil
+}
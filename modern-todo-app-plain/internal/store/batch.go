@@ -0,0 +1,151 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// BatchAction is an operation applied to many todos at once.
+type BatchAction string
+
+const (
+	BatchComplete BatchAction = "complete"
+	BatchReopen   BatchAction = "reopen"
+	BatchDelete   BatchAction = "delete"
+	BatchPriority BatchAction = "priority"
+	BatchMove     BatchAction = "move"
+	BatchTag      BatchAction = "tag"
+)
+
+// ErrInvalidBatch is returned for an unknown batch action or one missing its
+// argument.
+var ErrInvalidBatch = errors.New("invalid batch action")
+
+// Batch describes a batch operation. Priority, ProjectID and Tag are the
+// arguments of the priority, move and tag actions.
+type Batch struct {
+	Action    BatchAction
+	Priority  Priority
+	ProjectID string
+	Tag       string
+}
+
+// batchVerbs label batch operations in the command log.
+var batchVerbs = map[BatchAction]string{
+	BatchComplete: "Completed",
+	BatchReopen:   "Reopened",
+	BatchDelete:   "Deleted",
+	BatchPriority: "Reprioritised",
+	BatchMove:     "Moved",
+	BatchTag:      "Tagged",
+}
+
+// Apply performs the batch on every listed todo under a single lock and a
+// single save, so either all of them change or none do. Trashed todos are
+// skipped. It returns how many todos changed; the whole batch is one step in
+// the undo history.
+func (s *Store) Apply(ids []string, batch Batch) (int, Commit, error) {
+	verb, ok := batchVerbs[batch.Action]
+	if !ok {
+		return 0, Commit{}, ErrInvalidBatch
+	}
+	switch batch.Action {
+	case BatchPriority:
+		if !batch.Priority.Valid() {
+			return 0, Commit{}, ErrInvalidBatch
+		}
+	case BatchTag:
+		batch.Tag = NormalizeTag(batch.Tag)
+		if batch.Tag == "" {
+			return 0, Commit{}, ErrInvalidBatch
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if batch.Action == BatchMove {
+		if err := s.resolveProjectLocked(&batch.ProjectID); err != nil {
+			return 0, Commit{}, err
+		}
+	}
+
+	todos := make([]*Todo, 0, len(ids))
+	for _, id := range ids {
+		todo, err := s.findLocked(id)
+		if err != nil {
+			return 0, Commit{}, err
+		}
+		if !todo.Trashed() {
+			todos = append(todos, todo)
+		}
+	}
+	return s.applyLocked(todos, batch, func(n int) string { return batchLabel(verb, "", n) })
+}
+
+// ClearCompleted moves every completed todo in a list to the trash, or in
+// every list when projectID is empty, and returns how many were moved.
+func (s *Store) ClearCompleted(projectID string) (int, Commit, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var todos []*Todo
+	for _, todo := range s.todos {
+		if todo.Completed && !todo.Trashed() && (projectID == "" || todo.ProjectID == projectID) {
+			todos = append(todos, todo)
+		}
+	}
+	return s.applyLocked(todos, Batch{Action: BatchDelete}, func(n int) string { return batchLabel("Cleared", "completed ", n) })
+}
+
+// applyLocked applies batch to todos in one commit and records it under the
+// label for the number of todos that changed.
+func (s *Store) applyLocked(todos []*Todo, batch Batch, label func(int) string) (int, Commit, error) {
+	if len(todos) == 0 {
+		return 0, Commit{}, nil
+	}
+
+	prev := s.snapshotLocked()
+	now := time.Now()
+	for _, todo := range todos {
+		switch batch.Action {
+		case BatchComplete:
+			if !todo.Completed {
+				todo.Completed = true
+				s.spawnNextLocked(todo, now)
+			}
+		case BatchReopen:
+			todo.Completed = false
+		case BatchDelete:
+			todo.DeletedAt = timePtr(s.now().UTC())
+		case BatchPriority:
+			todo.Priority = batch.Priority
+		case BatchMove:
+			todo.ProjectID = batch.ProjectID
+		case BatchTag:
+			todo.Tags = NormalizeTags(append(todo.Tags, batch.Tag))
+		}
+	}
+	commit, err := s.commitLocked(prev)
+	if err != nil {
+		return 0, Commit{}, err
+	}
+
+	changed := 0
+	for _, change := range s.diffLocked(prev) {
+		if change.before != nil {
+			changed++
+		}
+	}
+	commit.Undo = s.recordLocked(label(changed), prev)
+	return changed, commit, nil
+}
+
+// batchLabel reads like "Completed 3 tasks"; kind qualifies the noun.
+func batchLabel(verb, kind string, n int) string {
+	if n == 1 {
+		return fmt.Sprintf("%s 1 %stask", verb, kind)
+	}
+	return fmt.Sprintf("%s %d %stasks", verb, n, kind)
+}
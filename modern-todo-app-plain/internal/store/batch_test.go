@@ -0,0 +1,132 @@
+package store
+
+import (
+	"errors"
+	"testing"
+)
+
+// countingBackend counts the saves that reach the backend.
+type countingBackend struct {
+	*MemoryBackend
+	saves int
+}
+
+func (b *countingBackend) Save(snapshot Snapshot) error {
+	b.saves++
+	return b.MemoryBackend.Save(snapshot)
+}
+
+func TestBatchIsOneStep(t *testing.T) {
+	tests := []struct {
+		name      string
+		run       func(s *Store, ids []string) (int, Commit, error)
+		wantErr   error
+		wantN     int
+		wantSaves int
+		// wantLabel is the undo step the batch adds; empty when it adds none.
+		wantLabel string
+	}{
+		{
+			name: "complete",
+			run: func(s *Store, ids []string) (int, Commit, error) {
+				return s.Apply(ids, Batch{Action: BatchComplete})
+			},
+			wantN:     1,
+			wantSaves: 1,
+			wantLabel: "Completed 1 task",
+		},
+		{
+			name: "unknown id",
+			run: func(s *Store, ids []string) (int, Commit, error) {
+				return s.Apply(append(ids, "missing"), Batch{Action: BatchPriority, Priority: PriorityHigh})
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name: "unknown id first",
+			run: func(s *Store, ids []string) (int, Commit, error) {
+				return s.Apply(append([]string{"missing"}, ids...), Batch{Action: BatchDelete})
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name: "clear completed",
+			run: func(s *Store, ids []string) (int, Commit, error) {
+				return s.ClearCompleted("")
+			},
+			wantN:     1,
+			wantSaves: 1,
+			wantLabel: "Cleared 1 completed task",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &countingBackend{MemoryBackend: NewMemoryBackend()}
+			if err := backend.Save(Snapshot{NextID: 1, NextProjectID: 1, Projects: []Project{inboxProject()}}); err != nil {
+				t.Fatal(err)
+			}
+			s, err := Open(backend)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, draft := range []Draft{
+				{Title: "Open", Priority: PriorityLow},
+				{Title: "Done", Priority: PriorityLow, Completed: true},
+			} {
+				todo, _, err := s.Add(draft)
+				if err != nil {
+					t.Fatal(err)
+				}
+				ids = append(ids, todo.ID)
+			}
+			before := s.List(Query{})
+			undoBefore, _ := s.History()
+			backend.saves = 0
+
+			n, _, err := tt.run(s, ids)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if n != tt.wantN {
+				t.Errorf("changed %d todos, want %d", n, tt.wantN)
+			}
+			if backend.saves != tt.wantSaves {
+				t.Errorf("saved %d times, want %d", backend.saves, tt.wantSaves)
+			}
+
+			undo, _ := s.History()
+			if tt.wantLabel == "" {
+				if undo != undoBefore {
+					t.Errorf("undo label = %q, want %q left as it was", undo, undoBefore)
+				}
+				assertSameTodos(t, s.List(Query{}), before)
+				return
+			}
+			if undo != tt.wantLabel {
+				t.Errorf("undo label = %q, want %q", undo, tt.wantLabel)
+			}
+
+			// A single undo puts every todo back.
+			if _, err := s.Undo(); err != nil {
+				t.Fatal(err)
+			}
+			assertSameTodos(t, s.List(Query{}), before)
+			if undo, _ := s.History(); undo != undoBefore {
+				t.Errorf("undo label after undo = %q, want %q", undo, undoBefore)
+			}
+		})
+	}
+}
+
+func assertSameTodos(t *testing.T, got, want []Todo) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d todos, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !sameContent(got[i], want[i]) {
+			t.Errorf("todo %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
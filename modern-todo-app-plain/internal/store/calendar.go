@@ -0,0 +1,456 @@
+package store
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	icsTimeLayout = "20060102T150405Z"
+	icsDateLayout = "20060102"
+	// icsLineLimit is the longest content line, in octets, before folding.
+	icsLineLimit = 75
+)
+
+// FeedToken returns the secret that authorises the calendar feed URL.
+func (s *Store) FeedToken() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.feedToken
+}
+
+// ValidFeedToken reports whether token matches the feed token, in constant
+// time.
+func (s *Store) ValidFeedToken(token string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.feedToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.feedToken)) == 1
+}
+
+// RotateFeedToken replaces the feed token, revoking every existing
+// subscription.
+func (s *Store) RotateFeedToken() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev := s.snapshotLocked()
+	s.feedToken = newFeedToken()
+	if _, err := s.commitLocked(prev); err != nil {
+		return "", err
+	}
+	return s.feedToken, nil
+}
+
+func newFeedToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("feed token: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// icsPriority maps priorities onto the iCalendar 1 (highest) to 9 (lowest)
+// scale.
+func icsPriority(p Priority) int {
+	switch p {
+	case PriorityHigh:
+		return 1
+	case PriorityLow:
+		return 9
+	default:
+		return 5
+	}
+}
+
+// priorityFromICS is the inverse of icsPriority. 0 means undefined.
+func priorityFromICS(value int) Priority {
+	switch {
+	case value >= 1 && value <= 4:
+		return PriorityHigh
+	case value >= 6 && value <= 9:
+		return PriorityLow
+	default:
+		return PriorityMedium
+	}
+}
+
+// exportICS writes todos as an iCalendar document with one VTODO each.
+func exportICS(w io.Writer, todos []Todo, now time.Time) error {
+	var b strings.Builder
+	line := func(name, value string) {
+		writeICSLine(&b, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//Modern Todo//Plain//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("X-WR-CALNAME", "Modern Todo")
+	for _, todo := range todos {
+		line("BEGIN", "VTODO")
+		line("UID", "todo-"+todo.ID+"@modern-todo")
+		line("DTSTAMP", now.UTC().Format(icsTimeLayout))
+		line("CREATED", todo.CreatedAt.UTC().Format(icsTimeLayout))
+		line("SUMMARY", escapeICS(todo.Title))
+		if todo.Description != "" {
+			line("DESCRIPTION", escapeICS(todo.Description))
+		}
+		if due, ok := todo.DueLocal(); ok {
+			if todo.Recurrence != "" {
+				// RRULE repeats from DTSTART, which must come before DUE.
+				line("DTSTART", icsStart(due).UTC().Format(icsTimeLayout))
+				line("RRULE", todo.Recurrence)
+			}
+			line("DUE", due.UTC().Format(icsTimeLayout))
+		}
+		line("PRIORITY", strconv.Itoa(icsPriority(todo.Priority)))
+		if todo.Completed {
+			line("STATUS", "COMPLETED")
+			line("PERCENT-COMPLETE", "100")
+		} else {
+			line("STATUS", "NEEDS-ACTION")
+		}
+		if len(todo.Tags) > 0 {
+			tags := make([]string, len(todo.Tags))
+			for i, tag := range todo.Tags {
+				tags[i] = escapeICS(tag)
+			}
+			line("CATEGORIES", strings.Join(tags, ","))
+		}
+		if _, ok := todo.RemindAt(); ok {
+			// The alarm counts back from DUE, like the reminder.
+			line("BEGIN", "VALARM")
+			line("ACTION", "DISPLAY")
+			line("DESCRIPTION", escapeICS(todo.Title))
+			line("TRIGGER;RELATED=END", formatICSDuration(-time.Duration(*todo.RemindMinutes)*time.Minute))
+			line("END", "VALARM")
+		}
+		line("END", "VTODO")
+	}
+	line("END", "VCALENDAR")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// icsStart is the DTSTART of a repeating todo due at due: the start of its
+// day, or of the day before when it is due at midnight.
+func icsStart(due time.Time) time.Time {
+	start := startOfDay(due)
+	if !start.Before(due) {
+		start = startOfDay(due.AddDate(0, 0, -1))
+	}
+	return start
+}
+
+// formatICSDuration writes d as an RFC 5545 duration in whole minutes, such
+// as -PT90M.
+func formatICSDuration(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	if d < time.Minute {
+		return "PT0S"
+	}
+	return fmt.Sprintf("%sPT%dM", sign, int(d/time.Minute))
+}
+
+// parseICSDuration reads an RFC 5545 duration such as -P1DT2H or PT15M.
+func parseICSDuration(value string) (time.Duration, error) {
+	sign := time.Duration(1)
+	rest := value
+	switch {
+	case strings.HasPrefix(rest, "-"):
+		sign, rest = -1, rest[1:]
+	case strings.HasPrefix(rest, "+"):
+		rest = rest[1:]
+	}
+	if !strings.HasPrefix(rest, "P") || len(rest) == 1 {
+		return 0, fmt.Errorf("%q is not a duration", value)
+	}
+	rest = rest[1:]
+
+	units := map[byte]time.Duration{'W': 7 * 24 * time.Hour, 'D': 24 * time.Hour}
+	var total time.Duration
+	for rest != "" {
+		if rest[0] == 'T' {
+			units = map[byte]time.Duration{'H': time.Hour, 'M': time.Minute, 'S': time.Second}
+			rest = rest[1:]
+			continue
+		}
+		i := 0
+		for i < len(rest) && rest[i] >= '0' && rest[i] <= '9' {
+			i++
+		}
+		unit, ok := time.Duration(0), false
+		if i > 0 && i < len(rest) {
+			unit, ok = units[rest[i]]
+		}
+		if !ok {
+			return 0, fmt.Errorf("%q is not a duration", value)
+		}
+		n, err := strconv.Atoi(rest[:i])
+		if err != nil {
+			return 0, err
+		}
+		total += time.Duration(n) * unit
+		rest = rest[i+1:]
+	}
+	return sign * total, nil
+}
+
+// writeICSLine writes a CRLF-terminated content line, folding it at
+// icsLineLimit octets without splitting a UTF-8 sequence.
+func writeICSLine(b *strings.Builder, line string) {
+	limit := icsLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts toward the limit.
+		limit = icsLineLimit - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func isRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escapeICS(text string) string {
+	return icsEscaper.Replace(text)
+}
+
+func unescapeICS(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] != '\\' || i+1 == len(text) {
+			b.WriteByte(text[i])
+			continue
+		}
+		i++
+		switch text[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(text[i])
+		}
+	}
+	return b.String()
+}
+
+// splitICSList splits an escaped comma-separated value such as CATEGORIES.
+func splitICSList(value string) []string {
+	var (
+		items   []string
+		current strings.Builder
+	)
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && i+1 < len(value):
+			current.WriteByte(value[i])
+			current.WriteByte(value[i+1])
+			i++
+		case value[i] == ',':
+			items = append(items, unescapeICS(current.String()))
+			current.Reset()
+		default:
+			current.WriteByte(value[i])
+		}
+	}
+	return append(items, unescapeICS(current.String()))
+}
+
+// icsProperty is one unfolded content line.
+type icsProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// decodeICS reads the VTODO components of an iCalendar file. Other
+// components, such as events, are skipped, as are repeat rules outside the
+// subset the store supports.
+func decodeICS(r io.Reader, loc *time.Location) ([]Todo, error) {
+	lines, err := unfoldICS(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		todos   []Todo
+		current *Todo
+		depth   int
+		// alarm is set inside a VALARM directly under the VTODO.
+		alarm bool
+	)
+	for n, raw := range lines {
+		prop, ok := parseICSLine(raw)
+		if !ok {
+			continue
+		}
+		switch {
+		case prop.name == "BEGIN" && prop.value == "VTODO" && current == nil:
+			current = &Todo{Priority: PriorityMedium}
+			continue
+		case current == nil:
+			continue
+		case prop.name == "BEGIN":
+			// Nested components such as VALARM.
+			depth++
+			alarm = depth == 1 && prop.value == "VALARM"
+			continue
+		case prop.name == "END" && depth > 0:
+			depth--
+			alarm = false
+			continue
+		case alarm && prop.name == "TRIGGER" && prop.params["RELATED"] == "END":
+			// A display alarm before the due date reads as the reminder.
+			if offset, err := parseICSDuration(prop.value); err == nil && offset <= 0 {
+				minutes := int(-offset / time.Minute)
+				current.RemindMinutes = &minutes
+			}
+			continue
+		case prop.name == "END" && prop.value == "VTODO":
+			todos = append(todos, *current)
+			current = nil
+			continue
+		case depth > 0:
+			continue
+		}
+
+		if err := applyICSProperty(current, prop, loc); err != nil {
+			return nil, fmt.Errorf("line %d: %s: %v", n+1, strings.ToLower(prop.name), err)
+		}
+	}
+	if current != nil {
+		return nil, fmt.Errorf("VTODO %q is missing END:VTODO", current.Title)
+	}
+	return todos, nil
+}
+
+func applyICSProperty(todo *Todo, prop icsProperty, loc *time.Location) error {
+	switch prop.name {
+	case "SUMMARY":
+		todo.Title = unescapeICS(prop.value)
+	case "DESCRIPTION":
+		todo.Description = unescapeICS(prop.value)
+	case "STATUS":
+		todo.Completed = strings.EqualFold(prop.value, "COMPLETED")
+	case "PRIORITY":
+		value, err := strconv.Atoi(prop.value)
+		if err != nil {
+			return err
+		}
+		todo.Priority = priorityFromICS(value)
+	case "CATEGORIES":
+		todo.Tags = append(todo.Tags, splitICSList(prop.value)...)
+	case "RRULE":
+		if rule, err := normalizeRecurrence(prop.value); err == nil {
+			todo.Recurrence = rule
+		}
+	case "CREATED":
+		created, _, err := parseICSTime(prop, loc)
+		if err != nil {
+			return err
+		}
+		todo.CreatedAt = created
+	case "DUE":
+		due, zone, err := parseICSTime(prop, loc)
+		if err != nil {
+			return err
+		}
+		todo.DueAt, todo.DueZone = &due, zone
+	}
+	return nil
+}
+
+// parseICSTime reads a DATE-TIME in UTC, local time with a TZID parameter or
+// floating time (taken in loc), or a DATE, which means the end of that day in
+// loc. It returns the time zone name to store with due dates, which is empty
+// for UTC times: those fix the instant but not the zone to show it in.
+func parseICSTime(prop icsProperty, loc *time.Location) (time.Time, string, error) {
+	value := prop.value
+	if prop.params["VALUE"] == "DATE" || len(value) == len(icsDateLayout) {
+		day, err := time.ParseInLocation(icsDateLayout, value, loc)
+		if err != nil {
+			return time.Time{}, "", err
+		}
+		return endOfDay(day), loc.String(), nil
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(icsTimeLayout, value)
+		return t, "", err
+	}
+
+	if tzid := prop.params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation(strings.TrimSuffix(icsTimeLayout, "Z"), value, loc)
+	return t, loc.String(), err
+}
+
+// unfoldICS joins folded continuation lines.
+func unfoldICS(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// parseICSLine splits "NAME;PARAM=VALUE:value". Quoted parameter values may
+// contain colons.
+func parseICSLine(line string) (icsProperty, bool) {
+	colon := -1
+	quoted := false
+	for i := 0; i < len(line); i++ {
+		if line[i] == '"' {
+			quoted = !quoted
+		}
+		if line[i] == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon <= 0 {
+		return icsProperty{}, false
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	prop := icsProperty{
+		name:   strings.ToUpper(parts[0]),
+		params: map[string]string{},
+		value:  line[colon+1:],
+	}
+	for _, param := range parts[1:] {
+		if key, value, ok := strings.Cut(param, "="); ok {
+			prop.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+		}
+	}
+	return prop, true
+}
@@ -0,0 +1,64 @@
+package store
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportICSRecurring(t *testing.T) {
+	zone := newYork(t)
+	tests := []struct {
+		name      string
+		due       *time.Time
+		rule      string
+		wantStart string
+		wantDue   string
+	}{
+		{"evening", timePtr(time.Date(2026, time.October, 14, 18, 30, 0, 0, zone)), "FREQ=WEEKLY;BYDAY=WE", "20261014T040000Z", "20261014T223000Z"},
+		{"end of day", timePtr(time.Date(2026, time.October, 14, 23, 59, 0, 0, zone)), "FREQ=DAILY", "20261014T040000Z", "20261015T035900Z"},
+		{"midnight", timePtr(time.Date(2026, time.October, 14, 0, 0, 0, 0, zone)), "FREQ=MONTHLY", "20261013T040000Z", "20261014T040000Z"},
+		{"no due date", nil, "FREQ=DAILY", "", ""},
+		{"not repeating", timePtr(time.Date(2026, time.October, 14, 18, 30, 0, 0, zone)), "", "", "20261014T223000Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todo := Todo{ID: "1", Title: "Water plants", Priority: PriorityMedium, DueAt: tt.due, DueZone: zone.String(), Recurrence: tt.rule}
+			var buf bytes.Buffer
+			if err := exportICS(&buf, []Todo{todo}, time.Date(2026, time.October, 14, 12, 0, 0, 0, time.UTC)); err != nil {
+				t.Fatal(err)
+			}
+			props := icsProperties(buf.String())
+
+			if got := props["DTSTART"]; got != tt.wantStart {
+				t.Errorf("DTSTART = %q, want %q", got, tt.wantStart)
+			}
+			if got := props["DUE"]; got != tt.wantDue {
+				t.Errorf("DUE = %q, want %q", got, tt.wantDue)
+			}
+			if props["DTSTART"] != "" && props["DTSTART"] >= props["DUE"] {
+				t.Errorf("DTSTART %s is not before DUE %s", props["DTSTART"], props["DUE"])
+			}
+			wantRule := tt.rule
+			if tt.due == nil {
+				wantRule = ""
+			}
+			if got := props["RRULE"]; got != wantRule {
+				t.Errorf("RRULE = %q, want %q", got, wantRule)
+			}
+		})
+	}
+}
+
+// icsProperties maps the property names of an exported calendar to their
+// last values.
+func icsProperties(calendar string) map[string]string {
+	props := map[string]string{}
+	for _, line := range strings.Split(calendar, "\r\n") {
+		if name, value, ok := strings.Cut(line, ":"); ok {
+			props[name] = value
+		}
+	}
+	return props
+}
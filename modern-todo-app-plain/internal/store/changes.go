@@ -0,0 +1,132 @@
+package store
+
+import (
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// changeBuffer is how many changes a subscriber may fall behind before it
+// starts missing them.
+const changeBuffer = 16
+
+// Change describes one committed mutation so open pages can catch up.
+type Change struct {
+	// Revision increases with every committed mutation. Each process starts
+	// counting from its start time in microseconds, so it keeps increasing
+	// across restarts.
+	Revision uint64 `json:"revision"`
+	// TodoIDs lists the todos whose content changed.
+	TodoIDs []string `json:"ids"`
+	// Full is set when the change reaches beyond the changed cards: todos
+	// were added, removed or moved between views or lists, lists changed, or
+	// the subscriber missed earlier changes.
+	Full bool `json:"full"`
+}
+
+// Commit describes what one mutation committed, so the caller can react to
+// its own change rather than to whatever the store did last. The zero Commit
+// means the mutation changed nothing.
+type Commit struct {
+	// Change is what the mutation published to subscribers.
+	Change
+	// Undo is the label of the undo step the mutation recorded, or empty if
+	// it recorded none.
+	Undo string
+}
+
+// changeFeed fans committed changes out to subscribers. It has its own lock
+// so subscribers can come and go while the store is busy.
+type changeFeed struct {
+	mu   sync.Mutex
+	subs map[chan Change]*subscriber
+}
+
+type subscriber struct {
+	// lagged is set when a change was dropped because the buffer was full.
+	lagged bool
+}
+
+// Subscribe returns a channel that receives every change committed from now
+// on, and a function that cancels the subscription. A subscriber that falls
+// behind misses changes; the next one it receives is marked Full.
+//
+// The channel starts with a Full change at the current revision, so a page
+// that reconnects after missing changes, even across a restart, catches up.
+func (s *Store) Subscribe() (<-chan Change, func()) {
+	ch := make(chan Change, changeBuffer)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ch <- Change{Revision: s.revision, Full: true}
+
+	s.feed.mu.Lock()
+	if s.feed.subs == nil {
+		s.feed.subs = map[chan Change]*subscriber{}
+	}
+	s.feed.subs[ch] = &subscriber{}
+	s.feed.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			s.feed.mu.Lock()
+			delete(s.feed.subs, ch)
+			s.feed.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Revision returns the revision of the last committed mutation.
+func (s *Store) Revision() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.revision
+}
+
+// publishLocked bumps the revision and notifies subscribers of changes, the
+// difference between prev and the current state, and returns what it sent. It
+// never blocks on a slow subscriber.
+func (s *Store) publishLocked(prev Snapshot, changes []todoChange) Change {
+	s.revision++
+	change := Change{Revision: s.revision, Full: !slices.Equal(prev.Projects, s.projects)}
+
+	if len(changes) == 0 {
+		change.Full = true
+	}
+	for _, c := range changes {
+		change.TodoIDs = append(change.TodoIDs, c.id)
+		if c.before == nil || c.after == nil || movesCard(*c.before, *c.after) {
+			change.Full = true
+		}
+	}
+	slices.Sort(change.TodoIDs)
+
+	s.feed.mu.Lock()
+	defer s.feed.mu.Unlock()
+	for ch, sub := range s.feed.subs {
+		next := change
+		if sub.lagged {
+			next.Full = true
+		}
+		select {
+		case ch <- next:
+			sub.lagged = false
+		default:
+			sub.lagged = true
+		}
+	}
+	return change
+}
+
+// movesCard reports whether a change can move a todo in or out of a view or
+// change the counts beside it, so re-rendering its card alone is not enough.
+func movesCard(before, after Todo) bool {
+	return before.Completed != after.Completed ||
+		before.ProjectID != after.ProjectID ||
+		before.Position != after.Position ||
+		!reflect.DeepEqual(before.DueAt, after.DueAt) ||
+		!reflect.DeepEqual(before.DeletedAt, after.DeletedAt) ||
+		!slices.Equal(before.Tags, after.Tags)
+}
@@ -0,0 +1,47 @@
+package store
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSubscribeCatchesUpAcrossRestarts(t *testing.T) {
+	backend := NewMemoryBackend()
+	before, err := Open(backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	todo, _, err := before.Add(Draft{Title: "Seen before the restart", Priority: PriorityMedium})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered := before.Revision()
+	// Revisions count microseconds from the start; a real restart takes far
+	// longer than the one commit made since.
+	time.Sleep(time.Millisecond)
+
+	after, err := Open(backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes, cancel := after.Subscribe()
+	defer cancel()
+
+	// A page rendered before the restart is told to refresh on reconnecting.
+	first := <-changes
+	if !first.Full || first.Revision != after.Revision() {
+		t.Errorf("first change = %+v, want a full change at revision %d", first, after.Revision())
+	}
+	if first.Revision <= rendered {
+		t.Errorf("revision after the restart = %d, want more than %d", first.Revision, rendered)
+	}
+
+	if _, _, err := after.Toggle(todo.ID); err != nil {
+		t.Fatal(err)
+	}
+	next := <-changes
+	if next.Revision != first.Revision+1 || !slices.Equal(next.TodoIDs, []string{todo.ID}) {
+		t.Errorf("change = %+v, want revision %d for %q", next, first.Revision+1, todo.ID)
+	}
+}
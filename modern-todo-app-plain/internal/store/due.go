@@ -0,0 +1,81 @@
+package store
+
+import (
+	"sync"
+	"time"
+)
+
+// DueLocal returns the due time in the time zone it was entered in.
+func (t Todo) DueLocal() (time.Time, bool) {
+	if t.DueAt == nil {
+		return time.Time{}, false
+	}
+	return t.DueAt.In(t.location()), true
+}
+
+// IsOverdue reports whether an open todo is past its due time.
+func (t Todo) IsOverdue(now time.Time) bool {
+	return !t.Completed && t.DueAt != nil && t.DueAt.Before(now)
+}
+
+// IsDueThisWeek reports whether an open todo falls due between now and the end
+// of the current Monday-to-Sunday week in the todo's own time zone.
+func (t Todo) IsDueThisWeek(now time.Time) bool {
+	if t.Completed || t.DueAt == nil || t.DueAt.Before(now) {
+		return false
+	}
+	return t.DueAt.Before(endOfWeek(now.In(t.location())))
+}
+
+func (t Todo) location() *time.Location {
+	if t.DueZone == "" {
+		return time.UTC
+	}
+	return zoneLocation(t.DueZone)
+}
+
+// zones caches zoneLocation by zone name. Listing and rendering todos look up
+// the same few zones over and over, and time.LoadLocation reads the zone
+// database every time.
+var zones sync.Map
+
+// zoneLocation returns the named IANA time zone, or UTC if it is unknown.
+func zoneLocation(name string) *time.Location {
+	if loc, ok := zones.Load(name); ok {
+		return loc.(*time.Location)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		// Not cached, so stray names cannot grow the cache.
+		return time.UTC
+	}
+	zones.Store(name, loc)
+	return loc
+}
+
+func (t *Todo) setDue(at *time.Time, zone string) {
+	if at == nil {
+		t.DueAt, t.DueZone = nil, ""
+		return
+	}
+	due := at.UTC()
+	t.DueAt = &due
+	t.DueZone = zone
+	if t.DueZone == "" {
+		t.DueZone = at.Location().String()
+	}
+}
+
+// endOfWeek returns midnight at the start of the Monday after now.
+func endOfWeek(now time.Time) time.Time {
+	daysLeft := (7 - int(now.Weekday()) + int(time.Monday)) % 7
+	if daysLeft == 0 {
+		daysLeft = 7
+	}
+	y, m, d := now.Date()
+	return time.Date(y, m, d+daysLeft, 0, 0, 0, 0, now.Location())
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDueBoundaries(t *testing.T) {
+	zone := newYork(t)
+	// A Wednesday; the week ends at midnight before Monday the 18th.
+	wednesday := time.Date(2024, time.March, 13, 12, 0, 0, 0, time.UTC)
+	sunday := time.Date(2024, time.March, 17, 10, 0, 0, 0, time.UTC)
+	due := func(at time.Time) Todo {
+		return Todo{DueAt: timePtr(at.UTC()), DueZone: at.Location().String()}
+	}
+	completed := func(todo Todo) Todo {
+		todo.Completed = true
+		return todo
+	}
+
+	tests := []struct {
+		name         string
+		todo         Todo
+		now          time.Time
+		wantOverdue  bool
+		wantThisWeek bool
+	}{
+		{name: "no due date", todo: Todo{}, now: wednesday},
+		{name: "due now", todo: due(wednesday), now: wednesday, wantThisWeek: true},
+		{name: "a second ago", todo: due(wednesday.Add(-time.Second)), now: wednesday, wantOverdue: true},
+		{name: "completed and late", todo: completed(due(wednesday.Add(-time.Hour))), now: wednesday},
+		{name: "completed and due this week", todo: completed(due(wednesday.Add(time.Hour))), now: wednesday},
+		{name: "last minute of Sunday", todo: due(time.Date(2024, time.March, 17, 23, 59, 0, 0, time.UTC)), now: wednesday, wantThisWeek: true},
+		{name: "midnight starting Monday", todo: due(time.Date(2024, time.March, 18, 0, 0, 0, 0, time.UTC)), now: wednesday},
+		{name: "later on a Sunday", todo: due(time.Date(2024, time.March, 17, 23, 0, 0, 0, time.UTC)), now: sunday, wantThisWeek: true},
+		{name: "Monday seen from Sunday", todo: due(time.Date(2024, time.March, 18, 9, 0, 0, 0, time.UTC)), now: sunday},
+		// Sunday 23:00 in New York is Monday 03:00 UTC, but the week is
+		// counted in the todo's zone.
+		{name: "Sunday night in the todo's zone", todo: due(time.Date(2024, time.March, 17, 23, 0, 0, 0, zone)), now: wednesday, wantThisWeek: true},
+		{name: "Monday in the todo's zone", todo: due(time.Date(2024, time.March, 18, 0, 0, 0, 0, zone)), now: wednesday},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.todo.IsOverdue(tt.now); got != tt.wantOverdue {
+				t.Errorf("IsOverdue = %t, want %t", got, tt.wantOverdue)
+			}
+			if got := tt.todo.IsDueThisWeek(tt.now); got != tt.wantThisWeek {
+				t.Errorf("IsDueThisWeek = %t, want %t", got, tt.wantThisWeek)
+			}
+		})
+	}
+}
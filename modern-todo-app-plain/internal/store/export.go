@@ -0,0 +1,197 @@
+package store
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Format is a file format todos can be exported to and imported from.
+type Format string
+
+const (
+	FormatJSON     Format = "json"
+	FormatCSV      Format = "csv"
+	FormatTodoTxt  Format = "todotxt"
+	FormatMarkdown Format = "md"
+	// FormatICS is an iCalendar file of VTODO components (RFC 5545).
+	FormatICS Format = "ics"
+)
+
+// Formats lists every supported format.
+var Formats = []Format{FormatJSON, FormatCSV, FormatTodoTxt, FormatMarkdown, FormatICS}
+
+// ParseFormat reads a format name such as "csv" or "todotxt".
+func ParseFormat(raw string) (Format, bool) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "json":
+		return FormatJSON, true
+	case "csv":
+		return FormatCSV, true
+	case "todotxt", "todo.txt", "txt":
+		return FormatTodoTxt, true
+	case "md", "markdown":
+		return FormatMarkdown, true
+	case "ics", "ical", "icalendar":
+		return FormatICS, true
+	}
+	return "", false
+}
+
+// FormatForFile guesses the format from a file name's extension.
+func FormatForFile(name string) (Format, bool) {
+	return ParseFormat(strings.TrimPrefix(filepath.Ext(name), "."))
+}
+
+// Extension is the file extension used when downloading an export.
+func (f Format) Extension() string {
+	switch f {
+	case FormatTodoTxt:
+		return "txt"
+	default:
+		return string(f)
+	}
+}
+
+// ContentType is the MIME type of an export.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatJSON:
+		return "application/json; charset=utf-8"
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatMarkdown:
+		return "text/markdown; charset=utf-8"
+	case FormatICS:
+		return "text/calendar; charset=utf-8"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// csvHeader is the column order of CSV exports. Imports match columns by
+// name, so extra or reordered columns are fine.
+var csvHeader = []string{"title", "description", "completed", "priority", "created_at", "due_at", "due_zone", "tags", "recurrence"}
+
+// todoTxtPriorities maps priorities to todo.txt letters.
+var todoTxtPriorities = map[Priority]string{PriorityHigh: "A", PriorityMedium: "B", PriorityLow: "C"}
+
+// Export writes todos in the given format.
+func Export(w io.Writer, format Format, todos []Todo) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if todos == nil {
+			todos = []Todo{}
+		}
+		return enc.Encode(todos)
+	case FormatCSV:
+		return exportCSV(w, todos)
+	case FormatTodoTxt:
+		return exportTodoTxt(w, todos)
+	case FormatMarkdown:
+		return exportMarkdown(w, todos)
+	case FormatICS:
+		return exportICS(w, todos, time.Now())
+	}
+	return fmt.Errorf("unknown export format %q", format)
+}
+
+func exportCSV(w io.Writer, todos []Todo) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, todo := range todos {
+		record := []string{
+			todo.Title,
+			todo.Description,
+			fmt.Sprintf("%t", todo.Completed),
+			string(todo.Priority),
+			todo.CreatedAt.Format(time.RFC3339),
+			formatDue(todo),
+			todo.DueZone,
+			strings.Join(todo.Tags, ","),
+			todo.Recurrence,
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// exportTodoTxt writes one todo per line in todo.txt format. Tags become
+// +projects and the due date a due: key. todo.txt has no room for
+// descriptions, so they are left out.
+func exportTodoTxt(w io.Writer, todos []Todo) error {
+	for _, todo := range todos {
+		var parts []string
+		if todo.Completed {
+			parts = append(parts, "x")
+		}
+		if letter, ok := todoTxtPriorities[todo.Priority]; ok {
+			parts = append(parts, "("+letter+")")
+		}
+		parts = append(parts, todo.CreatedAt.Format("2006-01-02"))
+		parts = append(parts, strings.Join(strings.Fields(todo.Title), " "))
+		for _, tag := range todo.Tags {
+			parts = append(parts, "+"+tag)
+		}
+		if due, ok := todo.DueLocal(); ok {
+			parts = append(parts, "due:"+due.Format("2006-01-02"))
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(parts, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportMarkdown writes a GitHub task list. Each item carries its fields as a
+// nested list, followed by the description as an indented paragraph.
+func exportMarkdown(w io.Writer, todos []Todo) error {
+	var b strings.Builder
+	b.WriteString("# Todos\n")
+	for _, todo := range todos {
+		check := " "
+		if todo.Completed {
+			check = "x"
+		}
+		fmt.Fprintf(&b, "\n- [%s] %s\n", check, strings.Join(strings.Fields(todo.Title), " "))
+		fmt.Fprintf(&b, "  - Priority: %s\n", todo.Priority)
+		if due := formatDue(todo); due != "" {
+			fmt.Fprintf(&b, "  - Due: %s\n", due)
+		}
+		if len(todo.Tags) > 0 {
+			fmt.Fprintf(&b, "  - Tags: %s\n", strings.Join(todo.Tags, ", "))
+		}
+		if todo.Recurrence != "" {
+			fmt.Fprintf(&b, "  - Repeat: %s\n", todo.Recurrence)
+		}
+		fmt.Fprintf(&b, "  - Created: %s\n", todo.CreatedAt.Format(time.RFC3339))
+		if todo.Description != "" {
+			b.WriteString("\n")
+			for _, line := range strings.Split(todo.Description, "\n") {
+				fmt.Fprintf(&b, "  %s\n", line)
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// formatDue renders the due date in the zone it was entered in.
+func formatDue(todo Todo) string {
+	due, ok := todo.DueLocal()
+	if !ok {
+		return ""
+	}
+	return due.Format(time.RFC3339)
+}
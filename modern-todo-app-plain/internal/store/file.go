@@ -0,0 +1,114 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// schemaVersion is the current on-disk format. Bump it and register a
+// migration whenever a stored field changes shape.
+const schemaVersion = 3
+
+// migrations upgrade the raw data payload from version N to N+1, keyed by N.
+var migrations = map[int]func(json.RawMessage) (json.RawMessage, error){
+	1: migratePositions,
+	2: migrateProjects,
+}
+
+type fileEnvelope struct {
+	Schema int             `json:"schema"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// FileBackend stores snapshots as a JSON document on disk. Writes go to a
+// temporary file that is synced and renamed over the original, so a crash
+// mid-write leaves the previous version intact.
+type FileBackend struct {
+	path string
+}
+
+func NewFileBackend(path string) *FileBackend {
+	return &FileBackend{path: path}
+}
+
+func (b *FileBackend) Load() (Snapshot, bool, error) {
+	raw, err := os.ReadFile(b.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Snapshot{}, false, nil
+	}
+	if err != nil {
+		return Snapshot{}, false, err
+	}
+
+	var envelope fileEnvelope
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return Snapshot{}, false, fmt.Errorf("decode %s: %w", b.path, err)
+	}
+	if envelope.Schema > schemaVersion {
+		return Snapshot{}, false, fmt.Errorf("%s uses schema %d, newer than supported %d", b.path, envelope.Schema, schemaVersion)
+	}
+
+	data := envelope.Data
+	for version := envelope.Schema; version < schemaVersion; version++ {
+		migrate, ok := migrations[version]
+		if !ok {
+			return Snapshot{}, false, fmt.Errorf("no migration from schema %d", version)
+		}
+		if data, err = migrate(data); err != nil {
+			return Snapshot{}, false, fmt.Errorf("migrate schema %d: %w", version, err)
+		}
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return Snapshot{}, false, fmt.Errorf("decode %s: %w", b.path, err)
+	}
+	return snapshot, true, nil
+}
+
+func (b *FileBackend) Save(snapshot Snapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	raw, err := json.MarshalIndent(fileEnvelope{Schema: schemaVersion, Data: data}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(b.path, raw)
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer func() { _ = os.Remove(tmpName) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return err
+	}
+
+	// Sync the directory so the rename itself survives a power loss.
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		_ = d.Close()
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeDataFile writes contents to a data file in a fresh directory and
+// returns its path.
+func writeDataFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "todos.json")
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFileBackendSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.json")
+	if _, found, err := NewFileBackend(path).Load(); err != nil || found {
+		t.Fatalf("Load() of a missing file: found = %t, err = %v", found, err)
+	}
+
+	s, err := Open(NewFileBackend(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	added, _, err := s.Add(Draft{Title: "Survive a restart", Priority: PriorityHigh, Tags: []string{"ops"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := fmt.Sprintf(`"schema": %d`, schemaVersion); !strings.Contains(string(raw), want) {
+		t.Errorf("saved file is not schema %d:\n%s", schemaVersion, raw)
+	}
+
+	reopened, err := Open(NewFileBackend(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := reopened.Get(added.ID)
+	if err != nil {
+		t.Fatalf("reopened store lost %q: %v", added.Title, err)
+	}
+	if got.Title != added.Title || got.Priority != added.Priority || strings.Join(got.Tags, ",") != "ops" {
+		t.Errorf("reopened todo = %+v, want %+v", got, added)
+	}
+	if next, _, err := reopened.Add(Draft{Title: "Next", Priority: PriorityMedium}); err != nil || next.ID == added.ID {
+		t.Errorf("Add after reopening: id = %q, err = %v; want a new id", next.ID, err)
+	}
+}
+
+func TestFileBackendLoadRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+	}{
+		{"newer schema", fmt.Sprintf(`{"schema": %d, "data": {}}`, schemaVersion+1)},
+		{"unknown schema", `{"schema": 0, "data": {}}`},
+		{"not JSON", `todos`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeDataFile(t, tt.file)
+			if _, _, err := NewFileBackend(path).Load(); err == nil {
+				t.Error("Load() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestFileBackendMigratesPositions(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		// positions are the wanted manual positions by todo id.
+		positions map[string]float64
+	}{
+		{
+			name: "newest first",
+			file: `{"schema": 1, "data": {"next_id": 4, "todos": [
+				{"id": "1", "title": "Oldest", "priority": "low", "created_at": "2024-01-01T09:00:00Z"},
+				{"id": "3", "title": "Newest", "priority": "high", "created_at": "2024-03-01T09:00:00Z"},
+				{"id": "2", "title": "Middle", "priority": "medium", "created_at": "2024-02-01T09:00:00Z"}
+			]}}`,
+			positions: map[string]float64{"3": 0, "2": positionStep, "1": 2 * positionStep},
+		},
+		{
+			name:      "without todos",
+			file:      `{"schema": 1, "data": {"next_id": 1}}`,
+			positions: map[string]float64{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot, found, err := NewFileBackend(writeDataFile(t, tt.file)).Load()
+			if err != nil || !found {
+				t.Fatalf("Load() found = %t, err = %v", found, err)
+			}
+			if len(snapshot.Todos) != len(tt.positions) {
+				t.Fatalf("loaded %d todos, want %d", len(snapshot.Todos), len(tt.positions))
+			}
+			for _, todo := range snapshot.Todos {
+				if want := tt.positions[todo.ID]; todo.Position != want {
+					t.Errorf("%q: position = %v, want %v", todo.Title, todo.Position, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFileBackendMigratesProjects(t *testing.T) {
+	path := writeDataFile(t, `{"schema": 2, "data": {"next_id": 3, "todos": [
+		{"id": "1", "title": "Keep my place", "priority": "low", "created_at": "2024-01-01T09:00:00Z", "position": 512},
+		{"id": "2", "title": "Me too", "priority": "high", "created_at": "2024-02-01T09:00:00Z", "position": -100}
+	]}}`)
+	snapshot, found, err := NewFileBackend(path).Load()
+	if err != nil || !found {
+		t.Fatalf("Load() found = %t, err = %v", found, err)
+	}
+
+	if len(snapshot.Projects) != 1 || snapshot.Projects[0].ID != InboxProjectID {
+		t.Errorf("projects = %+v, want only the inbox", snapshot.Projects)
+	}
+	if snapshot.NextProjectID != 1 {
+		t.Errorf("next project id = %d, want 1", snapshot.NextProjectID)
+	}
+	positions := map[string]float64{"1": 512, "2": -100}
+	for _, todo := range snapshot.Todos {
+		if todo.ProjectID != InboxProjectID {
+			t.Errorf("%q is in list %q, want the inbox", todo.Title, todo.ProjectID)
+		}
+		if want := positions[todo.ID]; todo.Position != want {
+			t.Errorf("%q: position = %v, want %v", todo.Title, todo.Position, want)
+		}
+	}
+
+	// The migrated store opens and saves in the current schema.
+	s, err := Open(NewFileBackend(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.Add(Draft{Title: "After the upgrade", Priority: PriorityMedium}); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := fmt.Sprintf(`"schema": %d`, schemaVersion); !strings.Contains(string(raw), want) {
+		t.Errorf("saved file is not schema %d:\n%s", schemaVersion, raw)
+	}
+}
@@ -0,0 +1,241 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// maxHistory bounds the undo and redo stacks.
+const maxHistory = 50
+
+var (
+	ErrNothingToUndo = errors.New("nothing to undo")
+	ErrNothingToRedo = errors.New("nothing to redo")
+	// ErrHistoryConflict means a todo the command touched has changed since,
+	// through an edit the log does not record (subtasks, lists) or
+	// another user, so applying it would discard that change.
+	ErrHistoryConflict = errors.New("the todo has changed since")
+)
+
+// command is one reversible entry in the command log. It records the todos a
+// mutation touched as they were before and after it, so undoing one command
+// leaves unrelated edits made since then in place.
+type command struct {
+	label   string
+	changes []todoChange
+}
+
+// todoChange is the before and after state of one todo. A nil side means the
+// todo did not exist.
+type todoChange struct {
+	id     string
+	before *Todo
+	after  *Todo
+}
+
+// moved reports whether the change put the todo in a different place in the
+// manual order.
+func (c todoChange) moved() bool {
+	return c.before != nil && c.after != nil && c.before.Position != c.after.Position
+}
+
+// Undo reverts the most recent reversible mutation and returns its label. If
+// a todo it touched has changed since, the command is dropped instead and
+// ErrHistoryConflict is returned with its label.
+//
+// The command log belongs to the store, not to a session: every client shares
+// it, so Undo may revert a change another page made.
+func (s *Store) Undo() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.undo) == 0 {
+		return "", ErrNothingToUndo
+	}
+	cmd := s.undo[len(s.undo)-1]
+	if !s.unchangedLocked(cmd, func(change todoChange) *Todo { return change.after }) {
+		s.undo = s.undo[:len(s.undo)-1]
+		return cmd.label, ErrHistoryConflict
+	}
+
+	prev := s.snapshotLocked()
+	for _, change := range cmd.changes {
+		s.replaceLocked(change, change.before)
+	}
+	s.sortLocked()
+	if _, err := s.commitLocked(prev); err != nil {
+		return "", err
+	}
+
+	s.undo = s.undo[:len(s.undo)-1]
+	s.redo = append(s.redo, cmd)
+	return cmd.label, nil
+}
+
+// Redo reapplies the most recently undone mutation and returns its label. Like
+// Undo, it drops the command and returns ErrHistoryConflict if a todo it
+// touched has changed since.
+func (s *Store) Redo() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.redo) == 0 {
+		return "", ErrNothingToRedo
+	}
+	cmd := s.redo[len(s.redo)-1]
+	if !s.unchangedLocked(cmd, func(change todoChange) *Todo { return change.before }) {
+		s.redo = s.redo[:len(s.redo)-1]
+		return cmd.label, ErrHistoryConflict
+	}
+
+	prev := s.snapshotLocked()
+	for _, change := range cmd.changes {
+		s.replaceLocked(change, change.after)
+	}
+	s.sortLocked()
+	if _, err := s.commitLocked(prev); err != nil {
+		return "", err
+	}
+
+	s.redo = s.redo[:len(s.redo)-1]
+	s.undo = append(s.undo, cmd)
+	return cmd.label, nil
+}
+
+// History returns the labels of the commands Undo and Redo would apply next.
+// An empty label means the stack is empty.
+func (s *Store) History() (undo, redo string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if n := len(s.undo); n > 0 {
+		undo = s.undo[n-1].label
+	}
+	if n := len(s.redo); n > 0 {
+		redo = s.redo[n-1].label
+	}
+	return undo, redo
+}
+
+// recordLocked adds the difference between prev and the current todos to the
+// command log and returns label, or "" when there was nothing to record. It
+// must be called after a successful commit. Recording a new command clears
+// the redo stack.
+func (s *Store) recordLocked(label string, prev Snapshot) string {
+	changes := s.diffLocked(prev)
+	if len(changes) == 0 {
+		return ""
+	}
+
+	s.undo = append(s.undo, command{label: label, changes: changes})
+	if len(s.undo) > maxHistory {
+		s.undo = s.undo[len(s.undo)-maxHistory:]
+	}
+	s.redo = nil
+	return label
+}
+
+// diffLocked lists the todos that differ between prev and the current state.
+func (s *Store) diffLocked(prev Snapshot) []todoChange {
+	before := make(map[string]*Todo, len(prev.Todos))
+	for i := range prev.Todos {
+		before[prev.Todos[i].ID] = &prev.Todos[i]
+	}
+
+	var changes []todoChange
+	for _, todo := range s.todos {
+		old, existed := before[todo.ID]
+		delete(before, todo.ID)
+		after := todo.clone()
+		if existed && reflect.DeepEqual(*old, after) {
+			continue
+		}
+		changes = append(changes, todoChange{id: todo.ID, before: old, after: &after})
+	}
+	for id, old := range before {
+		changes = append(changes, todoChange{id: id, before: old})
+	}
+	return changes
+}
+
+// unchangedLocked reports whether every todo cmd touched is still in the state
+// side returns: present with the same content, or still missing. Every edit
+// changes the content, recorded or not, so a stale command is caught whoever
+// made the later edit. Versions are not compared because Undo and Redo bump
+// them while putting back content the neighbouring commands recorded.
+func (s *Store) unchangedLocked(cmd command, side func(todoChange) *Todo) bool {
+	for _, change := range cmd.changes {
+		want := side(change)
+		todo, err := s.findLocked(change.id)
+		switch {
+		case want == nil && err == nil, want != nil && err != nil:
+			return false
+		case want != nil && !sameContent(*want, todo.clone()):
+			return false
+		}
+	}
+	return true
+}
+
+// forgetLocked drops every recorded change to the given todos, along with
+// commands left empty as a result.
+func (s *Store) forgetLocked(ids map[string]bool) {
+	forget := func(log []command) []command {
+		kept := log[:0]
+		for _, cmd := range log {
+			changes := cmd.changes[:0]
+			for _, change := range cmd.changes {
+				if !ids[change.id] {
+					changes = append(changes, change)
+				}
+			}
+			if len(changes) > 0 {
+				cmd.changes = changes
+				kept = append(kept, cmd)
+			}
+		}
+		return kept
+	}
+	s.undo = forget(s.undo)
+	s.redo = forget(s.redo)
+}
+
+// replaceLocked sets the todo the change touched to state, inserting it if it
+// is missing and removing it when state is nil. A todo that still exists keeps
+// its current place unless the change itself moved it, and a todo restored
+// into a list that no longer exists goes to the inbox.
+func (s *Store) replaceLocked(change todoChange, state *Todo) {
+	for i, todo := range s.todos {
+		if todo.ID != change.id {
+			continue
+		}
+		if state == nil {
+			s.todos = append(s.todos[:i], s.todos[i+1:]...)
+			return
+		}
+		position := todo.Position
+		*todo = state.clone()
+		if !change.moved() {
+			todo.Position = position
+		}
+		s.fixProjectLocked(todo)
+		return
+	}
+	if state != nil {
+		todo := state.clone()
+		s.fixProjectLocked(&todo)
+		s.todos = append(s.todos, &todo)
+	}
+}
+
+func (s *Store) fixProjectLocked(todo *Todo) {
+	if _, err := s.findProjectLocked(todo.ProjectID); err != nil {
+		todo.ProjectID = InboxProjectID
+	}
+}
+
+// todoLabel names a todo in command labels.
+func todoLabel(verb, title string) string {
+	return fmt.Sprintf("%s %q", verb, title)
+}
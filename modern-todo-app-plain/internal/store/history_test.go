@@ -0,0 +1,202 @@
+package store
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	rename := func(t *testing.T, s *Store, id string) {
+		t.Helper()
+		if _, _, err := s.Update(id, Draft{Title: "Final", Priority: PriorityMedium}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		// steps runs after "Draft" is added and returns the result of the
+		// last Undo or Redo.
+		steps     func(t *testing.T, s *Store, id string) (string, error)
+		wantLabel string
+		wantErr   error
+		wantTitle string
+		// wantUndo and wantRedo are the labels History reports afterwards.
+		wantUndo string
+		wantRedo string
+	}{
+		{
+			name: "undo",
+			steps: func(t *testing.T, s *Store, id string) (string, error) {
+				rename(t, s, id)
+				return s.Undo()
+			},
+			wantLabel: `Updated "Final"`,
+			wantTitle: "Draft",
+			wantUndo:  `Added "Draft"`,
+			wantRedo:  `Updated "Final"`,
+		},
+		{
+			name: "undo then redo",
+			steps: func(t *testing.T, s *Store, id string) (string, error) {
+				rename(t, s, id)
+				if _, err := s.Undo(); err != nil {
+					t.Fatal(err)
+				}
+				return s.Redo()
+			},
+			wantLabel: `Updated "Final"`,
+			wantTitle: "Final",
+			wantUndo:  `Updated "Final"`,
+		},
+		{
+			name: "undo after a conflicting edit",
+			steps: func(t *testing.T, s *Store, id string) (string, error) {
+				rename(t, s, id)
+				if _, _, err := s.AddSubtask(id, "Step"); err != nil {
+					t.Fatal(err)
+				}
+				return s.Undo()
+			},
+			wantLabel: `Updated "Final"`,
+			wantErr:   ErrHistoryConflict,
+			wantTitle: "Final",
+			wantUndo:  `Added "Draft"`,
+		},
+		{
+			name: "redo after a conflicting edit",
+			steps: func(t *testing.T, s *Store, id string) (string, error) {
+				rename(t, s, id)
+				if _, err := s.Undo(); err != nil {
+					t.Fatal(err)
+				}
+				if _, _, err := s.AddSubtask(id, "Step"); err != nil {
+					t.Fatal(err)
+				}
+				return s.Redo()
+			},
+			wantLabel: `Updated "Final"`,
+			wantErr:   ErrHistoryConflict,
+			wantTitle: "Draft",
+			wantUndo:  `Added "Draft"`,
+		},
+		{
+			name: "new action discards the redo branch",
+			steps: func(t *testing.T, s *Store, id string) (string, error) {
+				rename(t, s, id)
+				if _, err := s.Undo(); err != nil {
+					t.Fatal(err)
+				}
+				if _, _, err := s.Toggle(id); err != nil {
+					t.Fatal(err)
+				}
+				return s.Redo()
+			},
+			wantErr:   ErrNothingToRedo,
+			wantTitle: "Draft",
+			wantUndo:  `Completed "Draft"`,
+		},
+		{
+			name: "undo past the first action",
+			steps: func(t *testing.T, s *Store, id string) (string, error) {
+				if _, err := s.Undo(); err != nil {
+					t.Fatal(err)
+				}
+				return s.Undo()
+			},
+			wantErr:  ErrNothingToUndo,
+			wantRedo: `Added "Draft"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := openEmpty(t)
+			added, _, err := s.Add(Draft{Title: "Draft", Priority: PriorityMedium})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			label, err := tt.steps(t, s, added.ID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if label != tt.wantLabel {
+				t.Errorf("label = %q, want %q", label, tt.wantLabel)
+			}
+
+			title := ""
+			if todo, err := s.Get(added.ID); err == nil {
+				title = todo.Title
+			}
+			if title != tt.wantTitle {
+				t.Errorf("title = %q, want %q", title, tt.wantTitle)
+			}
+			if undo, redo := s.History(); undo != tt.wantUndo || redo != tt.wantRedo {
+				t.Errorf("History() = %q, %q; want %q, %q", undo, redo, tt.wantUndo, tt.wantRedo)
+			}
+		})
+	}
+}
+
+func TestCommitUndo(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(s *Store, id string) (Commit, error)
+		// want is the undo label the mutation reports; empty when it
+		// recorded nothing.
+		want string
+	}{
+		{
+			name: "edit",
+			mutate: func(s *Store, id string) (Commit, error) {
+				_, commit, err := s.Update(id, Draft{Title: "Final", Priority: PriorityMedium, Tags: []string{"work"}})
+				return commit, err
+			},
+			want: `Updated "Final"`,
+		},
+		{
+			name: "edit that changes nothing",
+			mutate: func(s *Store, id string) (Commit, error) {
+				_, commit, err := s.Update(id, Draft{Title: "Draft", Priority: PriorityMedium, Tags: []string{"work"}})
+				return commit, err
+			},
+		},
+		{
+			name: "rename a tag to itself",
+			mutate: func(s *Store, id string) (Commit, error) {
+				return s.RenameTag("work", "work")
+			},
+		},
+		{
+			name: "delete a tag nobody uses",
+			mutate: func(s *Store, id string) (Commit, error) {
+				return s.DeleteTag("home")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := openEmpty(t)
+			todo, _, err := s.Add(Draft{Title: "Draft", Priority: PriorityMedium, Tags: []string{"work"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			commit, err := tt.mutate(s, todo.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if commit.Undo != tt.want {
+				t.Errorf("Undo = %q, want %q", commit.Undo, tt.want)
+			}
+			// The step recorded by the add stays on top when nothing new was.
+			wantTop := tt.want
+			if wantTop == "" {
+				wantTop = `Added "Draft"`
+			}
+			if undo, _ := s.History(); undo != wantTop {
+				t.Errorf("History() undo = %q, want %q", undo, wantTop)
+			}
+		})
+	}
+}
@@ -0,0 +1,457 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidImport wraps every problem found while decoding an import file.
+var ErrInvalidImport = errors.New("invalid import file")
+
+// ImportResult describes what an import added, or would add on a dry run.
+type ImportResult struct {
+	Added []Todo
+	// Duplicates were skipped because a todo with the same title and due
+	// date already exists, or appears earlier in the same file.
+	Duplicates []Todo
+}
+
+// Decode reads todos from an export in the given format. Only the fields the
+// format carries are set; Import fills in the rest. Dates without a time zone,
+// such as todo.txt's due:2024-05-01, are read in loc (UTC if nil), and due
+// dates that do not name their zone are kept in loc.
+func Decode(r io.Reader, format Format, loc *time.Location) ([]Todo, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	var (
+		todos []Todo
+		err   error
+	)
+	switch format {
+	case FormatJSON:
+		todos, err = decodeJSON(r)
+	case FormatCSV:
+		todos, err = decodeCSV(r, loc)
+	case FormatTodoTxt:
+		todos, err = decodeTodoTxt(r, loc)
+	case FormatMarkdown:
+		todos, err = decodeMarkdown(r, loc)
+	case FormatICS:
+		todos, err = decodeICS(r, loc)
+	default:
+		err = fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	for i := range todos {
+		if todos[i].DueAt != nil && todos[i].DueZone == "" {
+			todos[i].DueZone = loc.String()
+		}
+	}
+	return todos, nil
+}
+
+// decodeJSON accepts a JSON export (an array of todos) or a JSON API list
+// response ({"data": [...]}).
+func decodeJSON(r io.Reader) ([]Todo, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		var list struct {
+			Data []Todo `json:"data"`
+		}
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, err
+		}
+		return list.Data, nil
+	}
+	var todos []Todo
+	if err := json.Unmarshal(data, &todos); err != nil {
+		return nil, err
+	}
+	return todos, nil
+}
+
+func decodeCSV(r io.Reader, loc *time.Location) ([]Todo, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, errors.New("CSV header has no title column")
+	}
+
+	var todos []Todo
+	for n, record := range records[1:] {
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		todo := Todo{
+			Title:       field("title"),
+			Description: field("description"),
+			Priority:    Priority(strings.ToLower(field("priority"))),
+			DueZone:     field("due_zone"),
+			Tags:        ParseTags(field("tags")),
+			Recurrence:  field("recurrence"),
+		}
+		if raw := field("completed"); raw != "" {
+			todo.Completed, err = strconv.ParseBool(raw)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: completed must be true or false", n+2)
+			}
+		}
+		if todo.CreatedAt, err = parseImportTime(field("created_at"), false, loc); err != nil {
+			return nil, fmt.Errorf("row %d: created_at: %v", n+2, err)
+		}
+		dueLoc := loc
+		if zone, err := time.LoadLocation(todo.DueZone); err == nil && todo.DueZone != "" {
+			dueLoc = zone
+		}
+		if due, err := parseImportTime(field("due_at"), true, dueLoc); err != nil {
+			return nil, fmt.Errorf("row %d: due_at: %v", n+2, err)
+		} else if !due.IsZero() {
+			todo.DueAt = &due
+		}
+		todos = append(todos, todo)
+	}
+	return todos, nil
+}
+
+// decodeTodoTxt reads todo.txt lines: an optional "x" completion marker, an
+// optional "(A)" priority, up to two dates (the last is the creation date),
+// then the title with +project and @context words taken as tags and due: as
+// the due date.
+func decodeTodoTxt(r io.Reader, loc *time.Location) ([]Todo, error) {
+	var todos []Todo
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		todo := Todo{Priority: PriorityMedium}
+		i := 0
+		if fields[i] == "x" {
+			todo.Completed = true
+			i++
+		}
+		for ; i < len(fields); i++ {
+			field := fields[i]
+			if len(field) == 3 && field[0] == '(' && field[2] == ')' && field[1] >= 'A' && field[1] <= 'Z' {
+				todo.Priority = todoTxtPriority(field[1])
+				continue
+			}
+			if created, err := time.ParseInLocation("2006-01-02", field, loc); err == nil {
+				todo.CreatedAt = created
+				continue
+			}
+			break
+		}
+
+		var title []string
+		for _, field := range fields[i:] {
+			switch {
+			case len(field) > 1 && (field[0] == '+' || field[0] == '@'):
+				todo.Tags = append(todo.Tags, field[1:])
+			case strings.HasPrefix(field, "due:"):
+				due, err := parseImportTime(strings.TrimPrefix(field, "due:"), true, loc)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: due: %v", n, err)
+				}
+				todo.DueAt = &due
+			case strings.HasPrefix(field, "pri:") && len(field) == 5:
+				todo.Priority = todoTxtPriority(field[4])
+			default:
+				title = append(title, field)
+			}
+		}
+		todo.Title = strings.Join(title, " ")
+		todo.Tags = NormalizeTags(todo.Tags)
+		todos = append(todos, todo)
+	}
+	return todos, scanner.Err()
+}
+
+func todoTxtPriority(letter byte) Priority {
+	switch letter {
+	case 'A':
+		return PriorityHigh
+	case 'B':
+		return PriorityMedium
+	default:
+		return PriorityLow
+	}
+}
+
+// decodeMarkdown reads the task lists written by Export: "- [ ]" or "- [x]"
+// items, their indented "- Key: value" fields and an indented description.
+// Other lines, such as headings, are ignored.
+func decodeMarkdown(r io.Reader, loc *time.Location) ([]Todo, error) {
+	var (
+		todos       []Todo
+		current     *Todo
+		description []string
+	)
+	finish := func() {
+		if current == nil {
+			return
+		}
+		current.Description = strings.TrimSpace(strings.Join(description, "\n"))
+		todos = append(todos, *current)
+		current, description = nil, nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		if item, done, ok := markdownTask(trimmed); ok && !strings.HasPrefix(line, " ") {
+			finish()
+			current = &Todo{Title: item, Completed: done, Priority: PriorityMedium}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") && trimmed != "" {
+			finish()
+			continue
+		}
+
+		if key, value, ok := markdownField(trimmed); ok && len(description) == 0 {
+			if err := applyMarkdownField(current, key, value, loc); err != nil {
+				return nil, fmt.Errorf("line %d: %v", n, err)
+			}
+			continue
+		}
+		if trimmed != "" || len(description) > 0 {
+			description = append(description, trimmed)
+		}
+	}
+	finish()
+	return todos, scanner.Err()
+}
+
+func markdownTask(line string) (title string, done bool, ok bool) {
+	for _, prefix := range []string{"- [ ] ", "* [ ] "} {
+		if strings.HasPrefix(line, prefix) {
+			return strings.TrimSpace(line[len(prefix):]), false, true
+		}
+	}
+	for _, prefix := range []string{"- [x] ", "- [X] ", "* [x] ", "* [X] "} {
+		if strings.HasPrefix(line, prefix) {
+			return strings.TrimSpace(line[len(prefix):]), true, true
+		}
+	}
+	return "", false, false
+}
+
+func markdownField(line string) (key, value string, ok bool) {
+	if !strings.HasPrefix(line, "- ") {
+		return "", "", false
+	}
+	key, value, ok = strings.Cut(line[2:], ":")
+	if !ok {
+		return "", "", false
+	}
+	return strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value), true
+}
+
+func applyMarkdownField(todo *Todo, key, value string, loc *time.Location) error {
+	var err error
+	switch key {
+	case "priority":
+		todo.Priority = Priority(strings.ToLower(value))
+	case "due":
+		var due time.Time
+		if due, err = parseImportTime(value, true, loc); err == nil && !due.IsZero() {
+			todo.DueAt = &due
+		}
+	case "tags":
+		todo.Tags = ParseTags(value)
+	case "repeat":
+		todo.Recurrence = value
+	case "created":
+		todo.CreatedAt, err = parseImportTime(value, false, loc)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %v", key, err)
+	}
+	return nil
+}
+
+// parseImportTime reads an RFC 3339 timestamp or a bare date in loc. A bare
+// due date means the end of that day, as in the add dialog; other bare dates
+// mean midnight. An empty value gives the zero time.
+func parseImportTime(value string, due bool, loc *time.Location) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not a date", value)
+	}
+	if due {
+		t = endOfDay(t)
+	}
+	return t, nil
+}
+
+// endOfDay returns 23:59 on the day of t, in t's location.
+func endOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 23, 59, 0, 0, t.Location())
+}
+
+// Import adds decoded todos to a list, skipping duplicates. With dryRun set it
+// only reports what would happen. A real import is a single command in the
+// undo log.
+func (s *Store) Import(todos []Todo, projectID string, dryRun bool) (ImportResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.resolveProjectLocked(&projectID); err != nil {
+		return ImportResult{}, err
+	}
+
+	seen := map[string]bool{}
+	for _, todo := range s.todos {
+		if !todo.Trashed() {
+			seen[duplicateKey(*todo)] = true
+		}
+	}
+
+	var result ImportResult
+	now := time.Now().UTC()
+	for n, todo := range todos {
+		normalized, err := normalizeImport(todo, projectID, now)
+		if err != nil {
+			return ImportResult{}, fmt.Errorf("%w: item %d: %v", ErrInvalidImport, n+1, err)
+		}
+		key := duplicateKey(normalized)
+		if seen[key] {
+			result.Duplicates = append(result.Duplicates, normalized)
+			continue
+		}
+		seen[key] = true
+		result.Added = append(result.Added, normalized)
+	}
+	if dryRun || len(result.Added) == 0 {
+		return result, nil
+	}
+
+	prev := s.snapshotLocked()
+	position := 0.0
+	if len(s.todos) > 0 {
+		position = s.todos[len(s.todos)-1].Position
+	}
+	for i := range result.Added {
+		position += positionStep
+		result.Added[i].ID = generateID(s.nextID)
+		result.Added[i].Position = position
+		s.nextID++
+		todo := result.Added[i].clone()
+		s.todos = append(s.todos, &todo)
+	}
+	if _, err := s.commitLocked(prev); err != nil {
+		return ImportResult{}, err
+	}
+	s.recordLocked(fmt.Sprintf("Imported %d tasks", len(result.Added)), prev)
+	return result, nil
+}
+
+// normalizeImport validates a decoded todo like the add dialog does and
+// resets the fields that belong to the store rather than the file.
+func normalizeImport(todo Todo, projectID string, now time.Time) (Todo, error) {
+	todo.Title = strings.TrimSpace(todo.Title)
+	todo.Priority = Priority(strings.ToLower(string(todo.Priority)))
+	if !todo.Priority.Valid() {
+		todo.Priority = PriorityMedium
+	}
+	draft := Draft{
+		Title:         todo.Title,
+		Description:   todo.Description,
+		Priority:      todo.Priority,
+		DueAt:         todo.DueAt,
+		RemindMinutes: todo.RemindMinutes,
+		Recurrence:    todo.Recurrence,
+	}
+	if err := draft.Validate(); err != nil {
+		return Todo{}, err
+	}
+	rule, err := normalizeRecurrence(todo.Recurrence)
+	if err != nil {
+		return Todo{}, err
+	}
+
+	out := Todo{
+		Title:       todo.Title,
+		Description: strings.TrimSpace(todo.Description),
+		Completed:   todo.Completed,
+		Priority:    todo.Priority,
+		CreatedAt:   todo.CreatedAt.UTC(),
+		Tags:        NormalizeTags(todo.Tags),
+		Subtasks:    append([]Subtask(nil), todo.Subtasks...),
+		Recurrence:  rule,
+		ProjectID:   projectID,
+	}
+	if out.CreatedAt.IsZero() {
+		out.CreatedAt = now
+	}
+	// The commit only stamps todos it sees being completed, so a completed
+	// todo keeps its time from the file or is stamped here.
+	if out.Completed {
+		out.CompletedAt = timePtr(now)
+		if todo.CompletedAt != nil {
+			out.CompletedAt = timePtr(todo.CompletedAt.UTC())
+		}
+	}
+	out.setDue(todo.DueAt, todo.DueZone)
+	out.setRemind(todo.RemindMinutes)
+	return out, nil
+}
+
+// duplicateKey identifies a todo for duplicate detection: the same title,
+// ignoring case and spacing, due on the same day in the todo's own zone. Days
+// rather than exact times are compared because todo.txt only records the
+// date.
+func duplicateKey(todo Todo) string {
+	key := strings.ToLower(strings.Join(strings.Fields(todo.Title), " "))
+	if due, ok := todo.DueLocal(); ok {
+		key += "|" + due.Format("2006-01-02")
+	}
+	return key
+}
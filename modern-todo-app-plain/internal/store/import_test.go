@@ -0,0 +1,230 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// openEmpty returns a store in memory without the demo todos.
+func openEmpty(t *testing.T) *Store {
+	t.Helper()
+	backend := NewMemoryBackend()
+	if err := backend.Save(Snapshot{NextID: 1, NextProjectID: 1, Projects: []Project{inboxProject()}}); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Open(backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func newYork(t *testing.T) *time.Location {
+	t.Helper()
+	zone, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no time zone data:", err)
+	}
+	return zone
+}
+
+func TestDecodeRoundTrip(t *testing.T) {
+	zone := newYork(t)
+	drafts := []Draft{
+		{Title: "File taxes", Priority: PriorityHigh, Tags: []string{"finance"}, DueAt: timePtr(time.Date(2026, time.October, 14, 23, 59, 0, 0, zone)), DueZone: zone.String()},
+		{Title: "Dentist", Description: "Bring the insurance card", Priority: PriorityLow, DueAt: timePtr(time.Date(2026, time.October, 20, 9, 30, 0, 0, zone)), DueZone: zone.String(), RemindMinutes: intPtr(90)},
+		{Title: "Read a book", Priority: PriorityMedium, Tags: []string{"home", "reading"}},
+	}
+
+	for _, format := range Formats {
+		t.Run(string(format), func(t *testing.T) {
+			s := openEmpty(t)
+			for _, draft := range drafts {
+				if _, _, err := s.Add(draft); err != nil {
+					t.Fatal(err)
+				}
+			}
+			originals := s.List(Query{Sort: SortCreated})
+
+			var buf bytes.Buffer
+			if err := Export(&buf, format, originals); err != nil {
+				t.Fatal(err)
+			}
+			exported := buf.String()
+			decoded, err := Decode(strings.NewReader(exported), format, zone)
+			if err != nil {
+				t.Fatalf("decode: %v\n%s", err, exported)
+			}
+			if len(decoded) != len(drafts) {
+				t.Fatalf("decoded %d todos, want %d\n%s", len(decoded), len(drafts), exported)
+			}
+
+			// Re-importing an export into the same store adds nothing.
+			result, err := s.Import(decoded, "", true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Added) != 0 || len(result.Duplicates) != len(drafts) {
+				t.Errorf("re-import added %d, skipped %d; want 0 and %d\n%s", len(result.Added), len(result.Duplicates), len(drafts), exported)
+			}
+
+			// Into an empty store, the todos come back as they were.
+			result, err = openEmpty(t).Import(decoded, "", false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Added) != len(drafts) {
+				t.Fatalf("import added %d todos, want %d", len(result.Added), len(drafts))
+			}
+			byTitle := map[string]Todo{}
+			for _, todo := range result.Added {
+				byTitle[todo.Title] = todo
+			}
+			for _, want := range originals {
+				got, ok := byTitle[want.Title]
+				if !ok {
+					t.Errorf("%q missing after import", want.Title)
+					continue
+				}
+				if got.Priority != want.Priority {
+					t.Errorf("%q: priority = %q, want %q", want.Title, got.Priority, want.Priority)
+				}
+				if strings.Join(got.Tags, ",") != strings.Join(want.Tags, ",") {
+					t.Errorf("%q: tags = %v, want %v", want.Title, got.Tags, want.Tags)
+				}
+				checkImportedDue(t, format, got, want)
+				if format == FormatJSON || format == FormatICS {
+					if remind(got) != remind(want) {
+						t.Errorf("%q: reminder = %d, want %d", want.Title, remind(got), remind(want))
+					}
+				}
+			}
+		})
+	}
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+// remind returns a todo's reminder, or -1 without one.
+func remind(todo Todo) int {
+	if todo.RemindMinutes == nil {
+		return -1
+	}
+	return *todo.RemindMinutes
+}
+
+// checkImportedDue compares due dates. todo.txt only keeps the day, which
+// imports as the end of that day.
+func checkImportedDue(t *testing.T, format Format, got, want Todo) {
+	t.Helper()
+	wantDue, hasDue := want.DueLocal()
+	gotDue, gotHasDue := got.DueLocal()
+	switch {
+	case hasDue != gotHasDue:
+		t.Errorf("%q: due = %v, want %v", want.Title, got.DueAt, want.DueAt)
+	case !hasDue:
+	case format == FormatTodoTxt:
+		if gotDue.Format("2006-01-02") != wantDue.Format("2006-01-02") || gotDue.Hour() != 23 || gotDue.Minute() != 59 {
+			t.Errorf("%q: due = %v, want the end of %s", want.Title, gotDue, wantDue.Format("2006-01-02"))
+		}
+	case !gotDue.Equal(wantDue):
+		t.Errorf("%q: due = %v, want %v", want.Title, gotDue, wantDue)
+	}
+	if hasDue && got.DueZone != want.DueZone {
+		t.Errorf("%q: due zone = %q, want %q", want.Title, got.DueZone, want.DueZone)
+	}
+}
+
+func TestDecodeBareDates(t *testing.T) {
+	zone := newYork(t)
+	tests := []struct {
+		format Format
+		input  string
+	}{
+		{FormatTodoTxt, "Pay rent due:2026-10-14\n"},
+		{FormatCSV, "title,due_at\nPay rent,2026-10-14\n"},
+		{FormatMarkdown, "- [ ] Pay rent\n  - Due: 2026-10-14\n"},
+		{FormatICS, "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nSUMMARY:Pay rent\r\nDUE;VALUE=DATE:20261014\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"},
+	}
+	want := time.Date(2026, time.October, 14, 23, 59, 0, 0, zone)
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			todos, err := Decode(strings.NewReader(tt.input), tt.format, zone)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(todos) != 1 || todos[0].DueAt == nil {
+				t.Fatalf("decoded %+v, want one todo with a due date", todos)
+			}
+			if !todos[0].DueAt.Equal(want) || todos[0].DueZone != zone.String() {
+				t.Errorf("due = %v in %q, want %v in %q", todos[0].DueAt, todos[0].DueZone, want, zone)
+			}
+		})
+	}
+}
+
+func TestImportValidates(t *testing.T) {
+	tests := []struct {
+		name string
+		todo Todo
+	}{
+		{"no title", Todo{Title: "  "}},
+		{"long title", Todo{Title: strings.Repeat("x", MaxTitleLength+1)}},
+		{"long description", Todo{Title: "Notes", Description: strings.Repeat("x", MaxDescriptionLength+1)}},
+		{"bad recurrence", Todo{Title: "Repeat", Recurrence: "FREQ=HOURLY"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := openEmpty(t).Import([]Todo{tt.todo}, "", true)
+			if !errors.Is(err, ErrInvalidImport) {
+				t.Errorf("err = %v, want ErrInvalidImport", err)
+			}
+		})
+	}
+}
+
+func TestImportCompletedAt(t *testing.T) {
+	done := time.Date(2024, time.May, 2, 18, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		todo Todo
+		// want is the expected CompletedAt; the zero time means "stamped at
+		// import" and nil means none.
+		want *time.Time
+	}{
+		{"completed with a time", Todo{Title: "Filed taxes", Completed: true, CompletedAt: &done}, &done},
+		{"completed without a time", Todo{Title: "Filed taxes", Completed: true}, &time.Time{}},
+		{"open with a stale time", Todo{Title: "File taxes", CompletedAt: &done}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := openEmpty(t)
+			before := time.Now().UTC()
+			if _, err := s.Import([]Todo{tt.todo}, "", false); err != nil {
+				t.Fatal(err)
+			}
+			todos := s.List(Query{})
+			if len(todos) != 1 {
+				t.Fatalf("imported %d todos, want 1", len(todos))
+			}
+			got := todos[0].CompletedAt
+			switch {
+			case tt.want == nil:
+				if got != nil {
+					t.Errorf("CompletedAt = %v, want nil", got)
+				}
+			case tt.want.IsZero():
+				if got == nil || got.Before(before) {
+					t.Errorf("CompletedAt = %v, want stamped at import (after %v)", got, before)
+				}
+			case got == nil || !got.Equal(*tt.want):
+				t.Errorf("CompletedAt = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
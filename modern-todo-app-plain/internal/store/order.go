@@ -0,0 +1,193 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+)
+
+// Sort selects the order List returns todos in.
+type Sort string
+
+const (
+	SortManual   Sort = "manual"
+	SortCreated  Sort = "created"
+	SortPriority Sort = "priority"
+	SortDue      Sort = "due"
+)
+
+// positionStep is the gap left between neighbours when positions are
+// (re)assigned. Moves bisect the gap; once it shrinks below minPositionGap
+// every position is spread out again.
+const (
+	positionStep   = 1024.0
+	minPositionGap = 1e-6
+)
+
+var ErrInvalidMove = errors.New("invalid move")
+
+// Move places the todo directly before beforeID, or directly after afterID
+// when beforeID is empty, in the manual order.
+func (s *Store) Move(id, beforeID, afterID string) (Todo, Commit, error) {
+	if (beforeID == "" && afterID == "") || beforeID == id || afterID == id {
+		return Todo{}, Commit{}, ErrInvalidMove
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	todo, err := s.findLiveLocked(id)
+	if err != nil {
+		return Todo{}, Commit{}, err
+	}
+
+	anchorID, after := beforeID, false
+	if anchorID == "" {
+		anchorID, after = afterID, true
+	}
+	if _, err := s.findLiveLocked(anchorID); err != nil {
+		return Todo{}, Commit{}, err
+	}
+
+	prev := s.snapshotLocked()
+	position, ok := s.slotLocked(todo, anchorID, after)
+	if !ok {
+		s.rebalanceLocked()
+		position, _ = s.slotLocked(todo, anchorID, after)
+	}
+	todo.Position = position
+	s.sortLocked()
+
+	commit, err := s.commitLocked(prev)
+	if err != nil {
+		return Todo{}, Commit{}, err
+	}
+	commit.Undo = s.recordLocked(todoLabel("Moved", todo.Title), prev)
+	return todo.clone(), commit, nil
+}
+
+// slotLocked returns a position next to the anchor, ignoring the todo being
+// moved. ok is false when the neighbours are too close to bisect.
+func (s *Store) slotLocked(moving *Todo, anchorID string, after bool) (float64, bool) {
+	others := make([]*Todo, 0, len(s.todos))
+	for _, todo := range s.todos {
+		if todo != moving {
+			others = append(others, todo)
+		}
+	}
+
+	for i, todo := range others {
+		if todo.ID != anchorID {
+			continue
+		}
+		var lo, hi *Todo
+		if after {
+			lo = todo
+			if i+1 < len(others) {
+				hi = others[i+1]
+			}
+		} else {
+			hi = todo
+			if i > 0 {
+				lo = others[i-1]
+			}
+		}
+
+		switch {
+		case lo == nil:
+			return hi.Position - positionStep, true
+		case hi == nil:
+			return lo.Position + positionStep, true
+		case hi.Position-lo.Position < minPositionGap:
+			return 0, false
+		default:
+			return (lo.Position + hi.Position) / 2, true
+		}
+	}
+	return 0, false
+}
+
+// rebalanceLocked spreads positions evenly in the current manual order.
+func (s *Store) rebalanceLocked() {
+	for i, todo := range s.todos {
+		todo.Position = float64(i) * positionStep
+	}
+}
+
+// topPositionLocked returns a position above every existing todo.
+func (s *Store) topPositionLocked() float64 {
+	if len(s.todos) == 0 {
+		return 0
+	}
+	return s.todos[0].Position - positionStep
+}
+
+// sortTodos orders a List result. Ties fall back to the manual order the
+// slice is already in.
+func sortTodos(todos []*Todo, order Sort) {
+	switch order {
+	case SortCreated:
+		sort.SliceStable(todos, func(i, j int) bool {
+			return todos[i].CreatedAt.After(todos[j].CreatedAt)
+		})
+	case SortPriority:
+		sort.SliceStable(todos, func(i, j int) bool {
+			return priorityRank(todos[i].Priority) > priorityRank(todos[j].Priority)
+		})
+	case SortDue:
+		sort.SliceStable(todos, func(i, j int) bool {
+			a, b := todos[i].DueAt, todos[j].DueAt
+			if a == nil || b == nil {
+				return a != nil
+			}
+			return a.Before(*b)
+		})
+	}
+}
+
+func priorityRank(p Priority) int {
+	switch p {
+	case PriorityHigh:
+		return 3
+	case PriorityMedium:
+		return 2
+	case PriorityLow:
+		return 1
+	}
+	return 0
+}
+
+// migratePositions (schema 1 -> 2) assigns manual positions in the previous
+// newest-first order.
+func migratePositions(data json.RawMessage) (json.RawMessage, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	var todos []map[string]any
+	if len(raw["todos"]) > 0 {
+		if err := json.Unmarshal(raw["todos"], &todos); err != nil {
+			return nil, err
+		}
+	}
+
+	createdAt := func(todo map[string]any) time.Time {
+		value, _ := todo["created_at"].(string)
+		t, _ := time.Parse(time.RFC3339Nano, value)
+		return t
+	}
+	sort.SliceStable(todos, func(i, j int) bool {
+		return createdAt(todos[i]).After(createdAt(todos[j]))
+	})
+	for i, todo := range todos {
+		todo["position"] = float64(i) * positionStep
+	}
+
+	encoded, err := json.Marshal(todos)
+	if err != nil {
+		return nil, err
+	}
+	raw["todos"] = encoded
+	return json.Marshal(raw)
+}
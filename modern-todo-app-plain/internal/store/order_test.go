@@ -0,0 +1,89 @@
+package store
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMoveUndoRedo(t *testing.T) {
+	s := openEmpty(t)
+	ids := map[string]string{}
+	for _, title := range []string{"C", "B", "A"} {
+		todo, _, err := s.Add(Draft{Title: title, Priority: PriorityMedium})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[title] = todo.ID
+	}
+
+	if _, _, err := s.Move(ids["A"], "", ids["C"]); err != nil {
+		t.Fatal(err)
+	}
+	if got := pageTitles(s.List(Query{})); got != "B C A" {
+		t.Fatalf("after the move: %s, want B C A", got)
+	}
+	if undo, _ := s.History(); undo != `Moved "A"` {
+		t.Errorf("undo label = %q, want %q", undo, `Moved "A"`)
+	}
+
+	if _, err := s.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if got := pageTitles(s.List(Query{})); got != "A B C" {
+		t.Errorf("after undoing the move: %s, want A B C", got)
+	}
+
+	if _, err := s.Redo(); err != nil {
+		t.Fatal(err)
+	}
+	if got := pageTitles(s.List(Query{})); got != "B C A" {
+		t.Errorf("after redoing the move: %s, want B C A", got)
+	}
+}
+
+func TestMoveTrashed(t *testing.T) {
+	tests := []struct {
+		name string
+		// move moves one of the todos next to the other; trashed is the
+		// one in the trash.
+		move func(s *Store, trashed, live string) error
+	}{
+		{
+			name: "trashed todo",
+			move: func(s *Store, trashed, live string) error {
+				_, _, err := s.Move(trashed, live, "")
+				return err
+			},
+		},
+		{
+			name: "trashed anchor",
+			move: func(s *Store, trashed, live string) error {
+				_, _, err := s.Move(live, "", trashed)
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := openEmpty(t)
+			trashed, _, err := s.Add(Draft{Title: "Gone", Priority: PriorityMedium})
+			if err != nil {
+				t.Fatal(err)
+			}
+			live, _, err := s.Add(Draft{Title: "Here", Priority: PriorityMedium})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.Delete(trashed.ID); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := tt.move(s, trashed.ID, live.ID); !errors.Is(err, ErrNotFound) {
+				t.Errorf("err = %v, want ErrNotFound", err)
+			}
+			if undo, _ := s.History(); undo != `Deleted "Gone"` {
+				t.Errorf("undo label = %q, want the move left unrecorded", undo)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package store
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// ErrInvalidCursor means a page cursor could not be decoded.
+var ErrInvalidCursor = errors.New("invalid page cursor")
+
+// Cursor is a position in the results of a query. It carries the query, so
+// following it keeps the filter, tags, search, sort and list of the first
+// page.
+type Cursor struct {
+	Query Query
+	// After is the id of the last todo on the previous page; the next page
+	// starts behind it even when todos before it were added or removed.
+	After string
+	// Offset counts the todos on previous pages. It places the next page when
+	// After has since left the results.
+	Offset int
+}
+
+// Page is a slice of the results of a query.
+type Page struct {
+	Todos []Todo
+	// Total counts every result of the query, on all pages.
+	Total int
+	// Next continues after this page; it is nil on the last page.
+	Next *Cursor
+}
+
+// cursorToken is the encoded form of a Cursor.
+type cursorToken struct {
+	Filter    Filter   `json:"f,omitempty"`
+	Tags      []string `json:"t,omitempty"`
+	Search    string   `json:"q,omitempty"`
+	Sort      Sort     `json:"s,omitempty"`
+	ProjectID string   `json:"l,omitempty"`
+	After     string   `json:"a,omitempty"`
+	Offset    int      `json:"o,omitempty"`
+}
+
+// String encodes the cursor as an opaque token that is safe in URLs.
+func (c Cursor) String() string {
+	data, _ := json.Marshal(cursorToken{
+		Filter:    c.Query.Filter,
+		Tags:      c.Query.Tags,
+		Search:    c.Query.Search,
+		Sort:      c.Query.Sort,
+		ProjectID: c.Query.ProjectID,
+		After:     c.After,
+		Offset:    c.Offset,
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseCursor decodes a token made by Cursor.String.
+func ParseCursor(token string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(data) == 0 {
+		return Cursor{}, ErrInvalidCursor
+	}
+	var decoded cursorToken
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Offset < 0 {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return Cursor{
+		Query: Query{
+			Filter:    decoded.Filter,
+			Tags:      NormalizeTags(decoded.Tags),
+			Search:    decoded.Search,
+			Sort:      decoded.Sort,
+			ProjectID: decoded.ProjectID,
+		},
+		After:  decoded.After,
+		Offset: decoded.Offset,
+	}, nil
+}
+
+// ListPage returns up to limit todos of the cursor's query, starting at the
+// cursor. A cursor holding only a query starts at the first page; a limit of
+// zero or less returns every remaining todo.
+func (s *Store) ListPage(cursor Cursor, limit int) Page {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	results := s.queryLocked(cursor.Query)
+	start := min(cursor.Offset, len(results))
+	if cursor.After != "" {
+		for i, todo := range results {
+			if todo.ID == cursor.After {
+				start = i + 1
+				break
+			}
+		}
+	}
+	end := len(results)
+	if limit > 0 {
+		end = min(start+limit, end)
+	}
+
+	page := Page{Todos: make([]Todo, 0, end-start), Total: len(results)}
+	for _, todo := range results[start:end] {
+		page.Todos = append(page.Todos, todo.clone())
+	}
+	if end < len(results) {
+		page.Next = &Cursor{Query: cursor.Query, After: results[end-1].ID, Offset: end}
+	}
+	return page
+}
+
+// InView returns the todo with the given id when it is among the results of
+// query, on any page.
+func (s *Store) InView(query Query, id string) (Todo, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, todo := range s.queryLocked(query) {
+		if todo.ID == id {
+			return todo.clone(), true
+		}
+	}
+	return Todo{}, false
+}
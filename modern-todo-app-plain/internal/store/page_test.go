@@ -0,0 +1,116 @@
+package store
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestListPageAcrossChanges(t *testing.T) {
+	tests := []struct {
+		name  string
+		query Query
+		// change runs between the first page and the rest; ids maps titles
+		// to todo ids.
+		change func(t *testing.T, s *Store, ids map[string]string)
+		want   string
+	}{
+		{
+			name: "no change",
+			want: "T3 T2 T1",
+		},
+		{
+			name: "added at the top",
+			change: func(t *testing.T, s *Store, ids map[string]string) {
+				for _, title := range []string{"New 1", "New 2"} {
+					if _, _, err := s.Add(Draft{Title: title, Priority: PriorityMedium}); err != nil {
+						t.Fatal(err)
+					}
+				}
+			},
+			want: "T3 T2 T1",
+		},
+		{
+			name:  "added newest first",
+			query: Query{Sort: SortCreated},
+			change: func(t *testing.T, s *Store, ids map[string]string) {
+				if _, _, err := s.Add(Draft{Title: "New", Priority: PriorityMedium}); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: "T3 T2 T1",
+		},
+		{
+			name: "earlier todo deleted",
+			change: func(t *testing.T, s *Store, ids map[string]string) {
+				if _, err := s.Delete(ids["T5"]); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: "T3 T2 T1",
+		},
+		{
+			name: "earlier todo moved behind the cursor",
+			change: func(t *testing.T, s *Store, ids map[string]string) {
+				if _, _, err := s.Move(ids["T6"], "", ids["T1"]); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: "T3 T2 T1 T6",
+		},
+		{
+			name: "later todo moved ahead of the cursor",
+			change: func(t *testing.T, s *Store, ids map[string]string) {
+				if _, _, err := s.Move(ids["T2"], ids["T6"], ""); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: "T3 T1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := openEmpty(t)
+			ids := map[string]string{}
+			for _, title := range []string{"T1", "T2", "T3", "T4", "T5", "T6"} {
+				todo, _, err := s.Add(Draft{Title: title, Priority: PriorityMedium})
+				if err != nil {
+					t.Fatal(err)
+				}
+				ids[title] = todo.ID
+			}
+
+			page := s.ListPage(Cursor{Query: tt.query}, 3)
+			if got := pageTitles(page.Todos); got != "T6 T5 T4" {
+				t.Fatalf("first page = %s, want T6 T5 T4", got)
+			}
+			if page.Next == nil {
+				t.Fatal("first page has no next cursor")
+			}
+			if tt.change != nil {
+				tt.change(t, s, ids)
+			}
+
+			// Follow the cursors through their encoded form, as the list does.
+			var rest []Todo
+			for next := page.Next; next != nil; next = page.Next {
+				cursor, err := ParseCursor(next.String())
+				if err != nil {
+					t.Fatal(err)
+				}
+				page = s.ListPage(cursor, 2)
+				rest = append(rest, page.Todos...)
+			}
+			if got := pageTitles(rest); got != tt.want {
+				t.Errorf("later pages = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func pageTitles(todos []Todo) string {
+	titles := make([]string, len(todos))
+	for i, todo := range todos {
+		titles[i] = todo.Title
+	}
+	return strings.Join(titles, " ")
+}
@@ -0,0 +1,213 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// InboxProjectID is the list new todos land in when no other list is chosen.
+// It always exists and cannot be deleted.
+const InboxProjectID = "inbox"
+
+// Project is a named list of todos.
+type Project struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+	Icon  string `json:"icon"`
+}
+
+// ProjectSummary is a project together with its todo counts.
+type ProjectSummary struct {
+	Project
+	Stats Stats
+}
+
+// ProjectColors and ProjectIcons are the accepted values for Project.Color
+// and Project.Icon.
+var (
+	ProjectColors = []string{"slate", "red", "orange", "amber", "green", "teal", "blue", "violet", "pink"}
+	ProjectIcons  = []string{"inbox", "list", "briefcase", "house", "shopping-cart", "book", "heart", "star"}
+)
+
+var (
+	ErrInvalidProject = errors.New("list name is required")
+	ErrInboxProject   = errors.New("the inbox cannot be deleted")
+	ErrUnknownProject = errors.New("list does not exist")
+)
+
+func inboxProject() Project {
+	return Project{ID: InboxProjectID, Name: "Inbox", Color: "slate", Icon: "inbox"}
+}
+
+// Projects returns every list in creation order with per-list counts.
+func (s *Store) Projects() []ProjectSummary {
+	s.mu.RLock()
+	projects := append([]Project(nil), s.projects...)
+	s.mu.RUnlock()
+
+	summaries := make([]ProjectSummary, len(projects))
+	for i, project := range projects {
+		summaries[i] = ProjectSummary{Project: project, Stats: s.ProjectStats(project.ID)}
+	}
+	return summaries
+}
+
+func (s *Store) Project(id string) (Project, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	project, err := s.findProjectLocked(id)
+	if err != nil {
+		return Project{}, err
+	}
+	return *project, nil
+}
+
+func (s *Store) AddProject(name, color, icon string) (Project, error) {
+	project, err := normalizeProject(Project{Name: name, Color: color, Icon: icon})
+	if err != nil {
+		return Project{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev := s.snapshotLocked()
+	if s.nextProjectID == 0 {
+		s.nextProjectID = 1
+	}
+	project.ID = strconv.FormatUint(s.nextProjectID, 10)
+	s.nextProjectID++
+	s.projects = append(s.projects, project)
+
+	if _, err := s.commitLocked(prev); err != nil {
+		return Project{}, err
+	}
+	return project, nil
+}
+
+func (s *Store) UpdateProject(id, name, color, icon string) (Project, error) {
+	updated, err := normalizeProject(Project{ID: id, Name: name, Color: color, Icon: icon})
+	if err != nil {
+		return Project{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, err := s.findProjectLocked(id)
+	if err != nil {
+		return Project{}, err
+	}
+
+	prev := s.snapshotLocked()
+	*project = updated
+	if _, err := s.commitLocked(prev); err != nil {
+		return Project{}, err
+	}
+	return updated, nil
+}
+
+// DeleteProject removes a list and moves its todos to the inbox.
+func (s *Store) DeleteProject(id string) error {
+	if id == InboxProjectID {
+		return ErrInboxProject
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, project := range s.projects {
+		if project.ID != id {
+			continue
+		}
+		prev := s.snapshotLocked()
+		s.projects = append(s.projects[:i], s.projects[i+1:]...)
+		for _, todo := range s.todos {
+			if todo.ProjectID == id {
+				todo.ProjectID = InboxProjectID
+			}
+		}
+		_, err := s.commitLocked(prev)
+		return err
+	}
+	return ErrNotFound
+}
+
+func (s *Store) findProjectLocked(id string) (*Project, error) {
+	for i := range s.projects {
+		if s.projects[i].ID == id {
+			return &s.projects[i], nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// resolveProjectLocked defaults an empty project ID to the inbox and checks
+// that the list exists.
+func (s *Store) resolveProjectLocked(id *string) error {
+	if *id == "" {
+		*id = InboxProjectID
+	}
+	if _, err := s.findProjectLocked(*id); err != nil {
+		return ErrUnknownProject
+	}
+	return nil
+}
+
+func normalizeProject(p Project) (Project, error) {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return Project{}, ErrInvalidProject
+	}
+	if !contains(ProjectColors, p.Color) {
+		p.Color = ProjectColors[0]
+	}
+	if !contains(ProjectIcons, p.Icon) {
+		p.Icon = "list"
+	}
+	return p, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// migrateProjects (schema 2 -> 3) creates the inbox and files every existing
+// todo under it.
+func migrateProjects(data json.RawMessage) (json.RawMessage, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	var todos []map[string]any
+	if len(raw["todos"]) > 0 {
+		if err := json.Unmarshal(raw["todos"], &todos); err != nil {
+			return nil, err
+		}
+	}
+	for _, todo := range todos {
+		todo["project_id"] = InboxProjectID
+	}
+
+	encodedTodos, err := json.Marshal(todos)
+	if err != nil {
+		return nil, err
+	}
+	encodedProjects, err := json.Marshal([]Project{inboxProject()})
+	if err != nil {
+		return nil, err
+	}
+	raw["todos"] = encodedTodos
+	raw["projects"] = encodedProjects
+	raw["next_project_id"] = json.RawMessage("1")
+	return json.Marshal(raw)
+}
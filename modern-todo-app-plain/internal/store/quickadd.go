@@ -0,0 +1,285 @@
+package store
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseQuickAdd reads a todo typed on one line, such as
+// "Pay invoice tomorrow 5pm !high #finance", into a draft. It recognises:
+//
+//   - tags written as #name;
+//   - a priority marker: !low, !medium or !high (also !l, !m, !h, !!!);
+//   - a due date: today, tonight (8pm unless a time is given), tomorrow, a
+//     weekday (optionally "next"), "next week", "in 3 days", "in 2 weeks"
+//     or 2024-05-01, optionally preceded by "on", "by" or "due";
+//   - a time of day: 5pm, 5:30pm, 5 pm, 17:00, noon or midnight, optionally
+//     preceded by "at".
+//
+// Short weekdays such as "sat" or "wed" count only after "on", "by", "due" or
+// "next", or at the end of the text, and "in 3 days" only at the end, so
+// titles like "Fix sun shade" or "Put in 3 days of leave" stay whole. Tags,
+// a priority and a time may still follow them.
+//
+// Everything else is the title. Dates and times are read in now's location.
+// A date without a time is due at the end of that day; a time without a date
+// is due today, or tomorrow once that time has passed. Only the first date
+// and time are used; later ones stay in the title.
+func ParseQuickAdd(text string, now time.Time) Draft {
+	draft := Draft{Priority: PriorityMedium}
+	words := strings.Fields(text)
+	used := make([]bool, len(words))
+
+	var (
+		day     time.Time
+		hasDay  bool
+		clock   time.Duration
+		hasTime bool
+		// tonight falls back to 8pm when no time is given.
+		tonight bool
+	)
+	for i := 0; i < len(words); i++ {
+		word := quickWord(words[i])
+
+		if strings.HasPrefix(word, "#") {
+			if tag := NormalizeTag(word); tag != "" {
+				draft.Tags = append(draft.Tags, tag)
+				used[i] = true
+			}
+			continue
+		}
+		if strings.HasPrefix(word, "!") {
+			if priority, ok := priorityMarkers[word]; ok {
+				draft.Priority = priority
+				used[i] = true
+			}
+			continue
+		}
+
+		// A connector is consumed with the date or time that follows it.
+		next := i
+		if quickConnectors[word] {
+			next = i + 1
+		}
+		if next >= len(words) {
+			continue
+		}
+		if !hasDay {
+			led := next > i && word != "at"
+			if d, n, ok := parseQuickDay(words[next:], now, led); ok {
+				day, hasDay = d, true
+				tonight = quickWord(words[next]) == "tonight"
+				markUsed(used, i, next+n)
+				i = next + n - 1
+				continue
+			}
+		}
+		if !hasTime {
+			if c, n, ok := parseQuickTime(words[next:]); ok {
+				clock, hasTime = c, true
+				markUsed(used, i, next+n)
+				i = next + n - 1
+			}
+		}
+	}
+
+	var title []string
+	for i, word := range words {
+		if !used[i] {
+			title = append(title, word)
+		}
+	}
+	draft.Title = strings.Join(title, " ")
+	draft.Tags = NormalizeTags(draft.Tags)
+
+	if !hasDay && !hasTime {
+		return draft
+	}
+	if tonight && !hasTime {
+		clock, hasTime = 20*time.Hour, true
+	}
+	if !hasTime {
+		clock = 23*time.Hour + 59*time.Minute
+	}
+	// Build the wall-clock time directly so days with a DST change keep it.
+	hour, minute := int(clock/time.Hour), int(clock%time.Hour/time.Minute)
+	at := func(day time.Time) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, now.Location())
+	}
+	if !hasDay {
+		day = startOfDay(now)
+		if !at(day).After(now) {
+			day = day.AddDate(0, 0, 1)
+		}
+	}
+	due := at(day)
+	draft.DueAt = &due
+	draft.DueZone = now.Location().String()
+	return draft
+}
+
+var priorityMarkers = map[string]Priority{
+	"!low":    PriorityLow,
+	"!l":      PriorityLow,
+	"!medium": PriorityMedium,
+	"!med":    PriorityMedium,
+	"!m":      PriorityMedium,
+	"!high":   PriorityHigh,
+	"!h":      PriorityHigh,
+	"!urgent": PriorityHigh,
+	"!!!":     PriorityHigh,
+}
+
+// quickConnectors are words dropped along with the date or time they lead.
+var quickConnectors = map[string]bool{"on": true, "by": true, "due": true, "at": true}
+
+var quickWeekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+// parseQuickDay reads a date at the start of words and returns the day and
+// the number of words it took. led reports whether "on", "by" or "due" came
+// before words; ambiguous forms need one, or to end the text.
+func parseQuickDay(words []string, now time.Time, led bool) (time.Time, int, bool) {
+	at := func(i int) string {
+		if i >= len(words) {
+			return ""
+		}
+		return quickWord(words[i])
+	}
+	today := startOfDay(now)
+
+	switch word := at(0); word {
+	case "today", "tonight":
+		return today, 1, true
+	case "tomorrow", "tmr", "tmrw":
+		return today.AddDate(0, 0, 1), 1, true
+	case "next":
+		if at(1) == "week" {
+			return today.AddDate(0, 0, 7), 2, true
+		}
+		if weekday, ok := quickWeekdays[at(1)]; ok {
+			return nextWeekday(today, weekday, 1), 2, true
+		}
+	case "in":
+		n, err := strconv.Atoi(at(1))
+		if err != nil || n < 0 || n > 365 || !quickTrailing(words[min(3, len(words)):]) {
+			break
+		}
+		switch at(2) {
+		case "day", "days":
+			return today.AddDate(0, 0, n), 3, true
+		case "week", "weeks":
+			return today.AddDate(0, 0, 7*n), 3, true
+		}
+	default:
+		weekday, ok := quickWeekdays[word]
+		short := word != strings.ToLower(weekday.String())
+		if ok && (!short || led || quickTrailing(words[1:])) {
+			return nextWeekday(today, weekday, 0), 1, true
+		}
+		if day, err := time.ParseInLocation("2006-01-02", word, now.Location()); err == nil {
+			return day, 1, true
+		}
+	}
+	return time.Time{}, 0, false
+}
+
+// quickTrailing reports whether words hold nothing but tags, priority markers
+// and times, which may follow a date at the end of the text.
+func quickTrailing(words []string) bool {
+	for i := 0; i < len(words); i++ {
+		word := quickWord(words[i])
+		if strings.HasPrefix(word, "#") || strings.HasPrefix(word, "!") || word == "at" {
+			continue
+		}
+		_, n, ok := parseQuickTime(words[i:])
+		if !ok {
+			return false
+		}
+		i += n - 1
+	}
+	return true
+}
+
+// parseQuickTime reads a time of day at the start of words and returns it as
+// an offset from midnight, with the number of words it took.
+func parseQuickTime(words []string) (time.Duration, int, bool) {
+	word := quickWord(words[0])
+	switch word {
+	case "noon":
+		return 12 * time.Hour, 1, true
+	case "midnight":
+		return 0, 1, true
+	}
+
+	n := 1
+	suffix := ""
+	switch {
+	case strings.HasSuffix(word, "am"), strings.HasSuffix(word, "pm"):
+		word, suffix = word[:len(word)-2], word[len(word)-2:]
+	case len(words) > 1:
+		if next := quickWord(words[1]); next == "am" || next == "pm" {
+			suffix, n = next, 2
+		}
+	}
+
+	hourText, minuteText, hasMinutes := strings.Cut(word, ":")
+	if !hasMinutes && suffix == "" {
+		// A bare number is part of the title, not a time.
+		return 0, 0, false
+	}
+	hour, err := strconv.Atoi(hourText)
+	if err != nil || hour < 0 || hour > 23 {
+		return 0, 0, false
+	}
+	minute := 0
+	if hasMinutes {
+		if len(minuteText) != 2 {
+			return 0, 0, false
+		}
+		if minute, err = strconv.Atoi(minuteText); err != nil || minute > 59 {
+			return 0, 0, false
+		}
+	}
+	if suffix != "" {
+		if hour < 1 || hour > 12 {
+			return 0, 0, false
+		}
+		hour %= 12
+		if suffix == "pm" {
+			hour += 12
+		}
+	}
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute, n, true
+}
+
+// nextWeekday returns the first day on or after today+minDays that falls on
+// weekday.
+func nextWeekday(today time.Time, weekday time.Weekday, minDays int) time.Time {
+	day := today.AddDate(0, 0, minDays)
+	return day.AddDate(0, 0, (int(weekday)-int(day.Weekday())+7)%7)
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// quickWord lowercases a word and drops trailing punctuation.
+func quickWord(word string) string {
+	return strings.ToLower(strings.TrimRight(word, ",.;"))
+}
+
+func markUsed(used []bool, from, to int) {
+	for i := from; i < to; i++ {
+		used[i] = true
+	}
+}
@@ -0,0 +1,90 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseQuickAdd(t *testing.T) {
+	zone, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no time zone data:", err)
+	}
+	// Wednesday, 10:00.
+	now := time.Date(2026, time.October, 14, 10, 0, 0, 0, zone)
+	due := func(month time.Month, day, hour, minute int) *time.Time {
+		at := time.Date(2026, month, day, hour, minute, 0, 0, zone)
+		return &at
+	}
+
+	tests := []struct {
+		text     string
+		title    string
+		due      *time.Time
+		priority Priority
+		tags     []string
+	}{
+		{text: "Buy milk", title: "Buy milk"},
+		{text: "Pay invoice tomorrow 5pm !high #finance", title: "Pay invoice", due: due(time.October, 15, 17, 0), priority: PriorityHigh, tags: []string{"finance"}},
+		{text: "Bake cake tonight", title: "Bake cake", due: due(time.October, 14, 20, 0)},
+		{text: "Call mom tonight 9pm", title: "Call mom", due: due(time.October, 14, 21, 0)},
+		{text: "Call mom at 9:30pm tonight", title: "Call mom", due: due(time.October, 14, 21, 30)},
+		{text: "Lunch at noon", title: "Lunch", due: due(time.October, 14, 12, 0)},
+		{text: "Standup 9am", title: "Standup", due: due(time.October, 15, 9, 0)},
+		{text: "Call mom at 5:30 pm", title: "Call mom", due: due(time.October, 14, 17, 30)},
+		{text: "Launch 2026-11-01", title: "Launch", due: due(time.November, 1, 23, 59)},
+		{text: "Report friday", title: "Report", due: due(time.October, 16, 23, 59)},
+		{text: "Review wednesday 3pm", title: "Review", due: due(time.October, 14, 15, 0)},
+		{text: "Sprint planning next week", title: "Sprint planning", due: due(time.October, 21, 23, 59)},
+
+		// Short weekdays need a connector or the end of the text.
+		{text: "Call mom sun", title: "Call mom", due: due(time.October, 18, 23, 59)},
+		{text: "Call mom on sat", title: "Call mom", due: due(time.October, 17, 23, 59)},
+		{text: "Pay rent by fri 5pm #home", title: "Pay rent", due: due(time.October, 16, 17, 0), tags: []string{"home"}},
+		{text: "Pay rent next mon", title: "Pay rent", due: due(time.October, 19, 23, 59)},
+		{text: "Demo wed !high", title: "Demo", due: due(time.October, 14, 23, 59), priority: PriorityHigh},
+		{text: "Fix sun shade", title: "Fix sun shade"},
+		{text: "Book the sat exam", title: "Book the sat exam"},
+		{text: "Plan wed reception", title: "Plan wed reception"},
+
+		// "in N days" only ends the text.
+		{text: "Submit report in 3 days", title: "Submit report", due: due(time.October, 17, 23, 59)},
+		{text: "Renew passport in 2 weeks #admin", title: "Renew passport", due: due(time.October, 28, 23, 59), tags: []string{"admin"}},
+		{text: "Put in 3 days of leave", title: "Put in 3 days of leave"},
+
+		// Only the first date is used.
+		{text: "Move meeting from monday to friday", title: "Move meeting from to friday", due: due(time.October, 19, 23, 59)},
+		{text: "Read 3 chapters", title: "Read 3 chapters"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			draft := ParseQuickAdd(tt.text, now)
+			if draft.Title != tt.title {
+				t.Errorf("title = %q, want %q", draft.Title, tt.title)
+			}
+			switch {
+			case tt.due == nil && draft.DueAt != nil:
+				t.Errorf("due = %v, want none", draft.DueAt)
+			case tt.due != nil && (draft.DueAt == nil || !draft.DueAt.Equal(*tt.due)):
+				t.Errorf("due = %v, want %v", draft.DueAt, tt.due)
+			case tt.due != nil && draft.DueZone != "America/New_York":
+				t.Errorf("due zone = %q, want America/New_York", draft.DueZone)
+			}
+			priority := tt.priority
+			if priority == "" {
+				priority = PriorityMedium
+			}
+			if draft.Priority != priority {
+				t.Errorf("priority = %q, want %q", draft.Priority, priority)
+			}
+			if len(draft.Tags) != len(tt.tags) {
+				t.Fatalf("tags = %v, want %v", draft.Tags, tt.tags)
+			}
+			for i := range tt.tags {
+				if draft.Tags[i] != tt.tags[i] {
+					t.Errorf("tags = %v, want %v", draft.Tags, tt.tags)
+				}
+			}
+		})
+	}
+}
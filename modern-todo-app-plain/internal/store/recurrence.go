@@ -0,0 +1,396 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is the RRULE FREQ part.
+type Frequency string
+
+const (
+	FreqDaily   Frequency = "DAILY"
+	FreqWeekly  Frequency = "WEEKLY"
+	FreqMonthly Frequency = "MONTHLY"
+	FreqYearly  Frequency = "YEARLY"
+)
+
+// Recurrence is the supported subset of an RFC 5545 RRULE: FREQ, INTERVAL,
+// BYDAY (weekly rules), BYMONTHDAY (monthly rules), COUNT and UNTIL. Weeks
+// start on Monday.
+type Recurrence struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay []int
+	// Count is the number of occurrences left, including the current one.
+	// Zero means unlimited.
+	Count int
+	Until time.Time
+}
+
+var ErrInvalidRecurrence = errors.New("invalid recurrence rule")
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// ParseRecurrence parses an RRULE such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH".
+// A leading "RRULE:" is accepted.
+func ParseRecurrence(rule string) (Recurrence, error) {
+	rule = strings.TrimSpace(rule)
+	rule = strings.TrimPrefix(strings.ToUpper(rule), "RRULE:")
+
+	r := Recurrence{Interval: 1}
+	for _, part := range strings.Split(rule, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return Recurrence{}, fmt.Errorf("%w: %q is not KEY=VALUE", ErrInvalidRecurrence, part)
+		}
+
+		switch key {
+		case "FREQ":
+			switch Frequency(value) {
+			case FreqDaily, FreqWeekly, FreqMonthly, FreqYearly:
+				r.Freq = Frequency(value)
+			default:
+				return Recurrence{}, fmt.Errorf("%w: unsupported FREQ %q", ErrInvalidRecurrence, value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return Recurrence{}, fmt.Errorf("%w: INTERVAL must be a positive number", ErrInvalidRecurrence)
+			}
+			r.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return Recurrence{}, fmt.Errorf("%w: COUNT must be a positive number", ErrInvalidRecurrence)
+			}
+			r.Count = n
+		case "UNTIL":
+			until, err := parseUntil(value)
+			if err != nil {
+				return Recurrence{}, fmt.Errorf("%w: UNTIL must look like 20250131 or 20250131T170000Z", ErrInvalidRecurrence)
+			}
+			r.Until = until
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				day, ok := weekdayCodes[code]
+				if !ok {
+					return Recurrence{}, fmt.Errorf("%w: unsupported BYDAY %q", ErrInvalidRecurrence, code)
+				}
+				r.ByDay = append(r.ByDay, day)
+			}
+		case "BYMONTHDAY":
+			for _, raw := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(raw)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return Recurrence{}, fmt.Errorf("%w: BYMONTHDAY %q out of range", ErrInvalidRecurrence, raw)
+				}
+				r.ByMonthDay = append(r.ByMonthDay, n)
+			}
+		case "WKST":
+			if value != "MO" {
+				return Recurrence{}, fmt.Errorf("%w: only WKST=MO is supported", ErrInvalidRecurrence)
+			}
+		default:
+			return Recurrence{}, fmt.Errorf("%w: unsupported part %q", ErrInvalidRecurrence, key)
+		}
+	}
+
+	if r.Freq == "" {
+		return Recurrence{}, fmt.Errorf("%w: FREQ is required", ErrInvalidRecurrence)
+	}
+	if len(r.ByDay) > 0 && r.Freq != FreqWeekly {
+		return Recurrence{}, fmt.Errorf("%w: BYDAY is only supported with FREQ=WEEKLY", ErrInvalidRecurrence)
+	}
+	if len(r.ByMonthDay) > 0 && r.Freq != FreqMonthly {
+		return Recurrence{}, fmt.Errorf("%w: BYMONTHDAY is only supported with FREQ=MONTHLY", ErrInvalidRecurrence)
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return Recurrence{}, fmt.Errorf("%w: COUNT and UNTIL cannot be combined", ErrInvalidRecurrence)
+	}
+
+	sort.Slice(r.ByDay, func(i, j int) bool { return mondayIndex(r.ByDay[i]) < mondayIndex(r.ByDay[j]) })
+	sort.Ints(r.ByMonthDay)
+	return r, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("20060102", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// A date-only UNTIL includes the whole day.
+	return t.Add(24*time.Hour - time.Second), nil
+}
+
+// String returns the canonical RRULE text.
+func (r Recurrence) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			codes[i] = strings.ToUpper(day.String()[:2])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, day := range r.ByMonthDay {
+			days[i] = strconv.Itoa(day)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, fmt.Sprintf("COUNT=%d", r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Describe returns a short human readable summary such as
+// "Every 2 weeks on Mon, Thu".
+func (r Recurrence) Describe() string {
+	units := map[Frequency]string{FreqDaily: "day", FreqWeekly: "week", FreqMonthly: "month", FreqYearly: "year"}
+	text := "Every " + units[r.Freq]
+	if r.Interval > 1 {
+		text = fmt.Sprintf("Every %d %ss", r.Interval, units[r.Freq])
+	}
+
+	if len(r.ByDay) > 0 {
+		names := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			names[i] = day.String()[:3]
+		}
+		text += " on " + strings.Join(names, ", ")
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, day := range r.ByMonthDay {
+			switch {
+			case day > 0:
+				days[i] = fmt.Sprintf("day %d", day)
+			case day == -1:
+				days[i] = "the last day"
+			default:
+				days[i] = fmt.Sprintf("%d days before the end", -day-1)
+			}
+		}
+		text += " on " + strings.Join(days, ", ")
+	}
+	if r.Count > 0 {
+		text += fmt.Sprintf(", %d left", r.Count)
+	}
+	if !r.Until.IsZero() {
+		text += ", until " + r.Until.Format("Jan 02, 2006")
+	}
+	return text
+}
+
+// Next returns the first occurrence strictly after after, keeping its wall
+// clock time. ok is false once the rule has run out.
+func (r Recurrence) Next(after time.Time) (next time.Time, ok bool) {
+	if r.Count == 1 {
+		return time.Time{}, false
+	}
+
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	switch r.Freq {
+	case FreqDaily:
+		next = after.AddDate(0, 0, interval)
+	case FreqWeekly:
+		next = r.nextWeekly(after, interval)
+	case FreqMonthly:
+		next = r.nextMonthly(after, interval)
+	case FreqYearly:
+		next = addMonthsClamped(after, 12*interval)
+	default:
+		return time.Time{}, false
+	}
+
+	if !r.Until.IsZero() && next.After(r.Until) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// Advance returns the rule for the occurrence after this one, consuming one
+// COUNT.
+func (r Recurrence) Advance() Recurrence {
+	if r.Count > 0 {
+		r.Count--
+	}
+	return r
+}
+
+func (r Recurrence) nextWeekly(after time.Time, interval int) time.Time {
+	if len(r.ByDay) == 0 {
+		return after.AddDate(0, 0, 7*interval)
+	}
+
+	startOfWeek := after.AddDate(0, 0, -mondayIndex(after.Weekday()))
+	for offset := 1; offset <= 7*interval+7; offset++ {
+		candidate := after.AddDate(0, 0, offset)
+		weeks := daysBetween(startOfWeek, candidate) / 7
+		if weeks%interval != 0 {
+			continue
+		}
+		for _, day := range r.ByDay {
+			if candidate.Weekday() == day {
+				return candidate
+			}
+		}
+	}
+	return after.AddDate(0, 0, 7*interval)
+}
+
+func (r Recurrence) nextMonthly(after time.Time, interval int) time.Time {
+	if len(r.ByMonthDay) == 0 {
+		return addMonthsClamped(after, interval)
+	}
+
+	y, m, _ := after.Date()
+	hour, minute, sec := after.Clock()
+	// Look far enough ahead to find a month containing every requested day.
+	for months := 0; months <= 12*interval; months += interval {
+		first := time.Date(y, m+time.Month(months), 1, hour, minute, sec, 0, after.Location())
+		last := first.AddDate(0, 1, -1).Day()
+		// Days counted from the end of the month sort before the others, so
+		// take the earliest match rather than the first.
+		var next time.Time
+		for _, day := range r.ByMonthDay {
+			if day < 0 {
+				day = last + day + 1
+			}
+			if day < 1 || day > last {
+				continue
+			}
+			candidate := first.AddDate(0, 0, day-1)
+			if candidate.After(after) && (next.IsZero() || candidate.Before(next)) {
+				next = candidate
+			}
+		}
+		if !next.IsZero() {
+			return next
+		}
+	}
+	return addMonthsClamped(after, interval)
+}
+
+// addMonthsClamped adds months, clamping to the last day of the target month
+// instead of overflowing (Jan 31 + 1 month is Feb 28/29).
+func addMonthsClamped(t time.Time, months int) time.Time {
+	y, m, d := t.Date()
+	hour, minute, sec := t.Clock()
+	first := time.Date(y, m+time.Month(months), 1, hour, minute, sec, t.Nanosecond(), t.Location())
+	if last := first.AddDate(0, 1, -1).Day(); d > last {
+		d = last
+	}
+	return first.AddDate(0, 0, d-1)
+}
+
+func mondayIndex(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
+
+func daysBetween(from, to time.Time) int {
+	fy, fm, fd := from.Date()
+	ty, tm, td := to.Date()
+	start := time.Date(fy, fm, fd, 0, 0, 0, 0, time.UTC)
+	end := time.Date(ty, tm, td, 0, 0, 0, 0, time.UTC)
+	return int(end.Sub(start).Hours() / 24)
+}
+
+// normalizeRecurrence validates rule and returns its canonical form. An empty
+// rule means the todo does not repeat.
+func normalizeRecurrence(rule string) (string, error) {
+	if strings.TrimSpace(rule) == "" {
+		return "", nil
+	}
+	r, err := ParseRecurrence(rule)
+	if err != nil {
+		return "", err
+	}
+	return r.String(), nil
+}
+
+// Repeat returns the todo's parsed recurrence rule, if any.
+func (t Todo) Repeat() (Recurrence, bool) {
+	if t.Recurrence == "" {
+		return Recurrence{}, false
+	}
+	r, err := ParseRecurrence(t.Recurrence)
+	if err != nil {
+		return Recurrence{}, false
+	}
+	return r, true
+}
+
+// spawnNextLocked creates the following occurrence of a recurring todo that
+// has just been completed. It does nothing if the rule has run out or the next
+// occurrence already exists.
+func (s *Store) spawnNextLocked(todo *Todo, now time.Time) {
+	rule, ok := todo.Repeat()
+	if !ok {
+		return
+	}
+	if todo.NextOccurrenceID != "" {
+		if _, err := s.findLocked(todo.NextOccurrenceID); err == nil {
+			return
+		}
+	}
+
+	base := now.In(todo.location())
+	if due, ok := todo.DueLocal(); ok {
+		base = due
+	}
+	nextDue, ok := rule.Next(base)
+	if !ok {
+		return
+	}
+
+	next := todo.clone()
+	next.ID = generateID(s.nextID)
+	next.Completed = false
+	next.CreatedAt = now.UTC()
+	next.Recurrence = rule.Advance().String()
+	next.NextOccurrenceID = ""
+	next.DeletedAt = nil
+	next.setDue(&nextDue, todo.DueZone)
+	next.Position = todo.Position
+	for i := range next.Subtasks {
+		next.Subtasks[i].Done = false
+	}
+
+	s.nextID++
+	todo.NextOccurrenceID = next.ID
+	s.todos = append(s.todos, &next)
+	s.sortLocked()
+}
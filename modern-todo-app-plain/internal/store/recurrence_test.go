@@ -0,0 +1,105 @@
+package store
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRecurrenceNext(t *testing.T) {
+	zone := newYork(t)
+	at := func(year int, month time.Month, day, hour int) time.Time {
+		return time.Date(year, month, day, hour, 0, 0, 0, zone)
+	}
+	wednesday := at(2026, time.October, 14, 9)
+
+	tests := []struct {
+		rule   string
+		after  time.Time
+		want   time.Time
+		wantOK bool
+	}{
+		{"FREQ=DAILY", wednesday, at(2026, time.October, 15, 9), true},
+		{"FREQ=DAILY;INTERVAL=3", wednesday, at(2026, time.October, 17, 9), true},
+		{"FREQ=WEEKLY", wednesday, at(2026, time.October, 21, 9), true},
+		{"FREQ=WEEKLY;BYDAY=MO,FR", wednesday, at(2026, time.October, 16, 9), true},
+		{"FREQ=WEEKLY;BYDAY=MO", wednesday, at(2026, time.October, 19, 9), true},
+		{"FREQ=WEEKLY;BYDAY=WE", wednesday, at(2026, time.October, 21, 9), true},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE", wednesday, at(2026, time.October, 26, 9), true},
+		{"FREQ=MONTHLY", wednesday, at(2026, time.November, 14, 9), true},
+		{"FREQ=MONTHLY;BYMONTHDAY=1,15", wednesday, at(2026, time.October, 15, 9), true},
+		{"FREQ=MONTHLY;BYMONTHDAY=-1", wednesday, at(2026, time.October, 31, 9), true},
+		{"FREQ=MONTHLY;BYMONTHDAY=-1,15", wednesday, at(2026, time.October, 15, 9), true},
+		{"FREQ=MONTHLY;BYMONTHDAY=31", at(2026, time.October, 31, 9), at(2026, time.December, 31, 9), true},
+		{"FREQ=MONTHLY", at(2026, time.January, 31, 9), at(2026, time.February, 28, 9), true},
+		{"FREQ=YEARLY", at(2028, time.February, 29, 9), at(2029, time.February, 28, 9), true},
+		// The wall clock time survives the end of daylight saving time.
+		{"FREQ=DAILY", at(2026, time.October, 31, 9), at(2026, time.November, 1, 9), true},
+		{"FREQ=DAILY;COUNT=3", wednesday, at(2026, time.October, 15, 9), true},
+		{"FREQ=DAILY;COUNT=1", wednesday, time.Time{}, false},
+		{"FREQ=DAILY;UNTIL=20261015", wednesday, at(2026, time.October, 15, 9), true},
+		{"FREQ=DAILY;UNTIL=20261014", wednesday, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule+"/"+tt.after.Format("2006-01-02"), func(t *testing.T) {
+			rule, err := ParseRecurrence(tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := rule.Next(tt.after)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, %t; want %v, %t", tt.after, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestTrashedRecurringTodo(t *testing.T) {
+	s := openEmpty(t)
+	due := time.Date(2026, time.October, 14, 9, 0, 0, 0, time.UTC)
+	todo, _, err := s.Add(Draft{Title: "Water the plants", Priority: PriorityMedium, DueAt: &due, Recurrence: "FREQ=DAILY"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Delete(todo.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// A trashed todo has to be restored before it can change.
+	completed := true
+	edits := map[string]func() error{
+		"Toggle": func() error { _, _, err := s.Toggle(todo.ID); return err },
+		"Update": func() error {
+			_, _, err := s.Update(todo.ID, Draft{Title: "Renamed", Priority: PriorityLow})
+			return err
+		},
+		"Patch": func() error { _, _, err := s.Patch(todo.ID, Patch{Completed: &completed}); return err },
+	}
+	for name, edit := range edits {
+		if err := edit(); !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s of a trashed todo: err = %v, want ErrNotFound", name, err)
+		}
+	}
+	if got := s.List(Query{Filter: FilterAll}); len(got) != 0 {
+		t.Errorf("editing the trashed todo spawned %q", pageTitles(got))
+	}
+
+	// Once restored, completing it starts the next occurrence outside the
+	// trash.
+	if _, _, err := s.Restore(todo.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.Patch(todo.ID, Patch{Completed: &completed}); err != nil {
+		t.Fatal(err)
+	}
+	live := s.List(Query{Filter: FilterActive})
+	if len(live) != 1 || live[0].ID == todo.ID {
+		t.Fatalf("active todos = %q, want the next occurrence", pageTitles(live))
+	}
+	if want := due.AddDate(0, 0, 1); live[0].DueAt == nil || !live[0].DueAt.Equal(want) {
+		t.Errorf("next occurrence due %v, want %v", live[0].DueAt, want)
+	}
+	if got := s.List(Query{Filter: FilterTrash}); len(got) != 0 {
+		t.Errorf("trash holds %q, want it empty", pageTitles(got))
+	}
+}
@@ -0,0 +1,77 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// MaxRemindMinutes is the earliest a reminder may go off: a week before the
+// due date.
+const MaxRemindMinutes = 7 * 24 * 60
+
+// RemindAt returns when the todo's reminder goes off, if it has one.
+func (t Todo) RemindAt() (time.Time, bool) {
+	if t.DueAt == nil || t.RemindMinutes == nil {
+		return time.Time{}, false
+	}
+	return t.DueAt.Add(-time.Duration(*t.RemindMinutes) * time.Minute), true
+}
+
+// ReminderDue reports whether an open todo's reminder has gone off by now.
+// It stays due until the todo is completed or the reminder is dismissed.
+func (t Todo) ReminderDue(now time.Time) bool {
+	at, ok := t.RemindAt()
+	return ok && !t.Completed && !t.Trashed() && !at.After(now)
+}
+
+// Reminders returns the todos in every list whose reminders have gone off by
+// now, the earliest reminder first.
+func (s *Store) Reminders(now time.Time) []Todo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var todos []Todo
+	for _, todo := range s.todos {
+		if todo.ReminderDue(now) {
+			todos = append(todos, todo.clone())
+		}
+	}
+	sort.SliceStable(todos, func(i, j int) bool {
+		a, _ := todos[i].RemindAt()
+		b, _ := todos[j].RemindAt()
+		return a.Before(b)
+	})
+	return todos
+}
+
+// DescribeRemind names a reminder offset, such as "1 hour before" or "at the
+// due time".
+func DescribeRemind(minutes int) string {
+	unit := func(n int, name string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s before", name)
+		}
+		return fmt.Sprintf("%d %ss before", n, name)
+	}
+	switch {
+	case minutes == 0:
+		return "at the due time"
+	case minutes%(24*60) == 0:
+		return unit(minutes/(24*60), "day")
+	case minutes%60 == 0:
+		return unit(minutes/60, "hour")
+	default:
+		return unit(minutes, "minute")
+	}
+}
+
+// setRemind sets the reminder, which needs a due date to count back from.
+func (t *Todo) setRemind(minutes *int) {
+	if minutes == nil || t.DueAt == nil {
+		t.RemindMinutes = nil
+		return
+	}
+	m := *minutes
+	t.RemindMinutes = &m
+}
@@ -0,0 +1,182 @@
+package store
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Relevance weights for where a search term matched.
+const (
+	weightTitle       = 3
+	weightTag         = 2
+	weightDescription = 1
+	// exactMatchBonus multiplies the weight when a term matches a whole word
+	// rather than just its prefix.
+	exactMatchBonus = 2
+)
+
+// Tokenize splits text into lowercase words made of letters and digits.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// searchIndex is an inverted index from word to the todos containing it,
+// with a sorted word list for prefix lookups.
+type searchIndex struct {
+	postings map[string]map[string]int
+	words    []string
+}
+
+// buildSearchIndex indexes every todo. It runs when the todos are loaded;
+// commits then keep the index current with update.
+func buildSearchIndex(todos []*Todo) *searchIndex {
+	idx := &searchIndex{postings: map[string]map[string]int{}}
+	for _, todo := range todos {
+		idx.addTodo(todo, nil)
+	}
+
+	idx.words = make([]string, 0, len(idx.postings))
+	for word := range idx.postings {
+		idx.words = append(idx.words, word)
+	}
+	sort.Strings(idx.words)
+	return idx
+}
+
+// update reindexes the todos in changes and leaves the others alone.
+func (idx *searchIndex) update(changes []todoChange) {
+	// touched collects the words that gained or lost their last posting.
+	touched := map[string]bool{}
+	for _, change := range changes {
+		if change.before != nil {
+			idx.removeTodo(change.before, touched)
+		}
+		if change.after != nil {
+			idx.addTodo(change.after, touched)
+		}
+	}
+	if len(touched) == 0 {
+		return
+	}
+
+	var fresh []string
+	for word := range touched {
+		if _, ok := idx.postings[word]; ok {
+			fresh = append(fresh, word)
+		}
+	}
+	sort.Strings(fresh)
+	words := make([]string, 0, len(idx.postings))
+	for _, word := range idx.words {
+		if touched[word] {
+			continue
+		}
+		for len(fresh) > 0 && fresh[0] < word {
+			words = append(words, fresh[0])
+			fresh = fresh[1:]
+		}
+		words = append(words, word)
+	}
+	idx.words = append(words, fresh...)
+}
+
+// addTodo indexes a todo's text, recording new words in touched if it is
+// not nil.
+func (idx *searchIndex) addTodo(todo *Todo, touched map[string]bool) {
+	add := func(text string, weight int) {
+		for _, word := range Tokenize(text) {
+			docs, ok := idx.postings[word]
+			if !ok {
+				docs = map[string]int{}
+				idx.postings[word] = docs
+				if touched != nil {
+					touched[word] = true
+				}
+			}
+			docs[todo.ID] += weight
+		}
+	}
+	add(todo.Title, weightTitle)
+	add(todo.Description, weightDescription)
+	add(strings.Join(todo.Tags, " "), weightTag)
+	for _, sub := range todo.Subtasks {
+		add(sub.Title, weightDescription)
+	}
+}
+
+// removeTodo drops the postings addTodo made for todo, recording in touched
+// the words no other todo contains.
+func (idx *searchIndex) removeTodo(todo *Todo, touched map[string]bool) {
+	texts := []string{todo.Title, todo.Description, strings.Join(todo.Tags, " ")}
+	for _, sub := range todo.Subtasks {
+		texts = append(texts, sub.Title)
+	}
+	for _, text := range texts {
+		for _, word := range Tokenize(text) {
+			docs, ok := idx.postings[word]
+			if !ok {
+				continue
+			}
+			delete(docs, todo.ID)
+			if len(docs) == 0 {
+				delete(idx.postings, word)
+				touched[word] = true
+			}
+		}
+	}
+}
+
+// score ranks todos against the query text. Every query term must match a
+// word by prefix; todos missing a term are left out of the result.
+func (idx *searchIndex) score(text string) map[string]int {
+	var scores map[string]int
+	for _, term := range uniqueTokens(text) {
+		termScores := map[string]int{}
+		start := sort.SearchStrings(idx.words, term)
+		for _, word := range idx.words[start:] {
+			if !strings.HasPrefix(word, term) {
+				break
+			}
+			bonus := 1
+			if word == term {
+				bonus = exactMatchBonus
+			}
+			for id, weight := range idx.postings[word] {
+				termScores[id] += weight * bonus
+			}
+		}
+
+		if scores == nil {
+			scores = termScores
+			continue
+		}
+		for id := range scores {
+			if extra, ok := termScores[id]; ok {
+				scores[id] += extra
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+	return scores
+}
+
+func uniqueTokens(text string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, token := range Tokenize(text) {
+		if !seen[token] {
+			seen[token] = true
+			out = append(out, token)
+		}
+	}
+	return out
+}
+
+// Search returns todos matching text across all filters, most relevant first.
+func (s *Store) Search(text string) []Todo {
+	return s.List(Query{Filter: FilterAll, Search: text})
+}
@@ -0,0 +1,70 @@
+package store
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSearchIndexFollowsCommits(t *testing.T) {
+	s := openEmpty(t)
+	add := func(draft Draft) Todo {
+		t.Helper()
+		draft.Priority = PriorityMedium
+		todo, _, err := s.Add(draft)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return todo
+	}
+	search := func(text string) string {
+		t.Helper()
+		return pageTitles(s.Search(text))
+	}
+
+	invoice := add(Draft{Title: "Pay invoice", Tags: []string{"finance"}})
+	plants := add(Draft{Title: "Water plants", Description: "Fern and cactus"})
+	if got := search("invoice"); got != "Pay invoice" {
+		t.Errorf("search invoice = %q, want the new todo", got)
+	}
+
+	if _, _, err := s.Update(invoice.ID, Draft{Title: "Pay rent", Priority: PriorityHigh, Tags: []string{"home"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := search("invoice"); got != "" {
+		t.Errorf("search invoice after renaming = %q, want nothing", got)
+	}
+	if got := search("finance"); got != "" {
+		t.Errorf("search finance after retagging = %q, want nothing", got)
+	}
+	if got := search("rent home"); got != "Pay rent" {
+		t.Errorf("search rent home = %q, want the renamed todo", got)
+	}
+
+	if _, _, err := s.AddSubtask(plants.ID, "Repot the cactus"); err != nil {
+		t.Fatal(err)
+	}
+	if got := search("repot"); got != "Water plants" {
+		t.Errorf("search repot = %q, want the todo with that subtask", got)
+	}
+
+	if _, err := s.Delete(plants.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Purge(plants.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Undo(); err != nil {
+		t.Fatal(err)
+	}
+
+	// However it got here, the index matches one built from scratch.
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	want := buildSearchIndex(s.todos)
+	if !reflect.DeepEqual(s.index.postings, want.postings) {
+		t.Errorf("postings = %v, want %v", s.index.postings, want.postings)
+	}
+	if !reflect.DeepEqual(s.index.words, want.words) {
+		t.Errorf("words = %q, want %q", s.index.words, want.words)
+	}
+}
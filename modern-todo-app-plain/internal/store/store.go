@@ -3,6 +3,7 @@ package store
 import (
 	"errors"
 	"fmt"
+	"reflect"
 	"sort"
 	"strings"
 	"sync"
@@ -17,41 +18,224 @@ const (
 	PriorityHigh   Priority = "high"
 )
 
+// Valid reports whether p is one of the known priorities.
+func (p Priority) Valid() bool {
+	switch p {
+	case PriorityLow, PriorityMedium, PriorityHigh:
+		return true
+	}
+	return false
+}
+
 type Filter string
 
 const (
 	FilterAll       Filter = "all"
 	FilterActive    Filter = "active"
 	FilterCompleted Filter = "completed"
+	FilterOverdue   Filter = "overdue"
+	FilterThisWeek  Filter = "week"
+	// FilterTrash lists deleted todos; every other filter hides them.
+	FilterTrash Filter = "trash"
 )
 
 type Todo struct {
-	ID          string
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description,omitempty"`
+	Completed   bool      `json:"completed"`
+	Priority    Priority  `json:"priority"`
+	CreatedAt   time.Time `json:"created_at"`
+	// CompletedAt is when the todo was last completed. It is nil while the
+	// todo is open, and for todos completed before it was recorded.
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	DueAt       *time.Time `json:"due_at,omitempty"`
+	DueZone     string     `json:"due_zone,omitempty"`
+	// RemindMinutes asks for a reminder that many minutes before the due
+	// date; 0 reminds at the due time. It is nil without a due date.
+	RemindMinutes *int      `json:"remind_minutes,omitempty"`
+	Tags          []string  `json:"tags,omitempty"`
+	Subtasks      []Subtask `json:"subtasks,omitempty"`
+	// Recurrence is a canonical RRULE; see Recurrence for the supported subset.
+	Recurrence string `json:"recurrence,omitempty"`
+	// NextOccurrenceID points at the todo spawned when this one was completed.
+	NextOccurrenceID string `json:"next_occurrence_id,omitempty"`
+	// Position orders todos manually; lower comes first.
+	Position float64 `json:"position"`
+	// ProjectID is the list the todo belongs to.
+	ProjectID string `json:"project_id"`
+	// DeletedAt is set while the todo is in the trash.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// Version increases every time the todo's content changes. Edits may name
+	// the version they were based on to detect concurrent changes.
+	Version uint64 `json:"version"`
+}
+
+// Draft holds the user-editable fields of a todo.
+type Draft struct {
 	Title       string
 	Description string
-	Completed   bool
 	Priority    Priority
-	CreatedAt   time.Time
+	DueAt       *time.Time
+	DueZone     string
+	// RemindMinutes needs DueAt; see Todo.RemindMinutes.
+	RemindMinutes *int
+	Tags          []string
+	Recurrence    string
+	// ProjectID defaults to the inbox when empty.
+	ProjectID string
+	// Completed adds the todo already done. Update leaves completion as it is;
+	// use Toggle or Patch.
+	Completed bool
+	// Version, when set, is the version of the todo the edit was based on.
+	// Update fails with ErrVersionConflict if the todo has changed since.
+	Version uint64
+}
+
+// Patch describes a partial update. Nil fields are left unchanged; ClearDue
+// removes the due date, and with it the reminder, and ClearRemind removes
+// only the reminder.
+type Patch struct {
+	Title         *string
+	Description   *string
+	Priority      *Priority
+	Completed     *bool
+	DueAt         *time.Time
+	DueZone       *string
+	ClearDue      bool
+	RemindMinutes *int
+	ClearRemind   bool
+	Tags          *[]string
+	Recurrence    *string
+	ProjectID     *string
+	// Version works as in Draft.
+	Version uint64
+}
+
+// Query selects the todos returned by List.
+type Query struct {
+	Filter Filter
+	// Tags limits results to todos carrying every listed tag.
+	Tags []string
+	// Search limits results to todos matching the text and orders them by
+	// relevance.
+	Search string
+	// Sort orders the results; the zero value is the manual order.
+	Sort Sort
+	// ProjectID limits results to one list; empty means every list.
+	ProjectID string
 }
 
 type Stats struct {
 	Total     int
 	Active    int
 	Completed int
+	Overdue   int
+	ThisWeek  int
+	// Trashed counts deleted todos; they are excluded from every other count.
+	Trashed int
+	// Progress is the overall completion between 0 and 1. Open todos earn
+	// partial credit for finished subtasks.
+	Progress float64
 }
 
 type Store struct {
-	mu     sync.RWMutex
-	todos  []*Todo
-	nextID uint64
+	mu      sync.RWMutex
+	todos   []*Todo
+	nextID  uint64
+	backend Backend
+	index   *searchIndex
+
+	projects      []Project
+	nextProjectID uint64
+
+	feedToken string
+
+	// events is the persisted activity log, oldest first.
+	events []Event
+
+	// undo and redo form the in-memory command log; it is not persisted.
+	undo []command
+	redo []command
+
+	// revision counts committed mutations from the time the store was
+	// created; feed tells subscribers about them.
+	revision uint64
+	feed     changeFeed
+
+	autoComplete bool
+	seed         bool
+
+	// now stamps todos moved to the trash; tests replace it to age them.
+	now func() time.Time
 }
 
-func New() *Store {
-	s := &Store{}
+// Option configures a Store.
+type Option func(*Store)
+
+// WithAutoComplete controls whether finishing the last open subtask completes
+// its parent todo (and reopening one reopens the parent). It is on by default.
+func WithAutoComplete(enabled bool) Option {
+	return func(s *Store) { s.autoComplete = enabled }
+}
+
+// WithSeed controls whether Open fills an empty backend with demo todos. With
+// seeding off, Open fails with ErrNoData instead of creating the data, for
+// tools that should only work on existing todos. It is on by default.
+func WithSeed(enabled bool) Option {
+	return func(s *Store) { s.seed = enabled }
+}
+
+func newStore(backend Backend, opts []Option) *Store {
+	// Counting from the start time keeps revisions growing across restarts,
+	// so pages rendered before one still take the changes made after it.
+	s := &Store{backend: backend, autoComplete: true, seed: true, revision: uint64(time.Now().UnixMicro()), now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// New returns a store seeded with demo data that lives only in memory.
+func New(opts ...Option) *Store {
+	s := newStore(NewMemoryBackend(), opts)
 	s.bootstrap()
 	return s
 }
 
+// Open loads the store from backend, seeding it with demo data when the
+// backend is empty (see WithSeed). Every mutation is written back before it
+// returns.
+func Open(backend Backend, opts ...Option) (*Store, error) {
+	s := newStore(backend, opts)
+
+	snapshot, found, err := backend.Load()
+	if err != nil {
+		return nil, fmt.Errorf("load todos: %w", err)
+	}
+	if !found {
+		if !s.seed {
+			return nil, ErrNoData
+		}
+		s.bootstrap()
+		if err := backend.Save(s.snapshotLocked()); err != nil {
+			return nil, fmt.Errorf("save todos: %w", err)
+		}
+		return s, nil
+	}
+
+	s.restoreLocked(snapshot)
+	s.index = buildSearchIndex(s.todos)
+	if s.feedToken == "" {
+		// Stores saved before calendar feeds existed get a token on first open.
+		s.feedToken = newFeedToken()
+		if err := backend.Save(s.snapshotLocked()); err != nil {
+			return nil, fmt.Errorf("save todos: %w", err)
+		}
+	}
+	return s, nil
+}
+
 func (s *Store) bootstrap() {
 	s.todos = []*Todo{
 		{
@@ -61,6 +245,11 @@ func (s *Store) bootstrap() {
 			Priority:    PriorityLow,
 			Completed:   false,
 			CreatedAt:   time.Date(2024, time.January, 13, 12, 0, 0, 0, time.UTC),
+			DueAt:       timePtr(time.Date(2024, time.January, 19, 17, 0, 0, 0, time.UTC)),
+			DueZone:     "UTC",
+			Tags:        []string{"docs"},
+			Recurrence:  "FREQ=WEEKLY;BYDAY=FR",
+			ProjectID:   InboxProjectID,
 		},
 		{
 			ID:          "2",
@@ -69,6 +258,9 @@ func (s *Store) bootstrap() {
 			Priority:    PriorityMedium,
 			Completed:   true,
 			CreatedAt:   time.Date(2024, time.January, 14, 12, 0, 0, 0, time.UTC),
+			CompletedAt: timePtr(time.Date(2024, time.January, 15, 16, 30, 0, 0, time.UTC)),
+			Tags:        []string{"engineering", "review"},
+			ProjectID:   "2",
 		},
 		{
 			ID:          "1",
@@ -77,116 +269,407 @@ func (s *Store) bootstrap() {
 			Priority:    PriorityHigh,
 			Completed:   false,
 			CreatedAt:   time.Date(2024, time.January, 15, 12, 0, 0, 0, time.UTC),
+			Tags:        []string{"design", "marketing"},
+			Subtasks: []Subtask{
+				{ID: "1", Title: "Collect reference sites", Done: true},
+				{ID: "2", Title: "Sketch wireframes", Done: true},
+				{ID: "3", Title: "High-fidelity mockups"},
+			},
+			ProjectID: "2",
 		},
 	}
+	for _, todo := range s.todos {
+		todo.Version = 1
+	}
 	s.nextID = uint64(len(s.todos) + 1)
+	s.projects = []Project{
+		inboxProject(),
+		{ID: "2", Name: "Work", Color: "blue", Icon: "briefcase"},
+	}
+	s.nextProjectID = 3
+	s.feedToken = newFeedToken()
 	s.sortLocked()
+	s.rebalanceLocked()
+	s.index = buildSearchIndex(s.todos)
 }
 
+// sortLocked keeps s.todos in manual order, newest first among equal
+// positions.
 func (s *Store) sortLocked() {
 	sort.SliceStable(s.todos, func(i, j int) bool {
-		return s.todos[i].CreatedAt.After(s.todos[j].CreatedAt)
+		a, b := s.todos[i], s.todos[j]
+		if a.Position != b.Position {
+			return a.Position < b.Position
+		}
+		return a.CreatedAt.After(b.CreatedAt)
 	})
 }
 
-func (s *Store) List(filter Filter) []Todo {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+// List returns every todo matching query; ListPage returns them a page at a
+// time.
+func (s *Store) List(query Query) []Todo {
+	return s.ListPage(Cursor{Query: query}, 0).Todos
+}
+
+// queryLocked returns the todos matching query, in the order List returns
+// them.
+func (s *Store) queryLocked(query Query) []*Todo {
+	now := time.Now()
+	tags := NormalizeTags(query.Tags)
+
+	var scores map[string]int
+	searching := strings.TrimSpace(query.Search) != ""
+	if searching {
+		scores = s.index.score(query.Search)
+	}
 
-	var filtered []Todo
+	var filtered []*Todo
 	for _, todo := range s.todos {
-		switch filter {
-		case FilterActive:
-			if todo.Completed {
-				continue
-			}
-		case FilterCompleted:
-			if !todo.Completed {
-				continue
-			}
+		if !todo.matches(query.Filter, now) || !todo.hasTags(tags) {
+			continue
 		}
-		filtered = append(filtered, *todo)
+		if query.ProjectID != "" && todo.ProjectID != query.ProjectID {
+			continue
+		}
+		if _, ok := scores[todo.ID]; searching && !ok {
+			continue
+		}
+		filtered = append(filtered, todo)
+	}
+
+	if searching {
+		sort.SliceStable(filtered, func(i, j int) bool {
+			return scores[filtered[i].ID] > scores[filtered[j].ID]
+		})
+	} else {
+		sortTodos(filtered, query.Sort)
 	}
 	return filtered
 }
 
+func (t *Todo) matches(filter Filter, now time.Time) bool {
+	if filter == FilterTrash {
+		return t.Trashed()
+	}
+	if t.Trashed() {
+		return false
+	}
+	switch filter {
+	case FilterActive:
+		return !t.Completed
+	case FilterCompleted:
+		return t.Completed
+	case FilterOverdue:
+		return t.IsOverdue(now)
+	case FilterThisWeek:
+		return t.IsDueThisWeek(now)
+	default:
+		return true
+	}
+}
+
+// Stats counts todos across every list.
 func (s *Store) Stats() Stats {
+	return s.ProjectStats("")
+}
+
+// ProjectStats counts the todos in one list, or in every list when projectID
+// is empty.
+func (s *Store) ProjectStats(projectID string) Stats {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	now := time.Now()
 	stats := Stats{}
+	credit := 0.0
 	for _, todo := range s.todos {
+		if projectID != "" && todo.ProjectID != projectID {
+			continue
+		}
+		if todo.Trashed() {
+			stats.Trashed++
+			continue
+		}
 		stats.Total++
 		if todo.Completed {
 			stats.Completed++
+			credit++
 		} else {
 			stats.Active++
+			if done, total := todo.SubtaskProgress(); total > 0 {
+				credit += float64(done) / float64(total)
+			}
+		}
+		if todo.IsOverdue(now) {
+			stats.Overdue++
 		}
+		if todo.IsDueThisWeek(now) {
+			stats.ThisWeek++
+		}
+	}
+	if stats.Total > 0 {
+		stats.Progress = credit / float64(stats.Total)
 	}
 	return stats
 }
 
-func (s *Store) Add(title, description string, priority Priority) Todo {
+func (s *Store) Add(draft Draft) (Todo, Commit, error) {
+	if err := draft.Validate(); err != nil {
+		return Todo{}, Commit{}, err
+	}
+	if err := draft.normalize(); err != nil {
+		return Todo{}, Commit{}, err
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	trimmedTitle := strings.TrimSpace(title)
-	trimmedDescription := strings.TrimSpace(description)
+	if err := s.resolveProjectLocked(&draft.ProjectID); err != nil {
+		return Todo{}, Commit{}, err
+	}
+
+	prev := s.snapshotLocked()
 
 	todo := &Todo{
-		ID:          generateID(s.nextID),
-		Title:       trimmedTitle,
-		Description: trimmedDescription,
-		Completed:   false,
-		Priority:    priority,
-		CreatedAt:   time.Now().UTC(),
+		ID:        generateID(s.nextID),
+		Completed: false,
+		CreatedAt: time.Now().UTC(),
+		Position:  s.topPositionLocked(),
 	}
+	todo.apply(draft)
 
 	s.nextID++
 	s.todos = append([]*Todo{todo}, s.todos...)
+	if draft.Completed {
+		now := time.Now()
+		todo.Completed = true
+		todo.CompletedAt = timePtr(now.UTC())
+		s.spawnNextLocked(todo, now)
+	}
 	s.sortLocked()
 
-	return *todo
+	commit, err := s.commitLocked(prev)
+	if err != nil {
+		return Todo{}, Commit{}, err
+	}
+	commit.Undo = s.recordLocked(todoLabel("Added", todo.Title), prev)
+	return todo.clone(), commit, nil
 }
 
-func (s *Store) Toggle(id string) (Todo, error) {
+func (s *Store) Toggle(id string) (Todo, Commit, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	todo, err := s.findLocked(id)
+	todo, err := s.findLiveLocked(id)
 	if err != nil {
-		return Todo{}, err
+		return Todo{}, Commit{}, err
 	}
+
+	prev := s.snapshotLocked()
 	todo.Completed = !todo.Completed
-	return *todo, nil
+	if todo.Completed {
+		s.spawnNextLocked(todo, time.Now())
+	}
+	commit, err := s.commitLocked(prev)
+	if err != nil {
+		return Todo{}, Commit{}, err
+	}
+	verb := "Reopened"
+	if todo.Completed {
+		verb = "Completed"
+	}
+	commit.Undo = s.recordLocked(todoLabel(verb, todo.Title), prev)
+	return todo.clone(), commit, nil
 }
 
-func (s *Store) Delete(id string) error {
+// Delete moves a todo to the trash. Use Purge to remove it for good.
+func (s *Store) Delete(id string) (Commit, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for i, todo := range s.todos {
-		if todo.ID == id {
-			s.todos = append(s.todos[:i], s.todos[i+1:]...)
-			return nil
-		}
+	todo, err := s.findLocked(id)
+	if err != nil {
+		return Commit{}, err
+	}
+	if todo.Trashed() {
+		return Commit{}, nil
+	}
+
+	prev := s.snapshotLocked()
+	todo.DeletedAt = timePtr(s.now().UTC())
+	commit, err := s.commitLocked(prev)
+	if err != nil {
+		return Commit{}, err
+	}
+	commit.Undo = s.recordLocked(todoLabel("Deleted", todo.Title), prev)
+	return commit, nil
+}
+
+func (s *Store) Update(id string, draft Draft) (Todo, Commit, error) {
+	if err := draft.Validate(); err != nil {
+		return Todo{}, Commit{}, err
+	}
+	if err := draft.normalize(); err != nil {
+		return Todo{}, Commit{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	todo, err := s.findLiveLocked(id)
+	if err != nil {
+		return Todo{}, Commit{}, err
+	}
+	if draft.Version != 0 && draft.Version != todo.Version {
+		return Todo{}, Commit{}, ErrVersionConflict
 	}
-	return ErrNotFound
+	if err := s.resolveProjectLocked(&draft.ProjectID); err != nil {
+		return Todo{}, Commit{}, err
+	}
+
+	prev := s.snapshotLocked()
+	todo.apply(draft)
+	commit, err := s.commitLocked(prev)
+	if err != nil {
+		return Todo{}, Commit{}, err
+	}
+	commit.Undo = s.recordLocked(todoLabel("Updated", todo.Title), prev)
+	return todo.clone(), commit, nil
 }
 
-func (s *Store) Update(id, title, description string, priority Priority) (Todo, error) {
+func (s *Store) Patch(id string, patch Patch) (Todo, Commit, error) {
+	if err := patch.Validate(); err != nil {
+		return Todo{}, Commit{}, err
+	}
+	if patch.Recurrence != nil {
+		rule, err := normalizeRecurrence(*patch.Recurrence)
+		if err != nil {
+			return Todo{}, Commit{}, err
+		}
+		patch.Recurrence = &rule
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	todo, err := s.findLocked(id)
+	todo, err := s.findLiveLocked(id)
+	if err != nil {
+		return Todo{}, Commit{}, err
+	}
+	if patch.Version != 0 && patch.Version != todo.Version {
+		return Todo{}, Commit{}, ErrVersionConflict
+	}
+
+	if patch.ProjectID != nil {
+		if err := s.resolveProjectLocked(patch.ProjectID); err != nil {
+			return Todo{}, Commit{}, err
+		}
+	}
+	hasDue := patch.DueAt != nil || (todo.DueAt != nil && !patch.ClearDue)
+	if patch.RemindMinutes != nil && !hasDue {
+		return Todo{}, Commit{}, validationError(map[string]string{"remind": errRemindWithoutDue})
+	}
+
+	prev := s.snapshotLocked()
+	if patch.ProjectID != nil {
+		todo.ProjectID = *patch.ProjectID
+	}
+	if patch.Title != nil {
+		todo.Title = strings.TrimSpace(*patch.Title)
+	}
+	if patch.Description != nil {
+		todo.Description = strings.TrimSpace(*patch.Description)
+	}
+	if patch.Priority != nil {
+		todo.Priority = *patch.Priority
+	}
+	if patch.Recurrence != nil {
+		todo.Recurrence = *patch.Recurrence
+	}
+	if patch.Completed != nil {
+		wasCompleted := todo.Completed
+		todo.Completed = *patch.Completed
+		if todo.Completed && !wasCompleted {
+			s.spawnNextLocked(todo, time.Now())
+		}
+	}
+	if patch.Tags != nil {
+		todo.Tags = NormalizeTags(*patch.Tags)
+	}
+	if patch.ClearDue {
+		todo.DueAt, todo.DueZone = nil, ""
+	}
+	if patch.DueAt != nil || (patch.DueZone != nil && todo.DueAt != nil) {
+		due, zone := todo.DueAt, todo.DueZone
+		if patch.DueAt != nil {
+			due = patch.DueAt
+		}
+		if patch.DueZone != nil {
+			zone = *patch.DueZone
+		}
+		todo.setDue(due, zone)
+	}
+	if patch.ClearRemind {
+		todo.RemindMinutes = nil
+	}
+	if patch.RemindMinutes != nil {
+		todo.setRemind(patch.RemindMinutes)
+	}
+	if todo.DueAt == nil {
+		todo.RemindMinutes = nil
+	}
+	commit, err := s.commitLocked(prev)
+	if err != nil {
+		return Todo{}, Commit{}, err
+	}
+	commit.Undo = s.recordLocked(todoLabel("Updated", todo.Title), prev)
+	return todo.clone(), commit, nil
+}
+
+func (t *Todo) apply(draft Draft) {
+	t.Title = strings.TrimSpace(draft.Title)
+	t.Description = strings.TrimSpace(draft.Description)
+	t.Priority = draft.Priority
+	t.setDue(draft.DueAt, draft.DueZone)
+	t.setRemind(draft.RemindMinutes)
+	t.Tags = NormalizeTags(draft.Tags)
+	t.Recurrence = draft.Recurrence
+	t.ProjectID = draft.ProjectID
+}
+
+// normalize validates the draft's recurrence rule and rewrites it in
+// canonical form.
+func (d *Draft) normalize() error {
+	rule, err := normalizeRecurrence(d.Recurrence)
 	if err != nil {
-		return Todo{}, err
+		return err
 	}
+	d.Recurrence = rule
+	return nil
+}
 
-	todo.Title = strings.TrimSpace(title)
-	todo.Description = strings.TrimSpace(description)
-	todo.Priority = priority
-	return *todo, nil
+// clone returns a copy that shares no slices with t.
+func (t *Todo) clone() Todo {
+	c := *t
+	if t.DueAt != nil {
+		due := *t.DueAt
+		c.DueAt = &due
+	}
+	if t.RemindMinutes != nil {
+		minutes := *t.RemindMinutes
+		c.RemindMinutes = &minutes
+	}
+	if t.DeletedAt != nil {
+		deleted := *t.DeletedAt
+		c.DeletedAt = &deleted
+	}
+	if t.CompletedAt != nil {
+		completed := *t.CompletedAt
+		c.CompletedAt = &completed
+	}
+	c.Tags = append([]string(nil), t.Tags...)
+	c.Subtasks = append([]Subtask(nil), t.Subtasks...)
+	return c
 }
 
 func (s *Store) Get(id string) (Todo, error) {
@@ -195,7 +678,7 @@ func (s *Store) Get(id string) (Todo, error) {
 
 	for _, todo := range s.todos {
 		if todo.ID == id {
-			return *todo, nil
+			return todo.clone(), nil
 		}
 	}
 	return Todo{}, ErrNotFound
@@ -210,7 +693,135 @@ func (s *Store) findLocked(id string) (*Todo, error) {
 	return nil, ErrNotFound
 }
 
-var ErrNotFound = errors.New("todo not found")
+// findLiveLocked is findLocked for edits. A todo in the trash has to be
+// restored before it can change, so it is reported as not found.
+func (s *Store) findLiveLocked(id string) (*Todo, error) {
+	todo, err := s.findLocked(id)
+	if err != nil {
+		return nil, err
+	}
+	if todo.Trashed() {
+		return nil, ErrNotFound
+	}
+	return todo, nil
+}
+
+// snapshotLocked copies the current state so it can be persisted or restored.
+// The activity log is shared rather than copied: events are only ever
+// appended, and the capacity is clipped so an append never writes into a
+// snapshot's view.
+func (s *Store) snapshotLocked() Snapshot {
+	todos := make([]Todo, len(s.todos))
+	for i, todo := range s.todos {
+		todos[i] = todo.clone()
+	}
+	return Snapshot{
+		NextID:        s.nextID,
+		Todos:         todos,
+		NextProjectID: s.nextProjectID,
+		Projects:      append([]Project(nil), s.projects...),
+		FeedToken:     s.feedToken,
+		Events:        s.events[:len(s.events):len(s.events)],
+	}
+}
+
+// restoreLocked replaces the state with snapshot. It leaves the search index
+// alone: a rolled-back commit never reached it, and Open indexes what it
+// loads.
+func (s *Store) restoreLocked(snapshot Snapshot) {
+	s.todos = make([]*Todo, len(snapshot.Todos))
+	for i := range snapshot.Todos {
+		todo := snapshot.Todos[i].clone()
+		if todo.Version == 0 {
+			// Todos saved before versions existed.
+			todo.Version = 1
+		}
+		s.todos[i] = &todo
+	}
+	s.nextID = snapshot.NextID
+	s.projects = append([]Project(nil), snapshot.Projects...)
+	s.nextProjectID = snapshot.NextProjectID
+	s.feedToken = snapshot.FeedToken
+	s.events = snapshot.Events[:len(snapshot.Events):len(snapshot.Events)]
+	s.sortLocked()
+}
+
+// commitLocked stamps and versions changed todos, logs their activity,
+// writes the current state to the backend, reindexes the changed todos for
+// search and notifies subscribers. If the write fails the in-memory state is
+// rolled back to prev so memory and disk never diverge.
+func (s *Store) commitLocked(prev Snapshot) (Commit, error) {
+	s.stampCompletionLocked(prev, time.Now().UTC())
+	s.versionLocked(prev)
+	s.logLocked(prev, time.Now().UTC())
+	if err := s.backend.Save(s.snapshotLocked()); err != nil {
+		s.restoreLocked(prev)
+		return Commit{}, fmt.Errorf("save todos: %w", err)
+	}
+	changes := s.diffLocked(prev)
+	s.index.update(changes)
+	return Commit{Change: s.publishLocked(prev, changes)}, nil
+}
+
+// versionLocked bumps the version of every todo whose content differs from
+// prev. New todos start at version 1. Reordering is not an edit, so a change
+// of position alone keeps the version.
+func (s *Store) versionLocked(prev Snapshot) {
+	before := make(map[string]Todo, len(prev.Todos))
+	for _, todo := range prev.Todos {
+		before[todo.ID] = todo
+	}
+
+	for _, todo := range s.todos {
+		old, existed := before[todo.ID]
+		if !existed {
+			todo.Version = 1
+			continue
+		}
+		// Undo and redo put back older versions; compare content only.
+		if sameContent(old, todo.clone()) {
+			todo.Version = before[todo.ID].Version
+		} else {
+			todo.Version = before[todo.ID].Version + 1
+		}
+	}
+}
+
+// sameContent reports whether a and b differ at most in their place in the
+// list and their version.
+func sameContent(a, b Todo) bool {
+	a.Position, a.Version = b.Position, b.Version
+	return reflect.DeepEqual(a, b)
+}
+
+// stampCompletionLocked records when todos that were open in prev became
+// completed, and clears the time of todos that are open again. Undo and redo
+// put back the earlier time along with the rest of the todo.
+func (s *Store) stampCompletionLocked(prev Snapshot, now time.Time) {
+	wasCompleted := make(map[string]bool, len(prev.Todos))
+	for _, todo := range prev.Todos {
+		wasCompleted[todo.ID] = todo.Completed
+	}
+
+	for _, todo := range s.todos {
+		switch completed, existed := wasCompleted[todo.ID]; {
+		case !todo.Completed:
+			todo.CompletedAt = nil
+		case todo.CompletedAt == nil && existed && !completed:
+			todo.CompletedAt = timePtr(now)
+		}
+	}
+}
+
+var (
+	ErrNotFound       = errors.New("todo not found")
+	ErrInvalidTag     = errors.New("invalid tag")
+	ErrInvalidSubtask = errors.New("subtask title is required")
+	// ErrVersionConflict means the todo changed after the edit was started.
+	ErrVersionConflict = errors.New("todo was changed by someone else")
+	// ErrNoData means Open found an empty backend with seeding turned off.
+	ErrNoData = errors.New("no saved todos")
+)
 
 func generateID(next uint64) string {
 	if next == 0 {
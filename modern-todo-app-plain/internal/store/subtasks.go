@@ -0,0 +1,107 @@
+package store
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Subtask is a checklist item under a todo.
+type Subtask struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Done  bool   `json:"done"`
+}
+
+// SubtaskProgress returns how many subtasks are done out of the total.
+func (t Todo) SubtaskProgress() (done, total int) {
+	for _, sub := range t.Subtasks {
+		if sub.Done {
+			done++
+		}
+	}
+	return done, len(t.Subtasks)
+}
+
+func (s *Store) AddSubtask(todoID, title string) (Todo, Commit, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return Todo{}, Commit{}, ErrInvalidSubtask
+	}
+	fields := map[string]string{}
+	validateTitle(fields, title)
+	if err := validationError(fields); err != nil {
+		return Todo{}, Commit{}, err
+	}
+
+	return s.updateSubtasks(todoID, func(todo *Todo) error {
+		todo.Subtasks = append(todo.Subtasks, Subtask{ID: nextSubtaskID(todo.Subtasks), Title: title})
+		return nil
+	})
+}
+
+func (s *Store) ToggleSubtask(todoID, subtaskID string) (Todo, Commit, error) {
+	return s.updateSubtasks(todoID, func(todo *Todo) error {
+		for i := range todo.Subtasks {
+			if todo.Subtasks[i].ID == subtaskID {
+				todo.Subtasks[i].Done = !todo.Subtasks[i].Done
+				return nil
+			}
+		}
+		return ErrNotFound
+	})
+}
+
+func (s *Store) DeleteSubtask(todoID, subtaskID string) (Todo, Commit, error) {
+	return s.updateSubtasks(todoID, func(todo *Todo) error {
+		for i := range todo.Subtasks {
+			if todo.Subtasks[i].ID == subtaskID {
+				todo.Subtasks = append(todo.Subtasks[:i], todo.Subtasks[i+1:]...)
+				return nil
+			}
+		}
+		return ErrNotFound
+	})
+}
+
+// updateSubtasks applies fn to a todo's checklist and, when auto-complete is
+// on, keeps the parent's completion in step with its subtasks.
+func (s *Store) updateSubtasks(todoID string, fn func(*Todo) error) (Todo, Commit, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	todo, err := s.findLiveLocked(todoID)
+	if err != nil {
+		return Todo{}, Commit{}, err
+	}
+
+	prev := s.snapshotLocked()
+	if err := fn(todo); err != nil {
+		s.restoreLocked(prev)
+		return Todo{}, Commit{}, err
+	}
+	if s.autoComplete {
+		if done, total := todo.SubtaskProgress(); total > 0 {
+			wasCompleted := todo.Completed
+			todo.Completed = done == total
+			if todo.Completed && !wasCompleted {
+				s.spawnNextLocked(todo, time.Now())
+			}
+		}
+	}
+	commit, err := s.commitLocked(prev)
+	if err != nil {
+		return Todo{}, Commit{}, err
+	}
+	return todo.clone(), commit, nil
+}
+
+func nextSubtaskID(subtasks []Subtask) string {
+	max := 0
+	for _, sub := range subtasks {
+		if n, err := strconv.Atoi(sub.ID); err == nil && n > max {
+			max = n
+		}
+	}
+	return strconv.Itoa(max + 1)
+}
@@ -0,0 +1,124 @@
+package store
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSubtaskAutoComplete(t *testing.T) {
+	type step struct {
+		// op is add, toggle or delete; arg is the new title or the subtask ID.
+		op, arg string
+		// completed is the parent's state after the step.
+		completed bool
+	}
+	tests := []struct {
+		name         string
+		autoComplete bool
+		steps        []step
+	}{
+		{
+			name:         "on",
+			autoComplete: true,
+			steps: []step{
+				{"add", "Buy paint", false},
+				{"add", "Sand the fence", false},
+				{"toggle", "1", false},
+				{"toggle", "2", true},
+				{"toggle", "1", false},
+				{"toggle", "1", true},
+				{"add", "Clean brushes", false},
+				{"delete", "3", true},
+			},
+		},
+		{
+			name:         "completed by deleting the open subtask",
+			autoComplete: true,
+			steps: []step{
+				{"add", "Buy paint", false},
+				{"add", "Sand the fence", false},
+				{"toggle", "1", false},
+				{"delete", "2", true},
+				{"toggle", "1", false},
+			},
+		},
+		{
+			name:         "off",
+			autoComplete: false,
+			steps: []step{
+				{"add", "Buy paint", false},
+				{"toggle", "1", false},
+				{"toggle", "1", false},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := NewMemoryBackend()
+			if err := backend.Save(Snapshot{NextID: 1, NextProjectID: 1, Projects: []Project{inboxProject()}}); err != nil {
+				t.Fatal(err)
+			}
+			s, err := Open(backend, WithAutoComplete(tt.autoComplete))
+			if err != nil {
+				t.Fatal(err)
+			}
+			parent, _, err := s.Add(Draft{Title: "Paint the fence", Priority: PriorityMedium})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i, step := range tt.steps {
+				var todo Todo
+				switch step.op {
+				case "add":
+					todo, _, err = s.AddSubtask(parent.ID, step.arg)
+				case "toggle":
+					todo, _, err = s.ToggleSubtask(parent.ID, step.arg)
+				case "delete":
+					todo, _, err = s.DeleteSubtask(parent.ID, step.arg)
+				}
+				if err != nil {
+					t.Fatalf("step %d (%s %s): %v", i, step.op, step.arg, err)
+				}
+				if todo.Completed != step.completed {
+					done, total := todo.SubtaskProgress()
+					t.Errorf("step %d (%s %s): completed = %t with %d/%d done, want %t",
+						i, step.op, step.arg, todo.Completed, done, total, step.completed)
+				}
+			}
+		})
+	}
+}
+
+func TestAddSubtaskTitle(t *testing.T) {
+	tests := []struct {
+		name    string
+		title   string
+		wantErr error
+	}{
+		{name: "ok", title: "Buy paint"},
+		{name: "longest", title: strings.Repeat("x", MaxTitleLength)},
+		{name: "empty", title: "  ", wantErr: ErrInvalidSubtask},
+		{name: "too long", title: strings.Repeat("x", MaxTitleLength+1), wantErr: ErrInvalidTodo},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New()
+			parent, _, err := s.Add(Draft{Title: "Paint the fence", Priority: PriorityMedium})
+			if err != nil {
+				t.Fatal(err)
+			}
+			todo, _, err := s.AddSubtask(parent.ID, tt.title)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if _, total := todo.SubtaskProgress(); tt.wantErr == nil && total != 1 {
+				t.Errorf("todo has %d subtasks, want 1", total)
+			}
+			if saved, _ := s.Get(parent.ID); tt.wantErr != nil && len(saved.Subtasks) != 0 {
+				t.Errorf("saved %d subtasks, want none", len(saved.Subtasks))
+			}
+		})
+	}
+}
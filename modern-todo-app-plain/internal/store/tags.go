@@ -0,0 +1,143 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TagCount is a tag together with the number of todos carrying it.
+type TagCount struct {
+	Name  string
+	Count int
+}
+
+// NormalizeTags lowercases, trims and de-duplicates tags, dropping a leading
+// "#" and empty entries. The result is sorted.
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	var out []string
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		out = append(out, tag)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// NormalizeTag converts a single tag to its canonical form.
+func NormalizeTag(tag string) string {
+	tag = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+	return strings.ToLower(strings.Join(strings.Fields(tag), "-"))
+}
+
+// ParseTags splits a comma or whitespace separated list of tags.
+func ParseTags(raw string) []string {
+	return NormalizeTags(strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	}))
+}
+
+func (t *Todo) hasTags(tags []string) bool {
+	for _, want := range tags {
+		found := false
+		for _, have := range t.Tags {
+			if have == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Tags returns every tag in use, sorted by name.
+func (s *Store) Tags() []TagCount {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := map[string]int{}
+	for _, todo := range s.todos {
+		if todo.Trashed() {
+			continue
+		}
+		for _, tag := range todo.Tags {
+			counts[tag]++
+		}
+	}
+
+	tags := make([]TagCount, 0, len(counts))
+	for name, count := range counts {
+		tags = append(tags, TagCount{Name: name, Count: count})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags
+}
+
+// RenameTag replaces from with to on every todo. Renaming onto an existing tag
+// merges the two.
+func (s *Store) RenameTag(from, to string) (Commit, error) {
+	from, to = NormalizeTag(from), NormalizeTag(to)
+	if from == "" || to == "" {
+		return Commit{}, ErrInvalidTag
+	}
+
+	return s.retag(fmt.Sprintf("Renamed #%s to #%s", from, to), func(tag string) string {
+		if tag == from {
+			return to
+		}
+		return tag
+	})
+}
+
+// DeleteTag removes tag from every todo.
+func (s *Store) DeleteTag(tag string) (Commit, error) {
+	tag = NormalizeTag(tag)
+	if tag == "" {
+		return Commit{}, ErrInvalidTag
+	}
+
+	return s.retag("Deleted #"+tag, func(t string) string {
+		if t == tag {
+			return ""
+		}
+		return t
+	})
+}
+
+// retag rewrites every tag through fn; an empty result drops the tag. The
+// whole rewrite is one undo step under label.
+func (s *Store) retag(label string, fn func(string) string) (Commit, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev := s.snapshotLocked()
+	changed := false
+	for _, todo := range s.todos {
+		tags := make([]string, 0, len(todo.Tags))
+		for _, tag := range todo.Tags {
+			renamed := fn(tag)
+			if renamed != tag {
+				changed = true
+			}
+			tags = append(tags, renamed)
+		}
+		todo.Tags = NormalizeTags(tags)
+	}
+	if !changed {
+		return Commit{}, nil
+	}
+	commit, err := s.commitLocked(prev)
+	if err != nil {
+		return Commit{}, err
+	}
+	commit.Undo = s.recordLocked(label, prev)
+	return commit, nil
+}
@@ -0,0 +1,67 @@
+package store
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRetagUndo(t *testing.T) {
+	tests := []struct {
+		name  string
+		retag func(s *Store) (Commit, error)
+		label string
+		want  string
+	}{
+		{
+			name:  "rename",
+			retag: func(s *Store) (Commit, error) { return s.RenameTag("#Work", "job") },
+			label: "Renamed #work to #job",
+			want:  "home,job|job",
+		},
+		{
+			name:  "delete",
+			retag: func(s *Store) (Commit, error) { return s.DeleteTag("work") },
+			label: "Deleted #work",
+			want:  "home|",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := openEmpty(t)
+			first, _, err := s.Add(Draft{Title: "First", Priority: PriorityMedium, Tags: []string{"work", "home"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			second, _, err := s.Add(Draft{Title: "Second", Priority: PriorityMedium, Tags: []string{"work"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			tags := func() string {
+				a, _ := s.Get(first.ID)
+				b, _ := s.Get(second.ID)
+				return strings.Join(a.Tags, ",") + "|" + strings.Join(b.Tags, ",")
+			}
+
+			if _, err := tt.retag(s); err != nil {
+				t.Fatal(err)
+			}
+			if got := tags(); got != tt.want {
+				t.Errorf("tags = %s, want %s", got, tt.want)
+			}
+			if undo, _ := s.History(); undo != tt.label {
+				t.Errorf("undo label = %q, want %q", undo, tt.label)
+			}
+
+			// Both todos come back in a single step.
+			if _, err := s.Undo(); err != nil {
+				t.Fatal(err)
+			}
+			if got := tags(); got != "home,work|work" {
+				t.Errorf("after undo: tags = %s, want home,work|work", got)
+			}
+			if undo, _ := s.History(); undo != `Added "Second"` {
+				t.Errorf("undo label after undo = %q, want %q", undo, `Added "Second"`)
+			}
+		})
+	}
+}
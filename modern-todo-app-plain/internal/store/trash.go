@@ -0,0 +1,101 @@
+package store
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNotTrashed means a todo must be in the trash for the operation, such as
+// Purge.
+var ErrNotTrashed = errors.New("todo is not in the trash")
+
+// Trashed reports whether the todo is in the trash.
+func (t Todo) Trashed() bool {
+	return t.DeletedAt != nil
+}
+
+// Restore takes a todo out of the trash.
+func (s *Store) Restore(id string) (Todo, Commit, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	todo, err := s.findLocked(id)
+	if err != nil {
+		return Todo{}, Commit{}, err
+	}
+	if !todo.Trashed() {
+		return todo.clone(), Commit{}, nil
+	}
+
+	prev := s.snapshotLocked()
+	todo.DeletedAt = nil
+	s.fixProjectLocked(todo)
+	commit, err := s.commitLocked(prev)
+	if err != nil {
+		return Todo{}, Commit{}, err
+	}
+	commit.Undo = s.recordLocked(todoLabel("Restored", todo.Title), prev)
+	return todo.clone(), commit, nil
+}
+
+// Purge permanently removes a trashed todo. It cannot be undone, so todos
+// that are not in the trash are refused with ErrNotTrashed.
+func (s *Store) Purge(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	todo, err := s.findLocked(id)
+	if err != nil {
+		return err
+	}
+	if !todo.Trashed() {
+		return ErrNotTrashed
+	}
+	_, err = s.purgeLocked(func(todo *Todo) bool { return todo.ID == id && todo.Trashed() })
+	return err
+}
+
+// EmptyTrash permanently removes every trashed todo and returns how many were
+// removed.
+func (s *Store) EmptyTrash() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.purgeLocked(func(todo *Todo) bool { return todo.Trashed() })
+}
+
+// PurgeTrash permanently removes todos that were trashed before cutoff. It is
+// run periodically to enforce the trash retention window.
+func (s *Store) PurgeTrash(cutoff time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.purgeLocked(func(todo *Todo) bool {
+		return todo.Trashed() && todo.DeletedAt.Before(cutoff)
+	})
+}
+
+// purgeLocked removes the todos matching drop and forgets them in the command
+// log so an undo cannot bring them back.
+func (s *Store) purgeLocked(drop func(*Todo) bool) (int, error) {
+	prev := s.snapshotLocked()
+	kept := s.todos[:0:0]
+	removed := map[string]bool{}
+	for _, todo := range s.todos {
+		if drop(todo) {
+			removed[todo.ID] = true
+			continue
+		}
+		kept = append(kept, todo)
+	}
+	if len(removed) == 0 {
+		return 0, nil
+	}
+
+	s.todos = kept
+	if _, err := s.commitLocked(prev); err != nil {
+		return 0, err
+	}
+	s.forgetLocked(removed)
+	return len(removed), nil
+}
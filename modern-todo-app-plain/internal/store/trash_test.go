@@ -0,0 +1,88 @@
+package store
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPurgeTrash(t *testing.T) {
+	s := openEmpty(t)
+	clock := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return clock }
+
+	ids := map[string]string{}
+	for _, title := range []string{"Old", "Recent", "Kept"} {
+		todo, _, err := s.Add(Draft{Title: title, Priority: PriorityMedium})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[title] = todo.ID
+	}
+	if _, err := s.Delete(ids["Old"]); err != nil {
+		t.Fatal(err)
+	}
+	clock = clock.Add(48 * time.Hour)
+	if _, err := s.Delete(ids["Recent"]); err != nil {
+		t.Fatal(err)
+	}
+
+	// The cutoff falls between the two deletions.
+	removed, err := s.PurgeTrash(clock.Add(-24 * time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 1 {
+		t.Errorf("purged %d todos, want 1", removed)
+	}
+	if _, err := s.Get(ids["Old"]); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(Old) err = %v, want ErrNotFound", err)
+	}
+	if recent, err := s.Get(ids["Recent"]); err != nil || !recent.Trashed() {
+		t.Errorf("Recent: trashed = %t, err = %v; want it left in the trash", recent.Trashed(), err)
+	}
+	if kept, err := s.Get(ids["Kept"]); err != nil || kept.Trashed() {
+		t.Errorf("Kept: trashed = %t, err = %v; want it untouched", kept.Trashed(), err)
+	}
+
+	// Undo reaches the second deletion but cannot bring back the purged todo.
+	if label, err := s.Undo(); err != nil || label != `Deleted "Recent"` {
+		t.Fatalf("Undo() = %q, %v; want %q", label, err, `Deleted "Recent"`)
+	}
+	if label, err := s.Undo(); err != nil || label != `Added "Kept"` {
+		t.Errorf("second Undo() = %q, %v; want %q", label, err, `Added "Kept"`)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	s := openEmpty(t)
+	s.now = func() time.Time { return time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC) }
+
+	todo, _, err := s.Add(Draft{Title: "Changed my mind", Priority: PriorityMedium})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Delete(todo.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := pageTitles(s.List(Query{})); got != "" {
+		t.Errorf("list with the todo trashed = %q, want it empty", got)
+	}
+
+	restored, _, err := s.Restore(todo.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.DeletedAt != nil {
+		t.Errorf("restored DeletedAt = %v, want nil", restored.DeletedAt)
+	}
+	if got := pageTitles(s.List(Query{})); got != todo.Title {
+		t.Errorf("list after restoring = %q, want %q", got, todo.Title)
+	}
+	if _, err := s.PurgeTrash(s.now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(todo.ID); err != nil {
+		t.Errorf("purging the trash removed the restored todo: %v", err)
+	}
+}
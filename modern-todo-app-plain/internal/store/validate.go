@@ -0,0 +1,121 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits on the free-text fields of a todo, counted in characters.
+const (
+	MaxTitleLength       = 200
+	MaxDescriptionLength = 2000
+)
+
+// ErrInvalidTodo matches every *ValidationError via errors.Is.
+var ErrInvalidTodo = errors.New("invalid todo")
+
+// ValidationError reports the invalid fields of a todo. Fields maps field
+// names (title, description, priority, remind, recurrence) to messages.
+type ValidationError struct {
+	Fields map[string]string
+}
+
+func (e *ValidationError) Error() string {
+	names := make([]string, 0, len(e.Fields))
+	for name := range e.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + ": " + e.Fields[name]
+	}
+	return "invalid todo: " + strings.Join(parts, "; ")
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidTodo
+}
+
+// Validate checks the fields of a draft, returning a *ValidationError listing
+// every problem, or nil.
+func (d Draft) Validate() error {
+	fields := map[string]string{}
+	validateTitle(fields, d.Title)
+	validateDescription(fields, d.Description)
+	validatePriority(fields, d.Priority)
+	validateRemind(fields, d.RemindMinutes)
+	if d.RemindMinutes != nil && d.DueAt == nil {
+		fields["remind"] = errRemindWithoutDue
+	}
+	validateRecurrence(fields, d.Recurrence)
+	return validationError(fields)
+}
+
+// Validate checks the fields a patch sets, like Draft.Validate.
+func (p Patch) Validate() error {
+	fields := map[string]string{}
+	if p.Title != nil {
+		validateTitle(fields, *p.Title)
+	}
+	if p.Description != nil {
+		validateDescription(fields, *p.Description)
+	}
+	if p.Priority != nil {
+		validatePriority(fields, *p.Priority)
+	}
+	validateRemind(fields, p.RemindMinutes)
+	if p.Recurrence != nil {
+		validateRecurrence(fields, *p.Recurrence)
+	}
+	return validationError(fields)
+}
+
+func validateTitle(fields map[string]string, title string) {
+	title = strings.TrimSpace(title)
+	switch {
+	case title == "":
+		fields["title"] = "title is required"
+	case utf8.RuneCountInString(title) > MaxTitleLength:
+		fields["title"] = fmt.Sprintf("title must be at most %d characters", MaxTitleLength)
+	}
+}
+
+func validateDescription(fields map[string]string, description string) {
+	if utf8.RuneCountInString(strings.TrimSpace(description)) > MaxDescriptionLength {
+		fields["description"] = fmt.Sprintf("description must be at most %d characters", MaxDescriptionLength)
+	}
+}
+
+func validatePriority(fields map[string]string, priority Priority) {
+	if !priority.Valid() {
+		fields["priority"] = "priority must be low, medium, or high"
+	}
+}
+
+// errRemindWithoutDue is the field error for a reminder on a todo with no
+// due date to count back from.
+const errRemindWithoutDue = "set a due date to get a reminder"
+
+func validateRemind(fields map[string]string, minutes *int) {
+	if minutes != nil && (*minutes < 0 || *minutes > MaxRemindMinutes) {
+		fields["remind"] = "reminder must be between the due time and a week before it"
+	}
+}
+
+func validateRecurrence(fields map[string]string, rule string) {
+	if _, err := normalizeRecurrence(rule); err != nil {
+		fields["recurrence"] = err.Error()
+	}
+}
+
+func validationError(fields map[string]string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: fields}
+}
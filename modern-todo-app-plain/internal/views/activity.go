@@ -0,0 +1,210 @@
+package views
+
+import (
+	"fmt"
+	"time"
+
+	"modern_todo_plain/internal/store"
+
+	. "github.com/plainkit/html"
+	icons "github.com/plainkit/icons/lucide"
+)
+
+// activityJS opens the activity drawer once htmx has loaded it into
+// #activity-slot.
+const activityJS = `(() => {
+  document.addEventListener('htmx:afterSwap', (event) => {
+    if (event.target.id !== 'activity-slot') return;
+    const drawer = document.getElementById('activity-drawer');
+    if (drawer && !drawer.open) drawer.showModal();
+  });
+})();`
+
+// activitySlot receives the activity drawer of a todo.
+func activitySlot() Node {
+	return Div(Id("activity-slot")).WithAssets("", activityJS, "todo-activity")
+}
+
+// activityButton opens the timeline of a todo in the activity drawer.
+func activityButton(todo store.Todo) Node {
+	return Button(
+		ButtonType("button"),
+		Class("inline-flex h-8 w-8 items-center justify-center rounded-lg text-muted-foreground hover:bg-muted"),
+		Title("History"),
+		Aria("label", "Show history"),
+		Custom("hx-get", todoPath(todo.ID, "activity")),
+		Custom("hx-target", "#activity-slot"),
+		Custom("hx-swap", "innerHTML"),
+		icons.History(icons.Size("16")),
+	)
+}
+
+// ActivityDrawer shows everything that happened to one todo, newest first.
+func ActivityDrawer(todo store.Todo, events []store.Event, data PageData) Node {
+	return Dialog(
+		Id("activity-drawer"),
+		Class("modal drawer"),
+		Aria("labelledby", "activity-title"),
+		Child(
+			Div(
+				Class("flex h-full flex-col"),
+				Div(
+					Class("flex items-start justify-between gap-3 border-b border-border p-6"),
+					Div(
+						Class("min-w-0 space-y-1"),
+						P(Class("text-xs font-medium uppercase text-muted-foreground"), T("History")),
+						H2(Id("activity-title"), Class("break-words text-xl font-semibold"), T(todo.Title)),
+						P(Class("text-xs text-muted-foreground"), T(fmt.Sprintf("Created %s · version %d", todo.CreatedAt.Local().Format("Jan 02, 2006"), todo.Version))),
+					),
+					Button(
+						ButtonType("button"),
+						Class("inline-flex h-8 w-8 items-center justify-center rounded-lg text-muted-foreground hover:bg-muted"),
+						Aria("label", "Close"),
+						Data("close-dialog", "activity-drawer"),
+						icons.X(icons.Size("16")),
+					),
+				),
+				Div(
+					Class("flex-1 overflow-y-auto p-6"),
+					activityTimeline(events, data, false),
+				),
+			),
+		),
+	)
+}
+
+// ActivityPage lists the most recent changes to every todo, grouped by day.
+func ActivityPage(events []store.Event, data PageData) Component {
+	days := []DivArg{Class("space-y-8")}
+	if len(events) == 0 {
+		days = append(days, P(Class("text-sm text-muted-foreground"), T("Nothing has happened yet. Changes to your tasks will show up here.")))
+	}
+	for start := 0; start < len(events); {
+		day := events[start].At.Local().Format("2006-01-02")
+		end := start
+		for end < len(events) && events[end].At.Local().Format("2006-01-02") == day {
+			end++
+		}
+		days = append(days, Section(
+			Class("space-y-3"),
+			H2(Class("text-sm font-semibold text-muted-foreground"), T(activityDay(events[start].At, data.Now))),
+			activityTimeline(events[start:end], data, true),
+		))
+		start = end
+	}
+
+	return subPage("Recent activity", icons.Activity(icons.Size("22"), Class("text-primary")), Div(days...))
+}
+
+// activityTimeline renders events as a vertical timeline. withTitle names the
+// todo on each entry, for timelines that mix todos.
+func activityTimeline(events []store.Event, data PageData, withTitle bool) Node {
+	if len(events) == 0 {
+		return P(Class("text-sm text-muted-foreground"), T("No changes recorded yet."))
+	}
+
+	items := []OlArg{Class("activity-timeline space-y-4 border-l border-border pl-5")}
+	for _, event := range events {
+		items = append(items, Child(activityEntry(event, data, withTitle)))
+	}
+	return Ol(items...)
+}
+
+func activityEntry(event store.Event, data PageData, withTitle bool) Node {
+	heading := []DivArg{
+		Class("flex flex-wrap items-baseline gap-x-2 text-sm"),
+		Span(Class("font-medium"), T(eventLabels[event.Kind])),
+	}
+	if withTitle {
+		heading = append(heading, Span(Class("min-w-0 break-words"), T(event.Title)))
+	}
+	heading = append(heading, Span(
+		Class("text-xs text-muted-foreground"),
+		Title(event.At.Local().Format("Mon Jan 02 2006, 15:04:05")),
+		T(event.At.Local().Format("Jan 02, 15:04")),
+	))
+
+	args := []LiArg{
+		Class("relative space-y-1"),
+		Span(
+			Class(eventDotClass(event.Kind)),
+			Aria("hidden", "true"),
+		),
+		Div(heading...),
+	}
+	if len(event.Changes) > 0 {
+		changes := []DlArg{Class("grid grid-cols-[auto_1fr] gap-x-3 gap-y-1 text-xs")}
+		for _, change := range event.Changes {
+			changes = append(changes,
+				Child(Dt(Class("text-muted-foreground"), T(capitalize(change.Field)))),
+				Child(Dd(
+					Class("min-w-0 break-words"),
+					Child(Span(Class("text-muted-foreground line-through"), T(orDash(changeValue(change.Field, change.Before, data))))),
+					T(" → "),
+					Child(Span(T(orDash(changeValue(change.Field, change.After, data))))),
+				)),
+			)
+		}
+		args = append(args, Dl(changes...))
+	}
+	return Li(args...)
+}
+
+var eventLabels = map[store.EventKind]string{
+	store.EventCreated:   "Created",
+	store.EventEdited:    "Edited",
+	store.EventCompleted: "Completed",
+	store.EventReopened:  "Reopened",
+	store.EventDeleted:   "Moved to trash",
+	store.EventRestored:  "Restored",
+	store.EventRemoved:   "Removed",
+}
+
+func eventDotClass(kind store.EventKind) string {
+	base := "absolute -left-[1.6rem] top-1.5 h-2.5 w-2.5 rounded-full ring-4 ring-background "
+	switch kind {
+	case store.EventCreated, store.EventRestored:
+		return base + "bg-primary"
+	case store.EventCompleted:
+		return base + "bg-emerald-500"
+	case store.EventDeleted, store.EventRemoved:
+		return base + "bg-destructive"
+	}
+	return base + "bg-muted-foreground"
+}
+
+// changeValue formats a logged field value for display.
+func changeValue(field, value string, data PageData) string {
+	switch field {
+	case "list":
+		if value == "" {
+			return ""
+		}
+		return data.projectName(value)
+	case "priority":
+		return capitalize(value)
+	}
+	return value
+}
+
+// activityDay names the day of an event relative to now.
+func activityDay(at, now time.Time) string {
+	y, m, d := now.Local().Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+	switch day := at.Local(); {
+	case !day.Before(today):
+		return "Today"
+	case !day.Before(today.AddDate(0, 0, -1)):
+		return "Yesterday"
+	default:
+		return day.Format("Monday, Jan 02")
+	}
+}
+
+func RenderActivityDrawer(todo store.Todo, events []store.Event, data PageData) string {
+	return Render(ActivityDrawer(todo, events, data))
+}
+
+func RenderActivityPage(events []store.Event, data PageData) string {
+	return Render(ActivityPage(events, data))
+}
@@ -0,0 +1,154 @@
+package views
+
+import (
+	"fmt"
+
+	"modern_todo_plain/internal/store"
+
+	. "github.com/plainkit/html"
+	icons "github.com/plainkit/icons/lucide"
+)
+
+// batchJS shows the batch bar while any card is selected, keeps its count and
+// the select-all box in step with the card checkboxes.
+const batchJS = `(() => {
+  const boxes = () => Array.from(document.querySelectorAll('.todo-select'));
+
+  const refresh = () => {
+    const form = document.getElementById('batch-form');
+    if (!form) return;
+    const all = boxes();
+    const selected = all.filter(box => box.checked).length;
+    form.classList.toggle('hidden', selected === 0);
+    const count = document.getElementById('batch-count');
+    if (count) count.textContent = selected === 1 ? '1 selected' : selected + ' selected';
+    const selectAll = document.getElementById('batch-select-all');
+    if (selectAll) {
+      selectAll.checked = all.length > 0 && selected === all.length;
+      selectAll.indeterminate = selected > 0 && selected < all.length;
+    }
+  };
+
+  document.addEventListener('change', (event) => {
+    if (event.target.id === 'batch-select-all') {
+      boxes().forEach(box => { box.checked = event.target.checked; });
+      refresh();
+    } else if (event.target.classList.contains('todo-select')) {
+      refresh();
+    }
+  });
+
+  document.addEventListener('htmx:afterSwap', refresh);
+})();`
+
+const batchButtonClass = "inline-flex items-center gap-1 rounded-lg border border-border px-2.5 py-1 text-xs font-medium hover:bg-muted"
+
+// batchForm is the action bar for the selected cards. The card checkboxes sit
+// outside the form and join it through their form attribute.
+func batchForm(data PageData) Node {
+	priorityArgs := []SelectArg{
+		Custom("name", "priority"),
+		Class("rounded-lg border bg-background px-2 py-1 text-xs"),
+		Aria("label", "New priority"),
+	}
+	for _, priority := range []store.Priority{store.PriorityLow, store.PriorityMedium, store.PriorityHigh} {
+		priorityArgs = append(priorityArgs, Child(Option(Custom("value", string(priority)), T(capitalize(string(priority))))))
+	}
+
+	projectArgs := []SelectArg{
+		Custom("name", "project"),
+		Class("rounded-lg border bg-background px-2 py-1 text-xs"),
+		Aria("label", "Move to list"),
+	}
+	for _, project := range data.Projects {
+		projectArgs = append(projectArgs, Child(Option(Custom("value", project.ID), T(project.Name))))
+	}
+
+	return Form(
+		Id("batch-form"),
+		Class("hidden flex flex-wrap items-center gap-2 rounded-xl border border-primary/30 bg-primary/5 px-4 py-2"),
+		Custom("hx-post", "/todos/batch"),
+		Custom("hx-target", "#todo-results"),
+		Custom("hx-swap", "outerHTML"),
+		Custom("hx-include", viewStateInclude),
+		FormLabel(
+			Class("mr-2 flex items-center gap-2 text-sm font-medium"),
+			Input(InputType("checkbox"), Id("batch-select-all"), Class("h-4 w-4 rounded border-border"), Aria("label", "Select all")),
+			Span(Id("batch-count"), T("0 selected")),
+		),
+		batchButton("complete", icons.Check(icons.Size("14")), "Complete"),
+		batchButton("reopen", icons.RotateCcw(icons.Size("14")), "Reopen"),
+		Div(
+			Class("flex items-center gap-1"),
+			Select(priorityArgs...),
+			batchButton("priority", icons.Flag(icons.Size("14")), "Set"),
+		),
+		Div(
+			Class("flex items-center gap-1"),
+			Select(projectArgs...),
+			batchButton("move", icons.FolderInput(icons.Size("14")), "Move"),
+		),
+		Div(
+			Class("flex items-center gap-1"),
+			Input(
+				InputName("batch_tag"),
+				Placeholder("tag"),
+				Class("w-24 rounded-lg border bg-background px-2 py-1 text-xs"),
+				Aria("label", "Tag to add"),
+			),
+			batchButton("tag", icons.Tag(icons.Size("14")), "Tag"),
+		),
+		Button(
+			ButtonType("submit"),
+			Custom("name", "action"),
+			Custom("value", "delete"),
+			Class("ml-auto inline-flex items-center gap-1 rounded-lg px-2.5 py-1 text-xs font-medium text-destructive hover:bg-destructive/10"),
+			icons.Trash2(icons.Size("14")),
+			T("Delete"),
+		),
+	).WithAssets("", batchJS, "todo-batch")
+}
+
+func batchButton(action string, icon Node, label string) Node {
+	return Button(
+		ButtonType("submit"),
+		Custom("name", "action"),
+		Custom("value", action),
+		Class(batchButtonClass),
+		icon,
+		T(label),
+	)
+}
+
+// selectBox puts a card in the current batch selection.
+func selectBox(todo store.Todo) Node {
+	return Input(
+		InputType("checkbox"),
+		InputName("id"),
+		InputValue(todo.ID),
+		Custom("form", "batch-form"),
+		Class("todo-select mt-2 h-4 w-4 rounded border-border"),
+		Aria("label", "Select "+todo.Title),
+	)
+}
+
+// clearCompletedButton moves the completed todos of the current list to the
+// trash. It stays in the page, empty when there is nothing to clear, so
+// partial responses can update it out of band.
+func clearCompletedButton(data PageData, attrs ...Global) Node {
+	args := []SpanArg{Id("clear-completed")}
+	if data.Filter != store.FilterTrash && data.Stats.Completed > 0 {
+		args = append(args, Button(
+			ButtonType("button"),
+			Class("inline-flex items-center gap-1 rounded-lg border border-border px-3 py-1.5 text-sm font-medium text-muted-foreground hover:bg-muted"),
+			Custom("hx-post", "/todos/clear-completed"),
+			Custom("hx-target", "#todo-results"),
+			Custom("hx-swap", "outerHTML"),
+			Custom("hx-include", viewStateInclude),
+			icons.ListX(icons.Size("14")),
+			T(fmt.Sprintf("Clear completed (%d)", data.Stats.Completed)),
+		))
+	}
+	args = append(args, spanArgs(attrs...)...)
+	return Span(args...)
+}
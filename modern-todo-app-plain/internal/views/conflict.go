@@ -0,0 +1,182 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"modern_todo_plain/internal/store"
+
+	. "github.com/plainkit/html"
+	icons "github.com/plainkit/icons/lucide"
+)
+
+// conflictJS lets htmx swap the 409 response of a stale edit, which is aimed
+// at #conflict-slot, and opens the dialog it contains.
+const conflictJS = `(() => {
+  document.addEventListener('htmx:beforeSwap', (event) => {
+    const xhr = event.detail.xhr;
+    if (xhr.status === 409 && xhr.getResponseHeader('HX-Retarget') === '#conflict-slot') {
+      event.detail.shouldSwap = true;
+      event.detail.isError = false;
+    }
+  });
+
+  document.addEventListener('htmx:afterSwap', (event) => {
+    if (event.target.id !== 'conflict-slot') return;
+    const dialog = document.getElementById('conflict-dialog');
+    if (dialog && !dialog.open) dialog.showModal();
+  });
+})();`
+
+// conflictSlot receives the conflict dialog when an edit is rejected.
+func conflictSlot() Node {
+	return Div(Id("conflict-slot")).WithAssets("", conflictJS, "todo-conflict")
+}
+
+// ConflictDialog compares an edit based on an outdated version with the todo
+// as it is now. Keeping the edit resubmits it against the current version;
+// discarding it reloads the app.
+func ConflictDialog(current store.Todo, draft store.Draft, data PageData) Node {
+	mine := store.Todo{
+		ID:            current.ID,
+		Title:         draft.Title,
+		Description:   draft.Description,
+		Priority:      draft.Priority,
+		DueAt:         draft.DueAt,
+		DueZone:       draft.DueZone,
+		RemindMinutes: draft.RemindMinutes,
+		Tags:          draft.Tags,
+		Recurrence:    draft.Recurrence,
+		ProjectID:     draft.ProjectID,
+	}
+	if mine.ProjectID == "" {
+		mine.ProjectID = store.InboxProjectID
+	}
+
+	fields := []struct {
+		label string
+		value func(store.Todo) string
+	}{
+		{"Title", func(t store.Todo) string { return t.Title }},
+		{"Description", func(t store.Todo) string { return t.Description }},
+		{"Priority", func(t store.Todo) string { return capitalize(string(t.Priority)) }},
+		{"Due", conflictDue},
+		{"Reminder", conflictRemind},
+		{"Tags", func(t store.Todo) string { return strings.Join(t.Tags, ", ") }},
+		{"Repeat", conflictRepeat},
+		{"List", func(t store.Todo) string { return data.projectName(t.ProjectID) }},
+	}
+
+	rows := []DivArg{
+		Class("grid grid-cols-3 gap-x-3 gap-y-1 text-sm"),
+		Span(Class("text-xs font-medium uppercase text-muted-foreground"), T("Field")),
+		Span(Class("text-xs font-medium uppercase text-muted-foreground"), T("Your changes")),
+		Span(Class("text-xs font-medium uppercase text-muted-foreground"), T(fmt.Sprintf("Current (v%d)", current.Version))),
+	}
+	for _, field := range fields {
+		theirs, ours := field.value(current), field.value(mine)
+		valueClass := "min-w-0 break-words"
+		if theirs != ours {
+			valueClass += " font-medium text-primary"
+		}
+		rows = append(rows,
+			Span(Class("text-muted-foreground"), T(field.label)),
+			Span(Class(valueClass), T(orDash(ours))),
+			Span(Class(valueClass), T(orDash(theirs))),
+		)
+	}
+
+	return Dialog(
+		Id("conflict-dialog"),
+		Class("modal"),
+		Role("alertdialog"),
+		Aria("labelledby", "conflict-title"),
+		Child(
+			Div(
+				Class("space-y-5 p-6"),
+				Div(
+					Class("flex items-center gap-2"),
+					icons.TriangleAlert(icons.Size("20"), Class("text-destructive")),
+					H2(Id("conflict-title"), Class("text-xl font-semibold"), T("Someone else changed this task")),
+				),
+				P(Class("text-sm text-muted-foreground"), T("Your edit was based on an older version. Keep your changes to overwrite theirs, or discard yours and see the current task.")),
+				Div(rows...),
+				Form(
+					Class("flex gap-2 pt-2"),
+					Custom("hx-post", todoPath(current.ID, "update")),
+					Custom("hx-target", "#todo-results"),
+					Custom("hx-swap", "outerHTML"),
+					Custom("hx-include", viewStateInclude),
+					Custom("hx-on::afterRequest", "if(event.detail.successful){ todoDialogs.closeDialog('conflict-dialog'); }"),
+					Input(InputType("hidden"), InputName("version"), InputValue(fmt.Sprintf("%d", current.Version))),
+					Input(InputType("hidden"), InputName("title"), InputValue(mine.Title)),
+					Input(InputType("hidden"), InputName("description"), InputValue(mine.Description)),
+					Input(InputType("hidden"), InputName("priority"), InputValue(string(mine.Priority))),
+					Input(InputType("hidden"), InputName("due"), InputValue(dueInputValue(mine))),
+					Input(InputType("hidden"), InputName("due_zone"), InputValue(mine.DueZone)),
+					Input(InputType("hidden"), InputName("remind"), InputValue(remindInputValue(mine))),
+					Input(InputType("hidden"), InputName("tags"), InputValue(strings.Join(mine.Tags, ", "))),
+					Input(InputType("hidden"), InputName("repeat"), InputValue("custom")),
+					Input(InputType("hidden"), InputName("rrule"), InputValue(mine.Recurrence)),
+					Input(InputType("hidden"), InputName("project"), InputValue(mine.ProjectID)),
+					Button(
+						ButtonType("button"),
+						Class("flex-1 rounded-lg border border-border px-4 py-2 text-sm font-medium hover:bg-muted"),
+						Custom("hx-get", partialURL(data.query())),
+						Custom("hx-target", "#todo-app"),
+						Custom("hx-swap", "outerHTML"),
+						T("Discard mine"),
+					),
+					Button(
+						ButtonType("submit"),
+						Class("flex-1 rounded-lg bg-primary px-4 py-2 text-sm font-medium text-primary-foreground hover:bg-primary/90"),
+						T("Keep mine"),
+					),
+				),
+			),
+		),
+	)
+}
+
+func conflictDue(t store.Todo) string {
+	due, ok := t.DueLocal()
+	if !ok {
+		return ""
+	}
+	return due.Format("Jan 02, 15:04 MST")
+}
+
+func conflictRemind(t store.Todo) string {
+	if t.RemindMinutes == nil {
+		return ""
+	}
+	return capitalize(store.DescribeRemind(*t.RemindMinutes))
+}
+
+func conflictRepeat(t store.Todo) string {
+	if rule, ok := t.Repeat(); ok {
+		return rule.Describe()
+	}
+	return ""
+}
+
+// projectName returns the name of a list, or its id if it no longer exists.
+func (d PageData) projectName(id string) string {
+	for _, project := range d.Projects {
+		if project.ID == id {
+			return project.Name
+		}
+	}
+	return id
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "—"
+	}
+	return value
+}
+
+func RenderConflictDialog(current store.Todo, draft store.Draft, data PageData) string {
+	return Render(ConflictDialog(current, draft, data))
+}
@@ -0,0 +1,160 @@
+package views
+
+import (
+	"fmt"
+	"net/url"
+
+	"modern_todo_plain/internal/store"
+
+	. "github.com/plainkit/html"
+)
+
+// TodoForm is the state of a todo form shown again after a rejected
+// submission: the submitted values and an error message per field. The zero
+// value is an empty form.
+type TodoForm struct {
+	Values url.Values
+	// Errors is keyed by field: title, description, priority, due, remind,
+	// recurrence or project.
+	Errors map[string]string
+}
+
+func (f TodoForm) value(name string) string {
+	return f.Values.Get(name)
+}
+
+const inputClass = "w-full rounded-lg border bg-background px-3 py-2 text-sm focus:outline-none focus:ring-2 focus:ring-primary"
+
+// fieldError renders the message for one field below its input. It is always
+// present, empty when the field is valid, so inputs can point at it with
+// aria-describedby.
+func fieldError(prefix, field, message string) Node {
+	return P(
+		Id(fieldErrorID(prefix, field)),
+		Class("field-error text-xs text-destructive"),
+		T(message),
+	)
+}
+
+func fieldErrorID(prefix, field string) string {
+	return prefix + "-" + field + "-error"
+}
+
+// invalid marks an input as failing validation and links it to its message.
+func invalid(prefix, field, message string) []Global {
+	return []Global{
+		Aria("invalid", fmt.Sprintf("%t", message != "")),
+		Aria("describedby", fieldErrorID(prefix, field)),
+	}
+}
+
+func titleField(prefix string, form TodoForm) Node {
+	message := form.Errors["title"]
+	args := []InputArg{
+		Id(prefix + "-title"),
+		InputName("title"),
+		InputValue(form.value("title")),
+		Required(),
+		Placeholder("What needs to be done?"),
+		Class(inputClass),
+	}
+	for _, attr := range invalid(prefix, "title", message) {
+		args = append(args, attr)
+	}
+
+	return FormLabel(
+		Class("block space-y-2"),
+		For(prefix+"-title"),
+		Span(Class("text-sm font-medium"), T("Title")),
+		Input(args...),
+		fieldError(prefix, "title", message),
+	)
+}
+
+func descriptionField(prefix string, form TodoForm) Node {
+	message := form.Errors["description"]
+	args := []TextareaArg{
+		Id(prefix + "-description"),
+		TextareaName("description"),
+		Rows(3),
+		Placeholder("Add more details... (optional)"),
+		Class(inputClass),
+		T(form.value("description")),
+	}
+	for _, attr := range invalid(prefix, "description", message) {
+		args = append(args, attr)
+	}
+
+	return FormLabel(
+		Class("block space-y-2"),
+		For(prefix+"-description"),
+		Span(Class("text-sm font-medium"), T("Description")),
+		Textarea(args...),
+		fieldError(prefix, "description", message),
+	)
+}
+
+func priorityField(prefix string, form TodoForm) Node {
+	message := form.Errors["priority"]
+	selected := form.value("priority")
+	if selected == "" {
+		selected = string(store.PriorityMedium)
+	}
+
+	args := []SelectArg{
+		Id(prefix + "-priority"),
+		Custom("name", "priority"),
+		Class("w-full rounded-lg border bg-background px-3 py-2 text-sm"),
+	}
+	for _, priority := range []store.Priority{store.PriorityLow, store.PriorityMedium, store.PriorityHigh} {
+		if string(priority) == selected {
+			args = append(args, Child(Option(Custom("value", string(priority)), Selected(), T(capitalize(string(priority))))))
+		} else {
+			args = append(args, Child(Option(Custom("value", string(priority)), T(capitalize(string(priority))))))
+		}
+	}
+	for _, attr := range invalid(prefix, "priority", message) {
+		args = append(args, attr)
+	}
+
+	return FormLabel(
+		Class("block space-y-2"),
+		For(prefix+"-priority"),
+		Span(Class("text-sm font-medium"), T("Priority")),
+		Select(args...),
+		fieldError(prefix, "priority", message),
+	)
+}
+
+// dueField renders the due date input plus a hidden field that the dialog
+// controller fills with the browser's time zone.
+func dueField(prefix string, form TodoForm) Node {
+	message := form.Errors["due"]
+	args := []InputArg{
+		Id(prefix + "-due"),
+		InputName("due"),
+		InputType("datetime-local"),
+		InputValue(form.value("due")),
+		Class(inputClass),
+	}
+	for _, attr := range invalid(prefix, "due", message) {
+		args = append(args, attr)
+	}
+
+	return FormLabel(
+		Class("block space-y-2"),
+		For(prefix+"-due"),
+		Span(Class("text-sm font-medium"), T("Due date")),
+		Input(args...),
+		Input(InputType("hidden"), Id(prefix+"-due-zone"), InputName("due_zone"), InputValue(form.value("due_zone"))),
+		fieldError(prefix, "due", message),
+	)
+}
+
+func RenderAddTodoForm(data PageData, form TodoForm) string {
+	return Render(AddTodoForm(data, form))
+}
+
+func RenderEditTodoForm(data PageData, form TodoForm) string {
+	return Render(EditTodoForm(data, form))
+}
@@ -0,0 +1,114 @@
+package views
+
+import (
+	. "github.com/plainkit/html"
+	icons "github.com/plainkit/icons/lucide"
+)
+
+// Toast is the notice shown after a mutation. Undone toasts offer Redo,
+// Failed ones no action, and every other toast offers Undo.
+type Toast struct {
+	Message string
+	Undone  bool
+	// Failed marks an undo or redo that could not be applied.
+	Failed bool
+}
+
+const toastCSS = `
+#todo-toast { animation: todo-toast-in 150ms ease-out; }
+#todo-toast.is-hiding { opacity: 0; transition: opacity 300ms; }
+@keyframes todo-toast-in { from { opacity: 0; transform: translateY(8px); } }`
+
+// toastJS hides the toast after a few seconds and maps Ctrl+Z / Ctrl+Shift+Z
+// (or Ctrl+Y) to the hidden undo and redo buttons. Shortcuts are ignored while
+// typing so text fields keep their native undo.
+const toastJS = `(() => {
+  let timer = null;
+
+  const scheduleHide = () => {
+    clearTimeout(timer);
+    const toast = document.getElementById('todo-toast');
+    if (!toast) return;
+    timer = setTimeout(() => toast.classList.add('is-hiding'), 6000);
+  };
+
+  document.addEventListener('keydown', (event) => {
+    if (!(event.ctrlKey || event.metaKey) || event.altKey) return;
+    const target = event.target;
+    if (target.closest && target.closest('input, textarea, select, [contenteditable="true"]')) return;
+    if (document.querySelector('dialog[open]')) return;
+
+    const key = event.key.toLowerCase();
+    let button = null;
+    if (key === 'z' && !event.shiftKey) button = document.getElementById('todo-undo');
+    if ((key === 'z' && event.shiftKey) || key === 'y') button = document.getElementById('todo-redo');
+    if (!button) return;
+    event.preventDefault();
+    button.click();
+  });
+
+  window.addEventListener('htmx:afterSwap', scheduleHide);
+  window.addEventListener('load', scheduleHide);
+})();`
+
+// historyControls renders the toast for the last mutation plus the hidden
+// undo and redo buttons behind the keyboard shortcuts.
+func historyControls(data PageData, attrs ...Global) Node {
+	args := []DivArg{
+		Id("todo-history"),
+		Class("fixed bottom-6 left-1/2 z-20 -translate-x-1/2"),
+		Aria("live", "polite"),
+		historyButton("todo-undo", "/todos/undo", "Undo"),
+		historyButton("todo-redo", "/todos/redo", "Redo"),
+	}
+	if data.Toast != nil {
+		args = append(args, Child(toast(*data.Toast)))
+	}
+	args = append(args, divArgs(attrs...)...)
+	return Div(args...).WithAssets(toastCSS, toastJS, "todo-toast")
+}
+
+func toast(t Toast) Node {
+	action := historyAction("/todos/undo", "Undo", icons.Undo2(icons.Size("14")))
+	message := t.Message
+	if t.Undone {
+		action = historyAction("/todos/redo", "Redo", icons.Redo2(icons.Size("14")))
+		message = "Undone: " + message
+	}
+	args := []DivArg{
+		Id("todo-toast"),
+		Class("flex items-center gap-4 rounded-xl border border-border bg-card px-4 py-3 text-sm text-card-foreground shadow-lg"),
+		Role("status"),
+		Span(T(message)),
+	}
+	if !t.Failed {
+		args = append(args, Child(action))
+	}
+	return Div(args...)
+}
+
+func historyAction(path, label string, icon Node) Node {
+	return Button(
+		ButtonType("button"),
+		Class("inline-flex items-center gap-1 rounded-lg px-2 py-1 text-sm font-semibold text-primary hover:bg-primary/10"),
+		Custom("hx-post", path),
+		Custom("hx-target", "#todo-app"),
+		Custom("hx-swap", "outerHTML"),
+		Custom("hx-include", viewStateInclude),
+		icon,
+		T(label),
+	)
+}
+
+func historyButton(id, path, label string) Node {
+	return Button(
+		ButtonType("button"),
+		Id(id),
+		Class("hidden"),
+		Aria("label", label),
+		Custom("hx-post", path),
+		Custom("hx-target", "#todo-app"),
+		Custom("hx-swap", "outerHTML"),
+		Custom("hx-include", viewStateInclude),
+	)
+}
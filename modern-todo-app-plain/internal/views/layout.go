@@ -12,24 +12,62 @@ const dialogController = `(() => {
     return filter.value || filter.getAttribute('value') || '';
   };
 
+  const browserZone = () => {
+    try {
+      return Intl.DateTimeFormat().resolvedOptions().timeZone || '';
+    } catch (_) {
+      return '';
+    }
+  };
+
   const applyFilterToForms = (value) => {
     const addFilter = document.querySelector('#add-form input[name="filter"]');
     if (addFilter) addFilter.value = value;
     const editFilter = document.getElementById('edit-filter');
     if (editFilter) editFilter.value = value;
+    const addZone = document.getElementById('add-due-zone');
+    if (addZone) addZone.value = browserZone();
+    const importZone = document.getElementById('import-due-zone');
+    if (importZone) importZone.value = browserZone();
   };
 
   const prefillEditForm = (dataset, filterValue) => {
     const form = document.getElementById('edit-form');
     if (!form) return;
-    const idField = document.getElementById('edit-id');
-    if (idField) idField.value = dataset.editId || '';
+    form.setAttribute('hx-post', '/todos/' + encodeURIComponent(dataset.editId) + '/update');
+    htmx.process(form);
+    const versionField = document.getElementById('edit-version');
+    if (versionField) versionField.value = dataset.editVersion || '';
     const titleField = document.getElementById('edit-title');
     if (titleField) titleField.value = dataset.editTitle || '';
     const descField = document.getElementById('edit-description');
     if (descField) descField.value = dataset.editDescription || '';
     const priorityField = document.getElementById('edit-priority');
     if (priorityField) priorityField.value = dataset.editPriority || 'medium';
+    const dueField = document.getElementById('edit-due');
+    if (dueField) dueField.value = dataset.editDue || '';
+    const dueZoneField = document.getElementById('edit-due-zone');
+    if (dueZoneField) dueZoneField.value = dataset.editDueZone || browserZone();
+    const remindField = document.getElementById('edit-remind');
+    if (remindField) {
+      const minutes = dataset.editRemind || '';
+      if (!Array.from(remindField.options).some(opt => opt.value === minutes)) {
+        remindField.add(new Option(minutes + ' minutes before', minutes));
+      }
+      remindField.value = minutes;
+    }
+    const tagsField = document.getElementById('edit-tags');
+    if (tagsField) tagsField.value = dataset.editTags || '';
+    const repeatField = document.getElementById('edit-repeat');
+    const ruleField = document.getElementById('edit-rrule');
+    if (repeatField) {
+      const rule = dataset.editRecurrence || '';
+      const preset = Array.from(repeatField.options).some(opt => opt.value === rule);
+      repeatField.value = preset ? rule : 'custom';
+      if (ruleField) ruleField.value = preset ? '' : rule;
+    }
+    const projectField = document.getElementById('edit-project');
+    if (projectField && dataset.editProject) projectField.value = dataset.editProject;
     const filterField = document.getElementById('edit-filter');
     if (filterField) filterField.value = filterValue;
   };
@@ -69,6 +107,18 @@ const dialogController = `(() => {
     });
   };
 
+  // Sidebar links to pages that group by day, such as the stats page, carry
+  // the browser's time zone, which the server cannot know otherwise.
+  const applyZoneToLinks = () => {
+    const zone = browserZone();
+    if (!zone) return;
+    document.querySelectorAll('a[data-zone-link]').forEach(link => {
+      const url = new URL(link.getAttribute('href'), window.location.origin);
+      url.searchParams.set('zone', zone);
+      link.setAttribute('href', url.pathname + url.search);
+    });
+  };
+
   const closeDialog = (id) => {
     const dialog = document.getElementById(id);
     if (dialog && typeof dialog.close === 'function') {
@@ -80,13 +130,36 @@ const dialogController = `(() => {
     closeDialog,
   };
 
-  window.addEventListener('htmx:afterSwap', (event) => {
-    if (event.target.id === 'todo-app') {
-      requestAnimationFrame(setupDialogControls);
+  // Rejected forms come back as 422 with the form re-rendered and retargeted
+  // over the one submitted. Swap it in, but leave the request failed so the
+  // dialog stays open.
+  document.addEventListener('htmx:beforeSwap', (event) => {
+    const xhr = event.detail.xhr;
+    if (xhr.status === 422 && xhr.getResponseHeader('HX-Retarget')) {
+      event.detail.shouldSwap = true;
     }
   });
 
+  // Checklists are re-rendered closed; reopen the ones the user had expanded.
+  let openChecklists = [];
+  window.addEventListener('htmx:beforeSwap', () => {
+    openChecklists = Array.from(document.querySelectorAll('details.subtasks[open]')).map(el => el.id);
+  });
+
+  window.addEventListener('htmx:afterSwap', (event) => {
+    openChecklists.forEach(id => {
+      const details = document.getElementById(id);
+      if (details) details.open = true;
+    });
+    // Cards and the list can be swapped on their own; bind any new buttons.
+    requestAnimationFrame(setupDialogControls);
+    requestAnimationFrame(applyZoneToLinks);
+    const invalidField = document.querySelector('dialog[open] [aria-invalid="true"]');
+    if (invalidField) invalidField.focus();
+  });
+
   window.addEventListener('load', setupDialogControls);
+  window.addEventListener('load', applyZoneToLinks);
 })();`
 
 func Layout(title string, content Node) Component {
@@ -111,13 +184,34 @@ func Layout(title string, content Node) Component {
 				content,
 			),
 			Script(ScriptSrc("https://unpkg.com/htmx.org@1.9.12"), Defer()),
+			Script(ScriptSrc("https://unpkg.com/htmx.org@1.9.12/dist/ext/sse.js"), Defer()),
 			Script(UnsafeText(dialogController)),
 			assets.JS(),
 		),
 	)
 }
 
-func AppHeader() Node {
+// subPage lays out a page opened from the sidebar: a link back to the tasks,
+// a heading and the content.
+func subPage(title string, icon Node, content Node) Component {
+	return Layout(title+" · Modern Todo", Div(
+		Class("mx-auto max-w-3xl space-y-6 p-6"),
+		A(
+			Custom("href", "/"),
+			Class("inline-flex items-center gap-1 text-sm text-muted-foreground hover:text-foreground"),
+			icons.ArrowLeft(icons.Size("14")),
+			T("Back to tasks"),
+		),
+		Div(
+			Class("flex items-center gap-3"),
+			Div(Class("flex h-10 w-10 items-center justify-center rounded-xl bg-primary/10"), icon),
+			H1(Class("text-2xl font-semibold tracking-tight"), T(title)),
+		),
+		content,
+	))
+}
+
+func AppHeader(title, search string, rejected *RejectedForm) Node {
 	return Header(
 		Class("border-b border-border bg-card/80 backdrop-blur sticky top-0 z-10"),
 		Div(
@@ -129,19 +223,40 @@ func AppHeader() Node {
 					icons.ListChecks(icons.Size("22"), Class("text-primary")),
 				),
 				Div(
-					H1(Class("text-2xl font-semibold tracking-tight"), T("Tasks")),
+					H1(Class("text-2xl font-semibold tracking-tight"), T(title)),
 					P(Class("text-sm text-muted-foreground"), T("Organize your day, achieve your goals")),
 				),
 			),
-			Button(
-				Id("open-add-dialog"),
-				Class("inline-flex items-center gap-2 rounded-lg bg-primary px-4 py-2 text-sm font-medium text-primary-foreground shadow transition hover:bg-primary/90"),
-				Data("dialog-target", "add-dialog"),
-				Span(Class("inline-flex h-5 w-5 items-center justify-center"),
-					icons.Plus(icons.Size("16"), Class("text-primary-foreground")),
+			Div(
+				Class("flex items-center gap-3"),
+				searchBox(search),
+				Button(
+					ButtonType("button"),
+					Class("hidden h-9 w-9 items-center justify-center rounded-lg border border-border text-muted-foreground hover:bg-muted sm:inline-flex"),
+					Title("Keyboard shortcuts (?)"),
+					Aria("label", "Keyboard shortcuts"),
+					Data("dialog-target", "shortcut-dialog"),
+					icons.Keyboard(icons.Size("16")),
+				),
+				Button(
+					ButtonType("button"),
+					Class("inline-flex h-9 w-9 items-center justify-center rounded-lg border border-border text-muted-foreground hover:bg-muted"),
+					Title("Import & export"),
+					Aria("label", "Import and export"),
+					Data("dialog-target", "transfer-dialog"),
+					icons.ArrowDownUp(icons.Size("16")),
+				),
+				Button(
+					Id("open-add-dialog"),
+					Class("inline-flex items-center gap-2 rounded-lg bg-primary px-4 py-2 text-sm font-medium text-primary-foreground shadow transition hover:bg-primary/90"),
+					Data("dialog-target", "add-dialog"),
+					Span(Class("inline-flex h-5 w-5 items-center justify-center"),
+						icons.Plus(icons.Size("16"), Class("text-primary-foreground")),
+					),
+					T("Add Task"),
 				),
-				T("Add Task"),
 			),
 		),
+		quickAddBox(rejected),
 	)
 }
@@ -0,0 +1,87 @@
+package views
+
+import (
+	. "github.com/plainkit/html"
+)
+
+// liveJS applies changes made in other tabs. Each "todos-changed" event
+// carries a store.Change; changes at or below the revision this page rendered
+// came from its own requests and are skipped. Edits that leave a card in place
+// re-render just that card, anything else re-renders the app. Updates wait
+// while a dialog is open, a card is being dragged or a field has focus, so
+// nobody loses what they are typing.
+const liveJS = `(() => {
+  let pending = null;
+
+  const renderedRevision = () => {
+    const marker = document.getElementById('todo-revision');
+    return marker ? Number(marker.dataset.revision || 0) : 0;
+  };
+
+  const viewParams = () => {
+    const params = new URLSearchParams();
+    document.querySelectorAll('.todo-view-state').forEach(el => {
+      if (el.name && el.value) params.append(el.name, el.value);
+    });
+    return params;
+  };
+
+  const busy = () => {
+    if (document.querySelector('dialog[open], .is-dragging')) return true;
+    const active = document.activeElement;
+    return !!(active && active.closest && active.closest('#todo-app') &&
+      active.matches('input, textarea, select, [contenteditable="true"]'));
+  };
+
+  const merge = (a, b) => a ? {
+    revision: Math.max(a.revision, b.revision),
+    ids: Array.from(new Set([...(a.ids || []), ...(b.ids || [])])),
+    full: a.full || b.full,
+  } : b;
+
+  const apply = () => {
+    const change = pending;
+    if (!change || busy()) return;
+    pending = null;
+    if (change.revision <= renderedRevision()) return;
+
+    const params = viewParams();
+    if (change.full || params.get('q')) {
+      htmx.ajax('GET', '/?' + params.toString() + '&partial=app', { target: '#todo-app', swap: 'outerHTML' });
+      return;
+    }
+    (change.ids || []).forEach(id => {
+      if (!document.getElementById('todo-' + id)) return;
+      htmx.ajax('GET', '/todos/' + encodeURIComponent(id) + '/card?' + params.toString(), { target: '#todo-' + id, swap: 'outerHTML' });
+    });
+    const marker = document.getElementById('todo-revision');
+    if (marker) marker.dataset.revision = String(change.revision);
+  };
+
+  document.addEventListener('htmx:sseMessage', (event) => {
+    const message = event.detail;
+    if (!message || message.type !== 'todos-changed') return;
+    try {
+      pending = merge(pending, JSON.parse(message.data));
+    } catch (_) {
+      return;
+    }
+    apply();
+  });
+
+  document.addEventListener('focusout', () => setTimeout(apply, 0));
+  document.addEventListener('close', () => setTimeout(apply, 0), true);
+  document.addEventListener('dragend', () => setTimeout(apply, 0));
+})();`
+
+// liveSync subscribes the page to /events through the htmx SSE extension.
+// It sits outside #todo-app so re-rendering the app keeps the connection.
+func liveSync() Node {
+	return Div(
+		Id("todo-live"),
+		Class("hidden"),
+		Custom("hx-ext", "sse"),
+		Custom("sse-connect", "/events"),
+		Div(Custom("sse-swap", "todos-changed"), Custom("hx-swap", "none")),
+	).WithAssets("", liveJS, "todo-live")
+}
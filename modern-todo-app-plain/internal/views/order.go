@@ -0,0 +1,165 @@
+package views
+
+import (
+	"modern_todo_plain/internal/store"
+
+	. "github.com/plainkit/html"
+	icons "github.com/plainkit/icons/lucide"
+)
+
+const reorderCSS = `
+[data-todo-id].is-dragging { opacity: 0.5; }
+[data-todo-id].drop-before { box-shadow: 0 -3px 0 0 var(--primary); }
+[data-todo-id].drop-after { box-shadow: 0 3px 0 0 var(--primary); }`
+
+// reorderJS enables native drag and drop from a card's grip handle. Dropping
+// points #reorder-form at the dragged todo, fills in its neighbour and fires
+// the form's "reorder" trigger so htmx posts the move.
+const reorderJS = `(() => {
+  let dragged = null;
+
+  const clearMarkers = () => {
+    document.querySelectorAll('.drop-before, .drop-after').forEach(el => {
+      el.classList.remove('drop-before', 'drop-after');
+    });
+  };
+
+  document.addEventListener('pointerdown', (event) => {
+    const handle = event.target.closest('[data-drag-handle]');
+    if (!handle) return;
+    const card = handle.closest('[data-todo-id]');
+    if (card) card.draggable = true;
+  });
+
+  document.addEventListener('dragstart', (event) => {
+    const card = event.target.closest && event.target.closest('[data-todo-id]');
+    if (!card || !card.draggable) return;
+    dragged = card;
+    card.classList.add('is-dragging');
+    event.dataTransfer.effectAllowed = 'move';
+    event.dataTransfer.setData('text/plain', card.dataset.todoId);
+  });
+
+  document.addEventListener('dragover', (event) => {
+    if (!dragged) return;
+    const card = event.target.closest('[data-todo-id]');
+    if (!card || card === dragged) return;
+    event.preventDefault();
+    const rect = card.getBoundingClientRect();
+    const after = event.clientY > rect.top + rect.height / 2;
+    clearMarkers();
+    card.classList.add(after ? 'drop-after' : 'drop-before');
+  });
+
+  document.addEventListener('drop', (event) => {
+    if (!dragged) return;
+    const card = event.target.closest('[data-todo-id]');
+    const form = document.getElementById('reorder-form');
+    if (!card || card === dragged || !form) return;
+    event.preventDefault();
+    const after = card.classList.contains('drop-after');
+    form.setAttribute('hx-post', '/todos/' + encodeURIComponent(dragged.dataset.todoId) + '/reorder');
+    htmx.process(form);
+    form.querySelector('[name="before"]').value = after ? '' : card.dataset.todoId;
+    form.querySelector('[name="after"]').value = after ? card.dataset.todoId : '';
+    htmx.trigger(form, 'reorder');
+  });
+
+  document.addEventListener('dragend', () => {
+    if (dragged) {
+      dragged.draggable = false;
+      dragged.classList.remove('is-dragging');
+    }
+    dragged = null;
+    clearMarkers();
+  });
+})();`
+
+var sortOptions = []struct {
+	key   store.Sort
+	label string
+}{
+	{store.SortManual, "Manual order"},
+	{store.SortCreated, "Newest first"},
+	{store.SortPriority, "Priority"},
+	{store.SortDue, "Due date"},
+}
+
+var filterTitles = map[store.Filter]string{
+	store.FilterAll:       "All Tasks",
+	store.FilterActive:    "Active",
+	store.FilterCompleted: "Completed",
+	store.FilterOverdue:   "Overdue",
+	store.FilterThisWeek:  "Due this week",
+	store.FilterTrash:     "Trash",
+}
+
+// listHeader shows the current view title and the sort selector. The select
+// is part of the view state so mutations keep the chosen order.
+func listHeader(data PageData) Node {
+	selectArgs := []SelectArg{
+		Id("todo-sort"),
+		Custom("name", "sort"),
+		Class("todo-view-state rounded-lg border bg-background px-3 py-1.5 text-sm"),
+		Aria("label", "Sort tasks"),
+		Custom("hx-get", "/?partial=app"),
+		Custom("hx-trigger", "change"),
+		Custom("hx-target", "#todo-app"),
+		Custom("hx-swap", "outerHTML"),
+		Custom("hx-include", viewStateInclude),
+	}
+	for _, opt := range sortOptions {
+		current := opt.key == data.Sort || (data.Sort == "" && opt.key == store.SortManual)
+		if current {
+			selectArgs = append(selectArgs, Child(Option(Custom("value", string(opt.key)), Selected(), T(opt.label))))
+		} else {
+			selectArgs = append(selectArgs, Child(Option(Custom("value", string(opt.key)), T(opt.label))))
+		}
+	}
+
+	controls := []DivArg{Class("flex items-center gap-3")}
+	controls = append(controls, clearCompletedButton(data))
+	if data.Filter == store.FilterTrash && len(data.Todos) > 0 {
+		controls = append(controls, emptyTrashButton())
+	}
+	controls = append(controls,
+		FormLabel(
+			Class("flex items-center gap-2 text-sm text-muted-foreground"),
+			icons.ArrowUpDown(icons.Size("14")),
+			Select(selectArgs...),
+		),
+	)
+
+	return Div(
+		Class("flex items-center justify-between gap-3"),
+		H2(
+			Class("text-lg font-semibold"),
+			T(filterTitles[data.Filter]),
+			resultCount(data.Total),
+		),
+		Div(controls...),
+	)
+}
+
+func dragHandle() Node {
+	return Span(
+		Class("mt-1 cursor-grab text-muted-foreground/60 hover:text-muted-foreground active:cursor-grabbing"),
+		Data("drag-handle", "true"),
+		Title("Drag to reorder"),
+		Aria("hidden", "true"),
+		icons.GripVertical(icons.Size("16")),
+	)
+}
+
+func reorderForm() Node {
+	return Form(
+		Id("reorder-form"),
+		Class("hidden"),
+		Custom("hx-trigger", "reorder"),
+		Custom("hx-target", "#todo-app"),
+		Custom("hx-swap", "outerHTML"),
+		Custom("hx-include", viewStateInclude),
+		Input(InputType("hidden"), InputName("before")),
+		Input(InputType("hidden"), InputName("after")),
+	)
+}
@@ -0,0 +1,43 @@
+package views
+
+import (
+	"net/url"
+	"strings"
+
+	"modern_todo_plain/internal/store"
+
+	. "github.com/plainkit/html"
+)
+
+// moreTodos ends a list that has more pages. It loads the next page when it
+// scrolls into view, or when clicked, and is replaced by the cards it loaded
+// and, if there are more, another moreTodos. The cursor carries the view
+// state, so the page does not need to send it along.
+func moreTodos(next *store.Cursor) Node {
+	return Div(
+		Id("todo-more"),
+		Class("flex justify-center pt-2"),
+		Button(
+			ButtonType("button"),
+			Class("inline-flex items-center gap-2 rounded-lg border border-border px-4 py-2 text-sm font-medium text-muted-foreground hover:bg-muted"),
+			Custom("hx-get", "/todos/more?cursor="+url.QueryEscape(next.String())),
+			Custom("hx-trigger", "click, revealed"),
+			Custom("hx-target", "#todo-more"),
+			Custom("hx-swap", "outerHTML"),
+			T("Load more"),
+		),
+	)
+}
+
+// RenderMoreTodos renders the cards of a following page, for the infinite
+// scroll, followed by the control that loads the page after it.
+func RenderMoreTodos(data PageData) string {
+	var b strings.Builder
+	for _, todo := range data.Todos {
+		b.WriteString(Render(todoCard(todo, data)))
+	}
+	if data.Next != nil {
+		b.WriteString(Render(moreTodos(data.Next)))
+	}
+	return b.String()
+}
@@ -0,0 +1,272 @@
+package views
+
+import (
+	"fmt"
+	"net/url"
+
+	"modern_todo_plain/internal/store"
+
+	. "github.com/plainkit/html"
+	icons "github.com/plainkit/icons/lucide"
+)
+
+// projectIcon renders one of store.ProjectIcons, falling back to a plain list.
+func projectIcon(name string, args ...SvgArg) Node {
+	switch name {
+	case "inbox":
+		return icons.Inbox(args...)
+	case "briefcase":
+		return icons.Briefcase(args...)
+	case "house":
+		return icons.House(args...)
+	case "shopping-cart":
+		return icons.ShoppingCart(args...)
+	case "book":
+		return icons.Book(args...)
+	case "heart":
+		return icons.Heart(args...)
+	case "star":
+		return icons.Star(args...)
+	default:
+		return icons.List(args...)
+	}
+}
+
+// currentProject returns the list being viewed, if any.
+func (d PageData) currentProject() (store.ProjectSummary, bool) {
+	for _, project := range d.Projects {
+		if project.ID == d.ProjectID {
+			return project, true
+		}
+	}
+	return store.ProjectSummary{}, false
+}
+
+// projectSidebarSection links to every list with its count of open todos.
+// "All lists" clears the list selection but keeps the other view state.
+func projectSidebarSection(data PageData) Node {
+	header := Div(
+		Class("flex items-center justify-between text-sm"),
+		Span(Class("text-sidebar-foreground"), T("Lists")),
+		Button(
+			ButtonType("button"),
+			Class("text-xs font-medium text-muted-foreground hover:text-sidebar-foreground"),
+			Data("dialog-target", "list-dialog"),
+			T("Manage"),
+		),
+	)
+
+	total := 0
+	for _, project := range data.Projects {
+		total += project.Stats.Active
+	}
+
+	linkArgs := []DivArg{
+		Class("space-y-1"),
+		Child(projectLink(data, "", "All lists", icons.Layers(icons.Size("14")), "slate", total)),
+	}
+	for _, project := range data.Projects {
+		linkArgs = append(linkArgs,
+			Child(projectLink(data, project.ID, project.Name, projectIcon(project.Icon, icons.Size("14")), project.Color, project.Stats.Active)),
+		)
+	}
+
+	return Div(
+		Class("space-y-3 border-t border-sidebar-muted pt-4"),
+		header,
+		Div(linkArgs...),
+	)
+}
+
+func projectLink(data PageData, id, name string, icon Node, color string, active int) Node {
+	isActive := id == data.ProjectID
+	target := data.query()
+	target.ProjectID = id
+	linkClass := "project-link"
+	if isActive {
+		linkClass += " is-active"
+	}
+
+	return Button(
+		ButtonType("button"),
+		Class(linkClass),
+		Aria("pressed", fmt.Sprintf("%t", isActive)),
+		Custom("hx-get", partialURL(target)),
+		Custom("hx-target", "#todo-app"),
+		Custom("hx-swap", "outerHTML"),
+		Custom("hx-push-url", ListURL(target)),
+		Span(
+			Class("flex items-center gap-2"),
+			Span(Class("project-icon project-"+color), Child(icon)),
+			Span(T(name)),
+		),
+		projectCount(id, active),
+	)
+}
+
+// projectField is the list picker in the add and edit dialogs. Unless another
+// list is selected it defaults to the list being viewed, or the inbox from
+// "All lists".
+func projectField(prefix string, data PageData, selected, message string) Node {
+	if selected == "" {
+		selected = data.ProjectID
+	}
+	if selected == "" {
+		selected = store.InboxProjectID
+	}
+
+	selectArgs := []SelectArg{
+		Id(prefix + "-project"),
+		Custom("name", "project"),
+		Class("w-full rounded-lg border bg-background px-3 py-2 text-sm"),
+	}
+	for _, project := range data.Projects {
+		if project.ID == selected {
+			selectArgs = append(selectArgs, Child(Option(Custom("value", project.ID), Selected(), T(project.Name))))
+		} else {
+			selectArgs = append(selectArgs, Child(Option(Custom("value", project.ID), T(project.Name))))
+		}
+	}
+
+	return FormLabel(
+		Class("block space-y-2"),
+		For(prefix+"-project"),
+		Span(Class("text-sm font-medium"), T("List")),
+		Select(selectArgs...),
+		fieldError(prefix, "project", message),
+	)
+}
+
+// ProjectDialog creates new lists and edits or deletes existing ones. The
+// inbox can be renamed but not deleted.
+func ProjectDialog(data PageData) Node {
+	rows := []DivArg{Class("space-y-2 max-h-80 overflow-y-auto")}
+	for _, project := range data.Projects {
+		rows = append(rows, Child(projectManagerRow(project.Project)))
+	}
+
+	return Dialog(
+		Id("list-dialog"),
+		Class("modal"),
+		Child(
+			Div(
+				Class("space-y-4 p-6"),
+				Div(
+					Class("flex items-center gap-2"),
+					icons.Layers(icons.Size("20"), Class("text-muted-foreground")),
+					H2(Class("text-xl font-semibold"), T("Lists")),
+				),
+				Div(rows...),
+				Form(
+					Class("space-y-3 border-t border-border pt-4"),
+					Custom("hx-post", "/lists/create"),
+					Custom("hx-target", "#todo-app"),
+					Custom("hx-swap", "outerHTML"),
+					Custom("hx-include", viewStateInclude),
+					Custom("hx-on::afterRequest", "if(event.detail.successful){ todoDialogs.closeDialog('list-dialog'); }"),
+					H3(Class("text-sm font-medium"), T("New list")),
+					Div(
+						Class("flex items-center gap-2"),
+						Input(
+							InputName("name"),
+							Required(),
+							Placeholder("List name"),
+							Aria("label", "List name"),
+							Class("flex-1 rounded-lg border bg-background px-3 py-1.5 text-sm focus:outline-none focus:ring-2 focus:ring-primary"),
+						),
+						projectColorSelect("slate"),
+						projectIconSelect("list"),
+						Button(
+							ButtonType("submit"),
+							Class("inline-flex items-center gap-1 rounded-lg bg-primary px-3 py-1.5 text-xs font-medium text-primary-foreground hover:bg-primary/90"),
+							icons.FolderPlus(icons.Size("14")),
+							T("Create"),
+						),
+					),
+				),
+				Div(
+					Class("flex pt-2"),
+					Button(
+						ButtonType("button"),
+						Class("flex-1 rounded-lg border border-border px-4 py-2 text-sm font-medium hover:bg-muted"),
+						Data("close-dialog", "list-dialog"),
+						T("Done"),
+					),
+				),
+			),
+		),
+	)
+}
+
+func projectManagerRow(project store.Project) Node {
+	args := []FormArg{
+		Class("flex items-center gap-2"),
+		Custom("hx-post", "/lists/"+url.PathEscape(project.ID)+"/update"),
+		Custom("hx-target", "#todo-app"),
+		Custom("hx-swap", "outerHTML"),
+		Custom("hx-include", viewStateInclude),
+		Span(Class("project-icon project-"+project.Color), Child(projectIcon(project.Icon, icons.Size("14")))),
+		Input(
+			InputName("name"),
+			InputValue(project.Name),
+			Required(),
+			Aria("label", "Rename "+project.Name),
+			Class("flex-1 rounded-lg border bg-background px-3 py-1.5 text-sm focus:outline-none focus:ring-2 focus:ring-primary"),
+		),
+		projectColorSelect(project.Color),
+		projectIconSelect(project.Icon),
+		Button(
+			ButtonType("submit"),
+			Class("rounded-lg border border-border px-3 py-1.5 text-xs font-medium hover:bg-muted"),
+			T("Save"),
+		),
+	}
+	if project.ID != store.InboxProjectID {
+		args = append(args,
+			Button(
+				ButtonType("button"),
+				Class("inline-flex h-8 w-8 items-center justify-center rounded-lg text-muted-foreground hover:bg-destructive/10 hover:text-destructive"),
+				Aria("label", "Delete "+project.Name),
+				Custom("hx-post", "/lists/"+url.PathEscape(project.ID)+"/delete"),
+				Custom("hx-target", "#todo-app"),
+				Custom("hx-swap", "outerHTML"),
+				Custom("hx-confirm", fmt.Sprintf("Delete %q? Its tasks move to the Inbox.", project.Name)),
+				Custom("hx-include", viewStateInclude),
+				icons.Trash2(icons.Size("16")),
+			),
+		)
+	}
+	return Form(args...)
+}
+
+func projectColorSelect(current string) Node {
+	args := []SelectArg{
+		Custom("name", "color"),
+		Aria("label", "Colour"),
+		Class("rounded-lg border bg-background px-2 py-1.5 text-xs"),
+	}
+	for _, color := range store.ProjectColors {
+		if color == current {
+			args = append(args, Child(Option(Custom("value", color), Selected(), T(capitalize(color)))))
+		} else {
+			args = append(args, Child(Option(Custom("value", color), T(capitalize(color)))))
+		}
+	}
+	return Select(args...)
+}
+
+func projectIconSelect(current string) Node {
+	args := []SelectArg{
+		Custom("name", "icon"),
+		Aria("label", "Icon"),
+		Class("rounded-lg border bg-background px-2 py-1.5 text-xs"),
+	}
+	for _, icon := range store.ProjectIcons {
+		if icon == current {
+			args = append(args, Child(Option(Custom("value", icon), Selected(), T(capitalize(icon)))))
+		} else {
+			args = append(args, Child(Option(Custom("value", icon), T(capitalize(icon)))))
+		}
+	}
+	return Select(args...)
+}
@@ -0,0 +1,111 @@
+package views
+
+import (
+	"modern_todo_plain/internal/store"
+
+	. "github.com/plainkit/html"
+	icons "github.com/plainkit/icons/lucide"
+)
+
+// quickAddJS sends the browser's time zone with quick-add requests, so words
+// like "tomorrow 5pm" are read in local time, and clears the box after a
+// todo is added.
+const quickAddJS = `(() => {
+  const browserZone = () => {
+    try {
+      return Intl.DateTimeFormat().resolvedOptions().timeZone || '';
+    } catch (_) {
+      return '';
+    }
+  };
+
+  document.addEventListener('htmx:configRequest', (event) => {
+    if (event.detail.elt.closest('#quick-add-form')) {
+      event.detail.parameters.due_zone = browserZone();
+    }
+  });
+
+  document.addEventListener('htmx:afterRequest', (event) => {
+    const form = event.detail.elt;
+    if (form.id !== 'quick-add-form' || !event.detail.successful) return;
+    form.reset();
+    const preview = document.getElementById('quick-add-preview');
+    if (preview) preview.innerHTML = '';
+  });
+})();`
+
+// quickAddBox adds a todo from one line of text. The preview below it shows
+// how the text will be read as the user types, or, after a plain post was
+// rejected, the text kept and why.
+func quickAddBox(rejected *RejectedForm) Node {
+	var text string
+	preview := []DivArg{Id("quick-add-preview"), Aria("live", "polite")}
+	if rejected != nil && rejected.Name == "quick" {
+		text = rejected.value("text")
+		preview = append(preview, Child(QuickAddPreview(rejected.Draft, rejected.Errors)))
+	}
+	return Form(
+		Id("quick-add-form"),
+		Class("px-6 pb-4"),
+		Custom("hx-post", "/todos/quick"),
+		Custom("hx-target", "#todo-results"),
+		Custom("hx-swap", "outerHTML"),
+		Custom("hx-include", viewStateInclude),
+		Div(
+			Class("relative"),
+			Span(
+				Class("pointer-events-none absolute inset-y-0 left-3 flex items-center text-muted-foreground"),
+				icons.Sparkles(icons.Size("16")),
+			),
+			Input(
+				Id("quick-add"),
+				InputName("text"),
+				InputValue(text),
+				Placeholder(`Quick add: "Pay invoice tomorrow 5pm !high #finance"`),
+				Aria("label", "Quick add a task"),
+				Aria("describedby", "quick-add-preview"),
+				Custom("autocomplete", "off"),
+				Class("w-full rounded-lg border bg-background py-2 pl-9 pr-3 text-sm focus:outline-none focus:ring-2 focus:ring-primary"),
+				Custom("hx-get", "/todos/quick/preview"),
+				Custom("hx-trigger", "input changed delay:200ms"),
+				Custom("hx-target", "#quick-add-preview"),
+				Custom("hx-swap", "innerHTML"),
+				Custom("hx-sync", "closest form:abort"),
+			),
+		),
+		Div(preview...),
+	).WithAssets("", quickAddJS, "todo-quick-add")
+}
+
+// QuickAddPreview lists the fields read from the quick-add text: the title,
+// due date, priority and tags, or the problems that stop it being added.
+func QuickAddPreview(draft store.Draft, errors map[string]string) Node {
+	args := []DivArg{Class("mt-2 flex flex-wrap items-center gap-2 text-xs text-muted-foreground")}
+
+	if message, ok := errors["title"]; ok {
+		args = append(args, Span(Class("text-destructive"), T(capitalize(message))))
+	} else {
+		args = append(args, Span(Class("font-medium text-foreground"), T(draft.Title)))
+	}
+	if draft.DueAt != nil {
+		args = append(args, Span(
+			Class("inline-flex items-center gap-1 rounded-full bg-muted px-2 py-0.5"),
+			icons.Clock(icons.Size("12")),
+			T(draft.DueAt.Format("Mon Jan 02, 15:04")),
+		))
+	}
+	args = append(args, Span(Class(priorityBadgeClass(draft.Priority)), T(capitalize(string(draft.Priority)))))
+	for _, tag := range draft.Tags {
+		args = append(args, Span(Class(tagChipClass(false)), T("#"+tag)))
+	}
+	for _, field := range []string{"description", "priority", "recurrence", "project"} {
+		if message, ok := errors[field]; ok {
+			args = append(args, Span(Class("text-destructive"), T(capitalize(message))))
+		}
+	}
+	return Div(args...)
+}
+
+func RenderQuickAddPreview(draft store.Draft, errors map[string]string) string {
+	return Render(QuickAddPreview(draft, errors))
+}
@@ -0,0 +1,61 @@
+package views
+
+import (
+	. "github.com/plainkit/html"
+)
+
+// repeatPresets are the common schedules offered in the todo dialogs. Anything
+// else can be entered as a custom RRULE.
+var repeatPresets = []struct {
+	rule  string
+	label string
+}{
+	{"", "Does not repeat"},
+	{"FREQ=DAILY", "Every day"},
+	{"FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR", "Every weekday"},
+	{"FREQ=WEEKLY", "Every week"},
+	{"FREQ=MONTHLY", "Every month"},
+	{"FREQ=YEARLY", "Every year"},
+	{"custom", "Custom rule..."},
+}
+
+func repeatField(prefix string, form TodoForm) Node {
+	message := form.Errors["recurrence"]
+	selected := form.value("repeat")
+	options := []SelectArg{
+		Id(prefix + "-repeat"),
+		Custom("name", "repeat"),
+		Class("w-full rounded-lg border bg-background px-3 py-2 text-sm"),
+	}
+	for _, preset := range repeatPresets {
+		if preset.rule == selected {
+			options = append(options, Child(Option(Custom("value", preset.rule), Selected(), T(preset.label))))
+		} else {
+			options = append(options, Child(Option(Custom("value", preset.rule), T(preset.label))))
+		}
+	}
+
+	ruleArgs := []InputArg{
+		Id(prefix + "-rrule"),
+		InputName("rrule"),
+		InputValue(form.value("rrule")),
+		Placeholder("Custom RRULE, e.g. FREQ=WEEKLY;INTERVAL=2;BYDAY=MO"),
+		Aria("label", "Custom recurrence rule"),
+		Class("w-full rounded-lg border bg-background px-3 py-2 font-mono text-xs focus:outline-none focus:ring-2 focus:ring-primary"),
+	}
+	for _, attr := range invalid(prefix, "recurrence", message) {
+		ruleArgs = append(ruleArgs, attr)
+	}
+
+	return Div(
+		Class("space-y-2"),
+		FormLabel(
+			Class("block space-y-2"),
+			For(prefix+"-repeat"),
+			Span(Class("text-sm font-medium"), T("Repeat")),
+			Select(options...),
+		),
+		Input(ruleArgs...),
+		fieldError(prefix, "recurrence", message),
+	)
+}
@@ -0,0 +1,147 @@
+package views
+
+import (
+	"strconv"
+
+	"modern_todo_plain/internal/store"
+
+	. "github.com/plainkit/html"
+	icons "github.com/plainkit/icons/lucide"
+)
+
+// remindPresets are the reminder choices in the add and edit dialogs, in
+// minutes before the due date. Other offsets, set through the API, are added
+// to the list when a todo uses them.
+var remindPresets = []int{0, 15, 60, 24 * 60}
+
+// remindField renders the reminder choice. It needs a due date, which the
+// store checks.
+func remindField(prefix string, form TodoForm) Node {
+	message := form.Errors["remind"]
+	selected := form.value("remind")
+
+	options := []string{""}
+	custom := selected != ""
+	for _, minutes := range remindPresets {
+		value := strconv.Itoa(minutes)
+		options = append(options, value)
+		if value == selected {
+			custom = false
+		}
+	}
+	if custom {
+		options = append(options, selected)
+	}
+
+	args := []SelectArg{
+		Id(prefix + "-remind"),
+		Custom("name", "remind"),
+		Class("w-full rounded-lg border bg-background px-3 py-2 text-sm"),
+	}
+	for _, value := range options {
+		optionArgs := []OptionArg{Custom("value", value), T(remindOptionLabel(value))}
+		if value == selected {
+			optionArgs = append(optionArgs, Selected())
+		}
+		args = append(args, Child(Option(optionArgs...)))
+	}
+	for _, attr := range invalid(prefix, "remind", message) {
+		args = append(args, attr)
+	}
+
+	return FormLabel(
+		Class("block space-y-2"),
+		For(prefix+"-remind"),
+		Span(Class("text-sm font-medium"), T("Reminder")),
+		Select(args...),
+		fieldError(prefix, "remind", message),
+	)
+}
+
+func remindOptionLabel(value string) string {
+	minutes, err := strconv.Atoi(value)
+	if err != nil {
+		return "No reminder"
+	}
+	return capitalize(store.DescribeRemind(minutes))
+}
+
+// remindInputValue is the todo's reminder as the remind field submits it.
+func remindInputValue(todo store.Todo) string {
+	if todo.RemindMinutes == nil {
+		return ""
+	}
+	return strconv.Itoa(*todo.RemindMinutes)
+}
+
+// reminderBadge shows when a todo's reminder goes off; it is highlighted once
+// it has.
+func reminderBadge(todo store.Todo, data PageData) Node {
+	class := "inline-flex items-center gap-1"
+	icon := icons.Bell(icons.Size("12"))
+	if todo.ReminderDue(data.Now) {
+		class += " font-medium text-amber-600"
+		icon = icons.BellRing(icons.Size("12"))
+	}
+	return Span(
+		Class(class),
+		icon,
+		T("Remind "+store.DescribeRemind(*todo.RemindMinutes)),
+	)
+}
+
+// remindersPanel lists the todos, in any list, whose reminders have gone off.
+// It checks for new ones every minute, so reminders show up on a page that is
+// left open, and is empty when there are none.
+func remindersPanel(data PageData, attrs ...Global) Node {
+	args := []DivArg{
+		Id("todo-reminders"),
+		Class("mb-4 space-y-2 empty:hidden"),
+		Role("status"),
+		Aria("live", "polite"),
+		Custom("hx-get", "/todos/reminders"),
+		Custom("hx-trigger", "every 60s"),
+		Custom("hx-swap", "outerHTML"),
+	}
+	for _, todo := range data.Reminders {
+		args = append(args, Child(reminderRow(todo, data)))
+	}
+	args = append(args, divArgs(attrs...)...)
+	return Div(args...)
+}
+
+func reminderRow(todo store.Todo, data PageData) Node {
+	due, _ := todo.DueLocal()
+	return Div(
+		Class("flex items-center gap-3 rounded-xl border border-amber-300 bg-amber-50 px-4 py-3 text-sm text-amber-900"),
+		icons.BellRing(icons.Size("16"), Class("shrink-0")),
+		Div(
+			Class("flex-1"),
+			Span(Class("font-semibold"), T(todo.Title)),
+			Span(Class("ml-2 text-xs"), T(dueLabel(todo, due, data.Now))),
+		),
+		Button(
+			ButtonType("button"),
+			Class("rounded-lg px-2 py-1 text-sm font-semibold hover:bg-amber-100"),
+			Custom("hx-post", todoPath(todo.ID, "toggle")),
+			Custom("hx-target", "#"+CardID(todo.ID)),
+			Custom("hx-swap", "outerHTML"),
+			Custom("hx-include", viewStateInclude),
+			T("Done"),
+		),
+		Button(
+			ButtonType("button"),
+			Class("rounded-lg px-2 py-1 text-sm font-semibold hover:bg-amber-100"),
+			Custom("hx-post", todoPath(todo.ID, "reminder/dismiss")),
+			Custom("hx-target", "#todo-app"),
+			Custom("hx-swap", "outerHTML"),
+			Custom("hx-include", viewStateInclude),
+			T("Dismiss"),
+		),
+	)
+}
+
+// RenderReminders renders the reminders panel on its own, for its polling.
+func RenderReminders(data PageData) string {
+	return Render(remindersPanel(data))
+}
@@ -0,0 +1,89 @@
+package views
+
+import (
+	"strings"
+	"unicode"
+
+	. "github.com/plainkit/html"
+	icons "github.com/plainkit/icons/lucide"
+)
+
+// searchBox refreshes the todo list as the user types. Requests are debounced
+// and only swap #todo-results so the input keeps focus.
+func searchBox(search string) Node {
+	return Div(
+		Class("relative hidden sm:block"),
+		Span(
+			Class("pointer-events-none absolute inset-y-0 left-3 flex items-center text-muted-foreground"),
+			icons.Search(icons.Size("16")),
+		),
+		Input(
+			Id("todo-search"),
+			InputType("search"),
+			InputName("q"),
+			InputValue(search),
+			Placeholder("Search tasks..."),
+			Aria("label", "Search tasks"),
+			Class("todo-view-state w-64 rounded-lg border bg-background py-2 pl-9 pr-3 text-sm focus:outline-none focus:ring-2 focus:ring-primary"),
+			Custom("hx-get", "/?partial=list"),
+			Custom("hx-trigger", "input changed delay:300ms, search"),
+			Custom("hx-target", "#todo-results"),
+			Custom("hx-swap", "outerHTML"),
+			Custom("hx-include", viewStateInclude),
+		),
+	)
+}
+
+// highlight wraps the part of each word that matches a search term in <mark>.
+// terms must already be tokenized (lowercase words).
+func highlight(text string, terms []string) Node {
+	if len(terms) == 0 {
+		return Span(T(text))
+	}
+
+	args := []SpanArg{}
+	runes := []rune(text)
+	plainStart := 0
+	for i := 0; i < len(runes); {
+		if !isWordRune(runes[i]) {
+			i++
+			continue
+		}
+		end := i
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+
+		if n := matchedPrefix(runes[i:end], terms); n > 0 {
+			if plainStart < i {
+				args = append(args, T(string(runes[plainStart:i])))
+			}
+			args = append(args, Mark(Class("search-hit"), T(string(runes[i:i+n]))))
+			plainStart = i + n
+		}
+		i = end
+	}
+	if plainStart < len(runes) {
+		args = append(args, T(string(runes[plainStart:])))
+	}
+	return Span(args...)
+}
+
+// matchedPrefix returns the length in runes of the longest term that prefixes
+// word, or 0.
+func matchedPrefix(word []rune, terms []string) int {
+	lower := strings.ToLower(string(word))
+	best := 0
+	for _, term := range terms {
+		if strings.HasPrefix(lower, term) {
+			if n := len([]rune(term)); n > best {
+				best = n
+			}
+		}
+	}
+	return best
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,157 @@
+package views
+
+import (
+	. "github.com/plainkit/html"
+	icons "github.com/plainkit/icons/lucide"
+)
+
+const shortcutsCSS = `
+[data-todo-id].is-focused { outline: 2px solid var(--ring); outline-offset: 2px; }
+kbd.shortcut-key { display: inline-block; min-width: 1.5rem; padding: 0 0.375rem; border: 1px solid var(--border); border-bottom-width: 2px; border-radius: 0.375rem; font: 500 0.75rem/1.25rem ui-monospace, monospace; text-align: center; }`
+
+// shortcutsJS is the keyboard layer. Keys act on the focused card, which is
+// remembered by id so it survives htmx swaps. Shortcuts are ignored while
+// typing, with a modifier held, or while another dialog is open.
+const shortcutsJS = `(() => {
+  let focusedId = null;
+
+  const cards = () => Array.from(document.querySelectorAll('#todo-list [data-todo-id]'));
+  const focused = () => focusedId && document.querySelector('#todo-list [data-todo-id="' + CSS.escape(focusedId) + '"]');
+
+  const focusCard = (card) => {
+    document.querySelectorAll('[data-todo-id].is-focused').forEach(el => el.classList.remove('is-focused'));
+    if (!card) {
+      focusedId = null;
+      return;
+    }
+    focusedId = card.dataset.todoId;
+    card.classList.add('is-focused');
+    card.tabIndex = -1;
+    card.focus({ preventScroll: true });
+    card.scrollIntoView({ block: 'nearest' });
+  };
+
+  const move = (step) => {
+    const list = cards();
+    if (list.length === 0) return;
+    const index = list.indexOf(focused());
+    const next = index === -1 ? (step > 0 ? 0 : list.length - 1) : Math.min(Math.max(index + step, 0), list.length - 1);
+    focusCard(list[next]);
+  };
+
+  const clickIn = (card, selector) => {
+    const button = card && card.querySelector(selector);
+    if (button) button.click();
+  };
+
+  const filters = { '1': 'all', '2': 'active', '3': 'completed' };
+
+  document.addEventListener('keydown', (event) => {
+    if (event.ctrlKey || event.metaKey || event.altKey) return;
+    const target = event.target;
+    if (target.closest && target.closest('input, textarea, select, [contenteditable="true"]')) return;
+
+    const help = document.getElementById('shortcut-dialog');
+    const openDialog = document.querySelector('dialog[open]');
+    if (event.key === '?') {
+      if (!help || (openDialog && openDialog !== help)) return;
+      event.preventDefault();
+      if (help.open) help.close(); else help.showModal();
+      return;
+    }
+    if (openDialog) return;
+
+    switch (event.key) {
+      case 'j':
+        move(1);
+        break;
+      case 'k':
+        move(-1);
+        break;
+      case 'x':
+        clickIn(focused(), '[hx-post$="/toggle"]:not([hx-post*="/subtasks/"])');
+        break;
+      case 'e':
+        clickIn(focused(), '[data-dialog-target="edit-dialog"]');
+        break;
+      case 'n': {
+        const add = document.getElementById('open-add-dialog');
+        if (add) add.click();
+        break;
+      }
+      case '/': {
+        const search = document.getElementById('todo-search');
+        if (search) search.focus();
+        break;
+      }
+      default: {
+        const filter = filters[event.key];
+        const button = filter && document.querySelector('[data-shortcut-filter="' + filter + '"]');
+        if (!button) return;
+        button.click();
+      }
+    }
+    event.preventDefault();
+  });
+
+  // Keep the focus mark on the same card when the list is re-rendered.
+  document.addEventListener('htmx:afterSwap', () => {
+    const card = focused();
+    if (card) card.classList.add('is-focused');
+  });
+})();`
+
+// shortcuts lists the keyboard shortcuts shown in the help dialog.
+var shortcuts = []struct {
+	keys  []string
+	label string
+}{
+	{[]string{"j", "k"}, "Next / previous task"},
+	{[]string{"x"}, "Complete or reopen the selected task"},
+	{[]string{"e"}, "Edit the selected task"},
+	{[]string{"n"}, "New task"},
+	{[]string{"/"}, "Search"},
+	{[]string{"1", "2", "3"}, "All, active or completed tasks"},
+	{[]string{"Ctrl", "Z"}, "Undo"},
+	{[]string{"Ctrl", "Shift", "Z"}, "Redo"},
+	{[]string{"?"}, "Show or hide this list"},
+}
+
+// ShortcutDialog lists the keyboard shortcuts; it carries the script that
+// implements them.
+func ShortcutDialog() Node {
+	rows := []DivArg{Class("grid grid-cols-[auto_1fr] items-center gap-x-4 gap-y-2 text-sm")}
+	for _, shortcut := range shortcuts {
+		keys := []SpanArg{Class("flex gap-1")}
+		for _, key := range shortcut.keys {
+			keys = append(keys, Child(Kbd(Class("shortcut-key"), T(key))))
+		}
+		rows = append(rows, Span(keys...), Span(T(shortcut.label)))
+	}
+
+	return Dialog(
+		Id("shortcut-dialog"),
+		Class("modal"),
+		Aria("labelledby", "shortcut-title"),
+		Child(
+			Div(
+				Class("space-y-4 p-6"),
+				Div(
+					Class("flex items-center gap-2"),
+					icons.Keyboard(icons.Size("20"), Class("text-muted-foreground")),
+					H2(Id("shortcut-title"), Class("text-xl font-semibold"), T("Keyboard shortcuts")),
+				),
+				Div(rows...),
+				Div(
+					Class("flex pt-2"),
+					Button(
+						ButtonType("button"),
+						Class("flex-1 rounded-lg border border-border px-4 py-2 text-sm font-medium hover:bg-muted"),
+						Data("close-dialog", "shortcut-dialog"),
+						T("Done"),
+					),
+				),
+			),
+		),
+	).WithAssets(shortcutsCSS, shortcutsJS, "todo-shortcuts")
+}
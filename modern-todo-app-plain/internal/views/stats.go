@@ -0,0 +1,249 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"modern_todo_plain/internal/store"
+
+	. "github.com/plainkit/html"
+	icons "github.com/plainkit/icons/lucide"
+)
+
+// statsCSS colours the charts. SVG paints come from here rather than utility
+// classes so the charts do not depend on the generated stylesheet.
+const statsCSS = `
+.chart { width: 100%; height: 10rem; overflow: visible; }
+.chart-bar { fill: var(--primary); opacity: 0.85; }
+.chart-bar.is-today { opacity: 1; }
+.chart-axis { fill: none; stroke: var(--border); stroke-width: 1; vector-effect: non-scaling-stroke; }
+.chart-line { fill: none; stroke-width: 2; stroke-linejoin: round; vector-effect: non-scaling-stroke; }
+.chart-line.priority-high, .chart-key.priority-high { stroke: #ef4444; background: #ef4444; }
+.chart-line.priority-medium, .chart-key.priority-medium { stroke: #eab308; background: #eab308; }
+.chart-line.priority-low, .chart-key.priority-low { stroke: #3b82f6; background: #3b82f6; }`
+
+// The drawing area of every chart, in SVG user units. The charts stretch to
+// their container.
+const (
+	chartWidth  = 560
+	chartHeight = 160
+)
+
+var chartPriorities = []store.Priority{store.PriorityHigh, store.PriorityMedium, store.PriorityLow}
+
+// StatsPage shows completions per day and week, the time todos take to
+// finish, open todos by priority and completion streaks.
+func StatsPage(analytics store.Analytics) Component {
+	recent := 0
+	perDay := make([]int, len(analytics.Days))
+	dayLabels := make([]string, len(analytics.Days))
+	for i, day := range analytics.Days {
+		recent += day.Completed
+		perDay[i] = day.Completed
+		dayLabels[i] = day.Date.Format("Mon 2")
+	}
+	perWeek := make([]int, len(analytics.Weeks))
+	weekLabels := make([]string, len(analytics.Weeks))
+	for i, week := range analytics.Weeks {
+		perWeek[i] = week.Completed
+		weekLabels[i] = week.Start.Format("Jan 2")
+	}
+
+	return subPage("Stats", icons.ChartColumn(icons.Size("22"), Class("text-primary")), Div(
+		Class("space-y-6"),
+		Div(
+			Class("grid grid-cols-2 gap-4 md:grid-cols-4"),
+			statTile(fmt.Sprintf("Completed in %d days", store.AnalyticsDays), fmt.Sprintf("%d", recent)),
+			statTile("Average time to complete", formatSpan(analytics.AverageCompletion, analytics.Timed)),
+			statTile("Current streak", plural(analytics.CurrentStreak, "day")),
+			statTile("Longest streak", plural(analytics.LongestStreak, "day")),
+		),
+		chartCard("Completed per day", barChart("Tasks completed per day", perDay, dayLabels)),
+		chartCard("Completed per week", barChart("Tasks completed per week", perWeek, weekLabels)),
+		chartCard("Open tasks by priority", burndownChart(analytics.Days)),
+	).WithAssets(statsCSS, "", "todo-stats"))
+}
+
+func statTile(label, value string) Node {
+	return Div(
+		Class("rounded-xl border border-border bg-card p-4"),
+		P(Class("text-xs text-muted-foreground"), T(label)),
+		P(Class("mt-1 text-2xl font-semibold"), T(value)),
+	)
+}
+
+func chartCard(title string, chart Node) Node {
+	return Section(
+		Class("space-y-3 rounded-xl border border-border bg-card p-5"),
+		H2(Class("text-sm font-semibold"), T(title)),
+		chart,
+	)
+}
+
+// barChart draws one bar per value, labelled underneath. The last bar is the
+// current period.
+func barChart(description string, values []int, labels []string) Node {
+	top := chartMax(values)
+	slot := float64(chartWidth) / float64(len(values))
+
+	svg := []SvgArg{
+		Class("chart"),
+		Custom("viewBox", fmt.Sprintf("0 0 %d %d", chartWidth, chartHeight)),
+		Custom("preserveAspectRatio", "none"),
+		Role("img"),
+		Aria("label", fmt.Sprintf("%s: %s", description, joinCounts(values, labels))),
+	}
+	for i, value := range values {
+		height := float64(value) / float64(top) * chartHeight
+		class := "chart-bar"
+		if i == len(values)-1 {
+			class += " is-today"
+		}
+		svg = append(svg, Child(Rect(
+			Class(class),
+			X(chartNumber(float64(i)*slot+slot*0.15)),
+			Y(chartNumber(chartHeight-height)),
+			RectWidth(chartNumber(slot*0.7)),
+			RectHeight(chartNumber(height)),
+			Rx("3"),
+		)))
+	}
+	svg = append(svg, Child(Path(Class("chart-axis"), D(fmt.Sprintf("M0 %dH%d", chartHeight, chartWidth)))))
+
+	return Div(
+		Class("space-y-1"),
+		chartScale(top),
+		Svg(svg...),
+		chartLabels(labels, values),
+	)
+}
+
+// burndownChart draws, per priority, how many todos were open at the end of
+// each day.
+func burndownChart(days []store.DayStats) Node {
+	var all []int
+	for _, day := range days {
+		for _, priority := range chartPriorities {
+			all = append(all, day.Open[priority])
+		}
+	}
+	top := chartMax(all)
+	step := float64(chartWidth) / float64(max(len(days)-1, 1))
+
+	svg := []SvgArg{
+		Class("chart"),
+		Custom("viewBox", fmt.Sprintf("0 0 %d %d", chartWidth, chartHeight)),
+		Custom("preserveAspectRatio", "none"),
+		Role("img"),
+	}
+	key := []DivArg{Class("flex flex-wrap gap-4 text-xs text-muted-foreground")}
+	var summary []string
+	for _, priority := range chartPriorities {
+		points := make([]string, len(days))
+		for i, day := range days {
+			y := chartHeight - float64(day.Open[priority])/float64(top)*chartHeight
+			points[i] = chartNumber(float64(i)*step) + "," + chartNumber(y)
+		}
+		class := "chart-line priority-" + string(priority)
+		svg = append(svg, Child(Polyline(Class(class), Points(strings.Join(points, " ")))))
+
+		now := 0
+		if len(days) > 0 {
+			now = days[len(days)-1].Open[priority]
+		}
+		summary = append(summary, fmt.Sprintf("%s %d", priority, now))
+		key = append(key, Span(
+			Class("inline-flex items-center gap-1.5"),
+			Span(Class("chart-key h-2 w-4 rounded-full "+"priority-"+string(priority))),
+			T(fmt.Sprintf("%s (%d open)", capitalize(string(priority)), now)),
+		))
+	}
+	svg = append(svg,
+		Aria("label", "Open tasks by priority over the last two weeks; now "+strings.Join(summary, ", ")),
+		Child(Path(Class("chart-axis"), D(fmt.Sprintf("M0 %dH%d", chartHeight, chartWidth)))),
+	)
+
+	labels := make([]string, len(days))
+	for i, day := range days {
+		labels[i] = day.Date.Format("Mon 2")
+	}
+	return Div(
+		Class("space-y-2"),
+		chartScale(top),
+		Svg(svg...),
+		chartLabels(labels, nil),
+		Div(key...),
+	)
+}
+
+func chartScale(top int) Node {
+	return P(Class("text-right text-xs text-muted-foreground"), T(fmt.Sprintf("max %d", top)))
+}
+
+// chartLabels names each bar or point, with its value when values is set.
+// Labels alternate on narrow charts so they stay readable.
+func chartLabels(labels []string, values []int) Node {
+	args := []DivArg{
+		Class("grid text-center text-[10px] text-muted-foreground"),
+		Style(fmt.Sprintf("grid-template-columns: repeat(%d, minmax(0, 1fr))", len(labels))),
+	}
+	for i, label := range labels {
+		text := label
+		if values != nil {
+			text = fmt.Sprintf("%s · %d", label, values[i])
+		}
+		class := "truncate"
+		if len(labels) > 8 && i%2 == 1 {
+			class += " invisible sm:visible"
+		}
+		args = append(args, Span(Class(class), Title(text), T(label)))
+	}
+	return Div(args...)
+}
+
+// chartMax is the top of a chart's scale: the largest value, at least 1.
+func chartMax(values []int) int {
+	top := 1
+	for _, value := range values {
+		top = max(top, value)
+	}
+	return top
+}
+
+func chartNumber(v float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", v), "0"), ".")
+}
+
+func joinCounts(values []int, labels []string) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = fmt.Sprintf("%s %d", labels[i], value)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatSpan renders a duration in days, hours or minutes, coarsely.
+func formatSpan(d time.Duration, samples int) string {
+	switch {
+	case samples == 0:
+		return "—"
+	case d >= 48*time.Hour:
+		return fmt.Sprintf("%.1f days", d.Hours()/24)
+	case d >= time.Hour:
+		return fmt.Sprintf("%.1f hours", d.Hours())
+	default:
+		return plural(int(d.Minutes()), "minute")
+	}
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+func RenderStatsPage(analytics store.Analytics) string {
+	return Render(StatsPage(analytics))
+}
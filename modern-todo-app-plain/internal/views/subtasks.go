@@ -0,0 +1,123 @@
+package views
+
+import (
+	"fmt"
+	"net/url"
+
+	"modern_todo_plain/internal/store"
+
+	. "github.com/plainkit/html"
+	icons "github.com/plainkit/icons/lucide"
+)
+
+// subtaskChecklist renders a todo's checklist as a collapsible section with a
+// progress summary and an inline form for new items.
+func subtaskChecklist(todo store.Todo) Node {
+	done, total := todo.SubtaskProgress()
+
+	summary := "Add checklist"
+	if total > 0 {
+		summary = fmt.Sprintf("%d/%d done", done, total)
+	}
+
+	items := []UlArg{Class("space-y-1")}
+	for _, sub := range todo.Subtasks {
+		items = append(items, Child(subtaskItem(todo.ID, sub)))
+	}
+
+	summaryArgs := []SummaryArg{
+		Child(icons.ListTodo(icons.Size("14"))),
+		T(summary),
+	}
+	if total > 0 {
+		summaryArgs = append(summaryArgs, Child(subtaskProgressBar(done, total)))
+	}
+
+	return Details(
+		Id("subtasks-"+todo.ID),
+		Class("subtasks"),
+		Child(Summary(summaryArgs...)),
+		Child(
+			Div(
+				Class("space-y-2 pt-2"),
+				Ul(items...),
+				Form(
+					Class("flex items-center gap-2"),
+					Custom("hx-post", todoPath(todo.ID, "subtasks")),
+					Custom("hx-target", "#todo-app"),
+					Custom("hx-swap", "outerHTML"),
+					Custom("hx-include", viewStateInclude),
+					Input(
+						InputName("subtask"),
+						Required(),
+						Placeholder("Add a step..."),
+						Aria("label", "New checklist item"),
+						Class("flex-1 rounded-lg border bg-background px-3 py-1.5 text-sm focus:outline-none focus:ring-2 focus:ring-primary"),
+					),
+					Button(
+						ButtonType("submit"),
+						Class("inline-flex h-8 w-8 items-center justify-center rounded-lg text-muted-foreground hover:bg-muted"),
+						Aria("label", "Add checklist item"),
+						icons.Plus(icons.Size("16")),
+					),
+				),
+			),
+		),
+	)
+}
+
+func subtaskItem(todoID string, sub store.Subtask) Node {
+	path := todoPath(todoID, "subtasks/"+url.PathEscape(sub.ID))
+
+	return Li(
+		Class("group/subtask flex items-center gap-2 text-sm"),
+		Button(
+			ButtonType("button"),
+			Class(subtaskToggleClasses(sub.Done)),
+			Aria("label", "Toggle "+sub.Title),
+			Aria("pressed", fmt.Sprintf("%t", sub.Done)),
+			Custom("hx-post", path+"/toggle"),
+			Custom("hx-target", "#todo-app"),
+			Custom("hx-swap", "outerHTML"),
+			Custom("hx-include", viewStateInclude),
+			icons.Check(icons.Size("12")),
+		),
+		Span(Class(subtaskTitleClasses(sub.Done)), T(sub.Title)),
+		Button(
+			ButtonType("button"),
+			Class("ml-auto inline-flex h-6 w-6 items-center justify-center rounded text-muted-foreground opacity-0 hover:text-destructive group-hover/subtask:opacity-100 focus:opacity-100"),
+			Aria("label", "Remove "+sub.Title),
+			Custom("hx-post", path+"/delete"),
+			Custom("hx-target", "#todo-app"),
+			Custom("hx-swap", "outerHTML"),
+			Custom("hx-include", viewStateInclude),
+			icons.X(icons.Size("12")),
+		),
+	)
+}
+
+func subtaskProgressBar(done, total int) Node {
+	percent := done * 100 / total
+	return Span(
+		Class("ml-2 inline-block h-1.5 w-16 rounded-full bg-muted align-middle"),
+		Span(
+			Class("block h-1.5 rounded-full bg-primary"),
+			Style(fmt.Sprintf("width: %d%%", percent)),
+		),
+	)
+}
+
+func subtaskToggleClasses(done bool) string {
+	base := "flex h-4 w-4 shrink-0 items-center justify-center rounded border"
+	if done {
+		return base + " border-primary bg-primary text-primary-foreground"
+	}
+	return base + " border-muted-foreground/40 text-transparent"
+}
+
+func subtaskTitleClasses(done bool) string {
+	if done {
+		return "line-through text-muted-foreground"
+	}
+	return "text-card-foreground"
+}
@@ -0,0 +1,198 @@
+package views
+
+import (
+	"fmt"
+	"net/url"
+
+	"modern_todo_plain/internal/store"
+
+	. "github.com/plainkit/html"
+	icons "github.com/plainkit/icons/lucide"
+)
+
+// tagSidebarSection lists every tag as a toggle. Selecting several tags narrows
+// the list to todos carrying all of them.
+func tagSidebarSection(data PageData, attrs ...Global) Node {
+	section := []DivArg{Id("tag-section"), Class("space-y-3 border-t border-sidebar-muted pt-4")}
+	section = append(section, divArgs(attrs...)...)
+
+	header := Div(
+		Class("flex items-center justify-between text-sm"),
+		Span(Class("text-sidebar-foreground"), T("Tags")),
+		Button(
+			ButtonType("button"),
+			Class("text-xs font-medium text-muted-foreground hover:text-sidebar-foreground"),
+			Data("dialog-target", "tag-dialog"),
+			T("Manage"),
+		),
+	)
+
+	if len(data.TagStats) == 0 {
+		section = append(section, header, P(Class("text-xs text-muted-foreground"), T("Add tags to a task to group it here.")))
+		return Div(section...)
+	}
+
+	chipArgs := []DivArg{Class("flex flex-wrap gap-2")}
+	for _, tag := range data.TagStats {
+		selected := containsTag(data.Tags, tag.Name)
+		target := data.query()
+		target.Tags = toggleTag(data.Tags, tag.Name)
+		chipArgs = append(chipArgs,
+			Child(
+				Button(
+					ButtonType("button"),
+					Class(tagChipClass(selected)),
+					Aria("pressed", fmt.Sprintf("%t", selected)),
+					Custom("hx-get", partialURL(target)),
+					Custom("hx-target", "#todo-app"),
+					Custom("hx-swap", "outerHTML"),
+					Custom("hx-push-url", ListURL(target)),
+					T("#"+tag.Name),
+					Span(Class("tag-count"), T(fmt.Sprintf("%d", tag.Count))),
+				),
+			),
+		)
+	}
+
+	section = append(section, header, Div(chipArgs...))
+	return Div(section...)
+}
+
+// tagChips renders a todo's tags; clicking one adds it to the tag filter.
+func tagChips(tags []string, data PageData) Node {
+	args := []DivArg{Class("flex flex-wrap gap-1.5")}
+	for _, tag := range tags {
+		target := data.query()
+		target.Tags = store.NormalizeTags(append([]string{tag}, data.Tags...))
+		args = append(args,
+			Child(
+				Button(
+					ButtonType("button"),
+					Class(tagChipClass(containsTag(data.Tags, tag))),
+					Custom("hx-get", partialURL(target)),
+					Custom("hx-target", "#todo-app"),
+					Custom("hx-swap", "outerHTML"),
+					Custom("hx-push-url", ListURL(target)),
+					T("#"+tag),
+				),
+			),
+		)
+	}
+	return Div(args...)
+}
+
+func tagsField(prefix string, form TodoForm) Node {
+	return FormLabel(
+		Class("block space-y-2"),
+		For(prefix+"-tags"),
+		Span(Class("text-sm font-medium"), T("Tags")),
+		Input(
+			Id(prefix+"-tags"),
+			InputName("tags"),
+			InputValue(form.value("tags")),
+			Placeholder("work, errands (optional)"),
+			Class(inputClass),
+		),
+	)
+}
+
+// TagManagerDialog lets users rename or delete tags across every todo.
+func TagManagerDialog(data PageData) Node {
+	rows := []DivArg{Class("space-y-2 max-h-80 overflow-y-auto")}
+	if len(data.TagStats) == 0 {
+		rows = append(rows, Child(P(Class("text-sm text-muted-foreground"), T("No tags yet."))))
+	}
+	for _, tag := range data.TagStats {
+		rows = append(rows, Child(tagManagerRow(tag)))
+	}
+
+	return Dialog(
+		Id("tag-dialog"),
+		Class("modal"),
+		Child(
+			Div(
+				Class("space-y-4 p-6"),
+				Div(
+					Class("flex items-center gap-2"),
+					icons.Tags(icons.Size("20"), Class("text-muted-foreground")),
+					H2(Class("text-xl font-semibold"), T("Manage Tags")),
+				),
+				Div(rows...),
+				Div(
+					Class("flex pt-2"),
+					Button(
+						ButtonType("button"),
+						Class("flex-1 rounded-lg border border-border px-4 py-2 text-sm font-medium hover:bg-muted"),
+						Data("close-dialog", "tag-dialog"),
+						T("Done"),
+					),
+				),
+			),
+		),
+	)
+}
+
+func tagManagerRow(tag store.TagCount) Node {
+	return Form(
+		Class("flex items-center gap-2"),
+		Custom("hx-post", "/tags/rename"),
+		Custom("hx-target", "#todo-app"),
+		Custom("hx-swap", "outerHTML"),
+		Custom("hx-include", viewStateInclude),
+		Input(InputType("hidden"), InputName("from"), InputValue(tag.Name)),
+		Input(
+			InputName("to"),
+			InputValue(tag.Name),
+			Required(),
+			Aria("label", "Rename #"+tag.Name),
+			Class("flex-1 rounded-lg border bg-background px-3 py-1.5 text-sm focus:outline-none focus:ring-2 focus:ring-primary"),
+		),
+		Span(Class("w-8 text-right text-xs text-muted-foreground"), T(fmt.Sprintf("%d", tag.Count))),
+		Button(
+			ButtonType("submit"),
+			Class("rounded-lg border border-border px-3 py-1.5 text-xs font-medium hover:bg-muted"),
+			T("Rename"),
+		),
+		Button(
+			ButtonType("button"),
+			Class("inline-flex h-8 w-8 items-center justify-center rounded-lg text-muted-foreground hover:bg-destructive/10 hover:text-destructive"),
+			Aria("label", "Delete #"+tag.Name),
+			Custom("hx-post", "/tags/"+url.PathEscape(tag.Name)+"/delete"),
+			Custom("hx-target", "#todo-app"),
+			Custom("hx-swap", "outerHTML"),
+			Custom("hx-confirm", fmt.Sprintf("Remove #%s from every task?", tag.Name)),
+			Custom("hx-include", viewStateInclude),
+			icons.Trash2(icons.Size("16")),
+		),
+	)
+}
+
+func tagChipClass(selected bool) string {
+	if selected {
+		return "tag-chip is-active"
+	}
+	return "tag-chip"
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// toggleTag adds tag to the selection, or removes it if already selected.
+func toggleTag(selected []string, tag string) []string {
+	out := make([]string, 0, len(selected)+1)
+	for _, t := range selected {
+		if t != tag {
+			out = append(out, t)
+		}
+	}
+	if len(out) == len(selected) {
+		out = append(out, tag)
+	}
+	return store.NormalizeTags(out)
+}
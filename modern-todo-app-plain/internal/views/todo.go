@@ -2,7 +2,9 @@ package views
 
 import (
 	"fmt"
+	"net/url"
 	"strings"
+	"time"
 
 	"modern_todo_plain/internal/store"
 
@@ -11,13 +13,114 @@ import (
 )
 
 type PageData struct {
-	Todos  []store.Todo
+	// Todos is the first page of the view; Next loads the rest.
+	Todos []store.Todo
+	// Total counts every todo in the view, on all pages.
+	Total int
+	// Next continues the list after Todos; nil when every todo is shown.
+	Next   *store.Cursor
 	Filter store.Filter
-	Stats  store.Stats
+	Tags   []string
+	Search string
+	Sort   store.Sort
+	// ProjectID is the list being viewed; empty shows every list.
+	ProjectID string
+	Projects  []store.ProjectSummary
+	Stats     store.Stats
+	TagStats  []store.TagCount
+	Now       time.Time
+	// Reminders are the open todos, in any list, whose reminders have gone
+	// off.
+	Reminders []store.Todo
+	// FeedURL is the path of the secret iCalendar feed.
+	FeedURL string
+	// Toast reports the mutation that produced this render, if any.
+	Toast *Toast
+	// Revision is the store revision rendered, so live updates for changes
+	// the page already shows can be skipped.
+	Revision uint64
+	// CSRFToken is sent back with every htmx request from the page.
+	CSRFToken string
+	// Rejected is a form a plain form post submitted and the server turned
+	// down; the page shows it again, open, with the errors.
+	Rejected *RejectedForm
 }
 
+// RejectedForm is the state of a rejected todo form, named "add", "edit" or
+// "quick".
+type RejectedForm struct {
+	Name string
+	TodoForm
+	// Draft is what the quick-add text was read as, for its preview.
+	Draft store.Draft
+}
+
+// rejected returns the state of the named form if a plain post of it was
+// rejected.
+func (d PageData) rejected(name string) (TodoForm, bool) {
+	if d.Rejected == nil || d.Rejected.Name != name {
+		return TodoForm{}, false
+	}
+	return d.Rejected.TodoForm, true
+}
+
+// CSRFHeader is the request header that carries PageData.CSRFToken.
+const CSRFHeader = "X-CSRF-Token"
+
+// viewStateInclude selects the hidden inputs that carry the current filter
+// and tag selection into every htmx request.
+const viewStateInclude = ".todo-view-state"
+
+func (d PageData) query() store.Query {
+	return store.Query{Filter: d.Filter, Tags: d.Tags, Search: d.Search, Sort: d.Sort, ProjectID: d.ProjectID}
+}
+
+// manualOrder reports whether cards can be dragged: only the manual order is
+// meaningful to rearrange, and search results are ranked by relevance.
+func (d PageData) manualOrder() bool {
+	return (d.Sort == "" || d.Sort == store.SortManual) && d.Search == "" && d.Filter != store.FilterTrash
+}
+
+// ListURL returns the page URL for a list view. Views of a single list live
+// under /lists/{id}.
+func ListURL(query store.Query) string {
+	values := viewValues(query)
+	if query.ProjectID != "" {
+		return "/lists/" + url.PathEscape(query.ProjectID) + "?" + values.Encode()
+	}
+	return "/?" + values.Encode()
+}
+
+// viewValues encodes the view state other than the list as query parameters.
+func viewValues(query store.Query) url.Values {
+	values := url.Values{}
+	values.Set("filter", string(query.Filter))
+	for _, tag := range query.Tags {
+		values.Add("tag", tag)
+	}
+	if query.Search != "" {
+		values.Set("q", query.Search)
+	}
+	if query.Sort != "" && query.Sort != store.SortManual {
+		values.Set("sort", string(query.Sort))
+	}
+	return values
+}
+
+// partialURL returns the htmx URL that re-renders the app shell for a view.
+func partialURL(query store.Query) string {
+	return ListURL(query) + "&partial=app"
+}
+
+// TodoPage is the full page. The CSRF token sits on the wrapper, outside
+// #todo-app, so it survives swaps of the app and every htmx request inherits
+// it.
 func TodoPage(data PageData) Component {
-	return Layout("Modern Todo", appShell(data))
+	return Layout("Modern Todo", Div(
+		Custom("hx-headers", fmt.Sprintf(`{%q: %q}`, CSRFHeader, data.CSRFToken)),
+		appShell(data),
+		liveSync(),
+	))
 }
 
 func appShell(data PageData) Node {
@@ -27,14 +130,23 @@ func appShell(data PageData) Node {
 		todoSidebar(data),
 		Div(
 			Class("flex-1 flex flex-col"),
-			AppHeader(),
+			AppHeader(data.title(), data.Search, data.Rejected),
 			Main(
 				Class("flex-1 bg-background p-6"),
+				remindersPanel(data),
 				TodoListSection(data),
 			),
 		),
-		AddTodoDialog(data.Filter),
-		EditTodoDialog(data.Filter),
+		AddTodoDialog(data),
+		EditTodoDialog(data),
+		TagManagerDialog(data),
+		ProjectDialog(data),
+		TransferDialog(data),
+		historyControls(data),
+		conflictSlot(),
+		activitySlot(),
+		ShortcutDialog(),
+		revisionMarker(data.Revision),
 	)
 }
 
@@ -48,11 +160,16 @@ func todoSidebar(data PageData) Node {
 		{store.FilterAll, "All Tasks", icons.List(icons.Size("18")), data.Stats.Total},
 		{store.FilterActive, "Active", icons.Circle(icons.Size("18")), data.Stats.Active},
 		{store.FilterCompleted, "Completed", icons.CircleCheck(icons.Size("18")), data.Stats.Completed},
+		{store.FilterOverdue, "Overdue", icons.AlarmClock(icons.Size("18")), data.Stats.Overdue},
+		{store.FilterThisWeek, "Due this week", icons.CalendarDays(icons.Size("18")), data.Stats.ThisWeek},
+		{store.FilterTrash, "Trash", icons.Trash2(icons.Size("18")), data.Stats.Trashed},
 	}
 
 	items := make([]ChildOpt, 0, len(filters))
 	for _, f := range filters {
 		isActive := f.key == data.Filter
+		target := data.query()
+		target.Filter = f.key
 		buttonClass := "filter-button"
 		if isActive {
 			buttonClass += " is-active"
@@ -64,27 +181,22 @@ func todoSidebar(data PageData) Node {
 					ButtonType("button"),
 					Class(buttonClass),
 					Aria("pressed", fmt.Sprintf("%t", isActive)),
-					Custom("hx-get", fmt.Sprintf("/?filter=%s&partial=app", f.key)),
+					Data("shortcut-filter", string(f.key)),
+					Custom("hx-get", partialURL(target)),
 					Custom("hx-target", "#todo-app"),
 					Custom("hx-swap", "outerHTML"),
-					Custom("hx-push-url", fmt.Sprintf("/?filter=%s", f.key)),
+					Custom("hx-push-url", ListURL(target)),
 					Div(
 						Class("flex items-center gap-3"),
 						Span(Class("flex h-9 w-9 items-center justify-center rounded-lg bg-sidebar-muted"), Child(f.icon)),
 						Span(Class("font-medium"), T(f.label)),
 					),
-					Span(
-						Id(fmt.Sprintf("count-%s", f.key)),
-						Class("filter-count"),
-						T(fmt.Sprintf("%d", f.count)),
-					),
+					filterCount(f.key, f.count),
 				),
 			),
 		)
 	}
 
-	percent := completionPercent(data.Stats)
-
 	buttonArgs := make([]DivArg, len(items)+1)
 	buttonArgs[0] = Class("space-y-2")
 	for i, item := range items {
@@ -98,36 +210,52 @@ func todoSidebar(data PageData) Node {
 				Class("p-6 space-y-6"),
 				Div(buttonArgs...),
 				Div(
-					Class("space-y-2 border-t border-sidebar-muted pt-4"),
-					Div(
-						Class("flex items-center justify-between text-sm"),
-						Span(Class("text-sidebar-foreground"), T("Completion")),
-						Span(
-							Id("completion-label"),
-							Class("text-sidebar-accent font-semibold"),
-							T(fmt.Sprintf("%d%%", percent)),
-						),
-					),
-					Div(
-						Class("h-2 w-full rounded-full bg-sidebar-muted"),
-						Div(
-							Id("progress-bar"),
-							Class("h-2 rounded-full bg-sidebar-accent transition-all"),
-							Style(fmt.Sprintf("wdth: %d%%", percent)),
-						),
-					),
+					Class("space-y-2"),
+					sidebarLink("/activity", icons.Activity(icons.Size("18")), "Recent activity"),
+					sidebarLink("/stats", icons.ChartColumn(icons.Size("18")), "Stats"),
 				),
+				projectSidebarSection(data),
+				tagSidebarSection(data),
+				completionMeter(data.Stats),
 			),
 		),
 	)
 }
 
+// sidebarLink leads from the sidebar to a page of its own. The page script
+// adds the browser's time zone to the link, for pages that group by day.
+func sidebarLink(href string, icon Node, label string) Node {
+	return A(
+		Custom("href", href),
+		Class("filter-button"),
+		Data("zone-link", "true"),
+		Div(
+			Class("flex items-center gap-3"),
+			Span(Class("flex h-9 w-9 items-center justify-center rounded-lg bg-sidebar-muted"), icon),
+			Span(Class("font-medium"), T(label)),
+		),
+	)
+}
+
 func TodoListSection(data PageData) Node {
 	listChildren := []ChildOpt{
-		Child(Input(InputType("hidden"), Id("todo-current-filter"), InputName("filter"), InputValue(string(data.Filter)))),
+		Child(Input(InputType("hidden"), Id("todo-current-filter"), Class("todo-view-state"), InputName("filter"), InputValue(string(data.Filter)))),
+		Child(Input(InputType("hidden"), Id("todo-current-list"), Class("todo-view-state"), InputName("list"), InputValue(data.ProjectID))),
+	}
+	for _, tag := range data.Tags {
+		listChildren = append(listChildren,
+			Child(Input(InputType("hidden"), Class("todo-view-state"), InputName("tag"), InputValue(tag))),
+		)
 	}
 
 	if len(data.Todos) == 0 {
+		hint := "You're all caught up! Add a new task to get started."
+		if data.Filter == store.FilterTrash {
+			hint = "The trash is empty. Deleted tasks wait here before being removed for good."
+		}
+		if data.Search != "" {
+			hint = fmt.Sprintf("Nothing matches %q. Try a shorter or different word.", data.Search)
+		}
 		listChildren = append(listChildren,
 			Child(
 				Div(
@@ -137,13 +265,16 @@ func TodoListSection(data PageData) Node {
 						icons.ShieldCheck(icons.Size("48"), Class("text-muted-foreground")),
 					),
 					H3(Class("text-lg font-semibold"), T("No tasks found")),
-					P(Class("max-w-sm text-sm text-muted-foreground"), T("You're all caught up! Add a new task to get started.")),
+					P(Class("max-w-sm text-sm text-muted-foreground"), T(hint)),
 				),
 			),
 		)
 	} else {
 		for _, todo := range data.Todos {
-			listChildren = append(listChildren, Child(todoCard(todo)))
+			listChildren = append(listChildren, Child(todoCard(todo, data)))
+		}
+		if data.Next != nil {
+			listChildren = append(listChildren, Child(moreTodos(data.Next)))
 		}
 	}
 
@@ -155,11 +286,28 @@ func TodoListSection(data PageData) Node {
 	}
 
 	return Section(
+		Id("todo-results"),
+		Class("space-y-4"),
+		listHeader(data),
+		batchForm(data),
 		Div(listArgs...),
-	)
+		reorderForm(),
+	).WithAssets(reorderCSS, reorderJS, "todo-reorder")
 }
 
-func todoCard(todo store.Todo) Node {
+// todoPath is the URL of an action on one todo, such as /todos/3/toggle.
+func todoPath(id, action string) string {
+	return "/todos/" + url.PathEscape(id) + "/" + action
+}
+
+// CardID is the element id of a todo's card.
+func CardID(id string) string {
+	return "todo-" + id
+}
+
+func todoCard(todo store.Todo, data PageData) Node {
+	now := data.Now
+	terms := store.Tokenize(data.Search)
 	cardClass := "group rounded-xl border border-border bg-card/80 p-5 shadow-sm transition hover:shadow-md"
 	if todo.Completed {
 		cardClass += " opacity-80"
@@ -180,13 +328,39 @@ func todoCard(todo store.Todo) Node {
 			),
 		),
 	}
+	if due, ok := todo.DueLocal(); ok {
+		metaContent = append(metaContent,
+			Child(
+				Span(
+					Class(dueBadgeClass(todo, now)),
+					icons.Clock(icons.Size("12")),
+					T(dueLabel(todo, due, now)),
+				),
+			),
+		)
+	}
+	if todo.RemindMinutes != nil {
+		metaContent = append(metaContent, Child(reminderBadge(todo, data)))
+	}
+	if rule, ok := todo.Repeat(); ok {
+		metaContent = append(metaContent,
+			Child(
+				Span(
+					Class("inline-flex items-center gap-1"),
+					Title(todo.Recurrence),
+					icons.Repeat(icons.Size("12")),
+					T(rule.Describe()),
+				),
+			),
+		)
+	}
 
 	textContent := []ChildOpt{
-		Child(H3(Class(titleClasses(todo.Completed)), T(todo.Title))),
+		Child(H3(Class(titleClasses(todo.Completed)), highlight(todo.Title, terms))),
 	}
 	if todo.Description != "" {
 		textContent = append(textContent,
-			Child(P(Class(descriptionClasses(todo.Completed)), T(todo.Description))),
+			Child(P(Class(descriptionClasses(todo.Completed)), highlight(todo.Description, terms))),
 		)
 	}
 	metaArgs := make([]DivArg, len(metaContent)+1)
@@ -198,6 +372,9 @@ func todoCard(todo store.Todo) Node {
 	textContent = append(textContent,
 		Child(Div(metaArgs...)),
 	)
+	if len(todo.Tags) > 0 {
+		textContent = append(textContent, Child(tagChips(todo.Tags, data)))
+	}
 
 	textArgs := make([]DivArg, len(textContent)+1)
 	textArgs[0] = Class("space-y-2")
@@ -205,49 +382,81 @@ func todoCard(todo store.Todo) Node {
 		textArgs[i+1] = child
 	}
 
-	return Article(
-		Class(cardClass),
+	actions := todoActions(todo)
+	if todo.Trashed() {
+		actions = trashActions(todo)
+	}
+
+	rowArgs := []DivArg{Class("flex items-start gap-4")}
+	if !todo.Trashed() {
+		rowArgs = append(rowArgs, selectBox(todo))
+	}
+	if data.manualOrder() {
+		rowArgs = append(rowArgs, dragHandle())
+	}
+	bodyArgs := []DivArg{
+		Class("flex-1 space-y-3"),
 		Div(
-			Class("flex items-start gap-4"),
+			Class("flex items-start justify-between gap-3"),
+			Div(textArgs...),
+			actions,
+		),
+	}
+	// A trashed todo has to be restored before it can be checked off or
+	// have its checklist changed.
+	if !todo.Trashed() {
+		rowArgs = append(rowArgs,
 			Button(
 				ButtonType("button"),
 				Class(toggleButtonClasses(todo.Completed)),
-				Custom("hx-post", fmt.Sprintf("/todos/toggle?id=%s", todo.ID)),
-				Custom("hx-target", "#todo-app"),
+				Custom("hx-post", todoPath(todo.ID, "toggle")),
+				Custom("hx-target", "#"+CardID(todo.ID)),
 				Custom("hx-swap", "outerHTML"),
-				Custom("hx-include", "#todo-current-filter"),
+				Custom("hx-include", viewStateInclude),
 				icons.Check(icons.Size("16")),
 			),
-			Div(
-				Class("flex-1 space-y-3"),
-				Div(
-					Class("flex items-start justify-between gap-3"),
-					Div(textArgs...),
-					Div(
-						Class("flex items-center gap-1"),
-						Button(
-							ButtonType("button"),
-							Class("inline-flex h-8 w-8 items-center justify-center rounded-lg text-muted-foreground hover:bg-muted"),
-							Data("dialog-target", "edit-dialog"),
-							Data("edit-id", todo.ID),
-							Data("edit-title", todo.Title),
-							Data("edit-description", todo.Description),
-							Data("edit-priority", string(todo.Priority)),
-							icons.PencilLine(icons.Size("16")),
-						),
-						Button(
-							ButtonType("button"),
-							Class("inline-flex h-8 w-8 items-center justify-center rounded-lg text-muted-foreground hover:bg-destructive/10 hover:text-destructive"),
-							Custom("hx-post", fmt.Sprintf("/todos/delete?id=%s", todo.ID)),
-							Custom("hx-target", "#todo-app"),
-							Custom("hx-swap", "outerHTML"),
-							Custom("hx-confirm", "Delete this task?"),
-							Custom("hx-include", "#todo-current-filter"),
-							icons.Trash2(icons.Size("16")),
-						),
-					),
-				),
-			),
+		)
+		bodyArgs = append(bodyArgs, subtaskChecklist(todo))
+	}
+	rowArgs = append(rowArgs, Div(bodyArgs...))
+
+	return Article(
+		Id(CardID(todo.ID)),
+		Class(cardClass),
+		Data("todo-id", todo.ID),
+		Div(rowArgs...),
+	)
+}
+
+func todoActions(todo store.Todo) Node {
+	return Div(
+		Class("flex items-center gap-1"),
+		activityButton(todo),
+		Button(
+			ButtonType("button"),
+			Class("inline-flex h-8 w-8 items-center justify-center rounded-lg text-muted-foreground hover:bg-muted"),
+			Data("dialog-target", "edit-dialog"),
+			Data("edit-id", todo.ID),
+			Data("edit-title", todo.Title),
+			Data("edit-description", todo.Description),
+			Data("edit-priority", string(todo.Priority)),
+			Data("edit-due", dueInputValue(todo)),
+			Data("edit-due-zone", todo.DueZone),
+			Data("edit-remind", remindInputValue(todo)),
+			Data("edit-tags", strings.Join(todo.Tags, ", ")),
+			Data("edit-recurrence", todo.Recurrence),
+			Data("edit-project", todo.ProjectID),
+			Data("edit-version", fmt.Sprintf("%d", todo.Version)),
+			icons.PencilLine(icons.Size("16")),
+		),
+		Button(
+			ButtonType("button"),
+			Class("inline-flex h-8 w-8 items-center justify-center rounded-lg text-muted-foreground hover:bg-destructive/10 hover:text-destructive"),
+			Custom("hx-post", todoPath(todo.ID, "delete")),
+			Custom("hx-target", "#"+CardID(todo.ID)),
+			Custom("hx-swap", "outerHTML"),
+			Custom("hx-include", viewStateInclude),
+			icons.Trash2(icons.Size("16")),
 		),
 	)
 }
@@ -287,11 +496,51 @@ func priorityBadgeClass(priority store.Priority) string {
 	}
 }
 
+func dueBadgeClass(todo store.Todo, now time.Time) string {
+	base := "due-badge inline-flex items-center gap-1 rounded-full px-3 py-1 text-xs font-medium"
+	switch {
+	case todo.Completed:
+		return base + " due-done"
+	case todo.IsOverdue(now):
+		return base + " due-overdue"
+	case todo.IsDueThisWeek(now):
+		return base + " due-soon"
+	default:
+		return base + " due-later"
+	}
+}
+
+func dueLabel(todo store.Todo, due, now time.Time) string {
+	label := "Due " + due.Format("Jan 02, 15:04 MST")
+	if todo.IsOverdue(now) {
+		label = "Overdue · " + label
+	}
+	return label
+}
+
+// dueInputValue formats the due date for a datetime-local input in the zone
+// the todo was created in.
+func dueInputValue(todo store.Todo) string {
+	due, ok := todo.DueLocal()
+	if !ok {
+		return ""
+	}
+	return due.Format("2006-01-02T15:04")
+}
+
+// title is the page heading: the list name, or "Tasks" across every list.
+func (d PageData) title() string {
+	if project, ok := d.currentProject(); ok {
+		return project.Name
+	}
+	return "Tasks"
+}
+
 func completionPercent(stats store.Stats) int {
 	if stats.Total == 0 {
 		return 0
 	}
-	return int((float64(stats.Completed)/float64(stats.Total))*100 + 0.5)
+	return int(stats.Progress*100 + 0.5)
 }
 
 func capitalize(s string) string {
@@ -301,144 +550,103 @@ func capitalize(s string) string {
 	return strings.ToUpper(s[:1]) + s[1:]
 }
 
-func AddTodoDialog(filter store.Filter) Node {
-	return Dialog(
-		Id("add-dialog"),
-		Class("modal"),
-		Child(
-			Form(
-				Id("add-form"),
-				Class("space-y-4 p-6"),
-				Custom("hx-post", "/todos/create"),
-				Custom("hx-target", "#todo-app"),
-				Custom("hx-swap", "outerHTML"),
-				Custom("hx-include", "#todo-current-filter"),
-				Custom("hx-on::afterRequest", "if(event.detail.successful){ todoDialogs.closeDialog('add-dialog'); this.reset(); }"),
-				H2(Class("text-xl font-semibold"), T("Add New Task")),
-				FormLabel(
-					Class("block space-y-2"),
-					For("add-title"),
-					Span(Class("text-sm font-medium"), T("Title")),
-					Input(
-						Id("add-title"),
-						InputName("title"),
-						Required(),
-						Placeholder("What needs to be done?"),
-						Class("w-full rounded-lg border bg-background px-3 py-2 text-sm focus:outline-none focus:ring-2 focus:ring-primary"),
-					),
-				),
-				FormLabel(
-					Class("block space-y-2"),
-					For("add-description"),
-					Span(Class("text-sm font-medium"), T("Description")),
-					Textarea(
-						Id("add-description"),
-						TextareaName("description"),
-						Rows(3),
-						Placeholder("Add more details... (optional)"),
-						Class("w-full rounded-lg border bg-background px-3 py-2 text-sm focus:outline-none focus:ring-2 focus:ring-primary"),
-					),
-				),
-				FormLabel(
-					Class("block space-y-2"),
-					For("add-priority"),
-					Span(Class("text-sm font-medium"), T("Priority")),
-					Select(
-						Id("add-priority"),
-						Custom("name", "priority"),
-						Child(Option(Custom("value", string(store.PriorityLow)), T("Low"))),
-						Child(Option(Custom("value", string(store.PriorityMedium)), Selected(), T("Medium"))),
-						Child(Option(Custom("value", string(store.PriorityHigh)), T("High"))),
-						Class("w-full rounded-lg border bg-background px-3 py-2 text-sm"),
-					),
-				),
-				Input(InputType("hidden"), InputName("filter"), InputValue(string(filter))),
-				Div(
-					Class("flex gap-2 pt-2"),
-					Button(
-						ButtonType("button"),
-						Class("flex-1 rounded-lg border border-border px-4 py-2 text-sm font-medium hover:bg-muted"),
-						Data("close-dialog", "add-dialog"),
-						T("Cancel"),
-					),
-					Button(
-						ButtonType("submit"),
-						Class("flex-1 rounded-lg bg-primary px-4 py-2 text-sm font-medium text-primary-foreground hover:bg-primary/90"),
-						T("Add Task"),
-					),
-				),
+func AddTodoDialog(data PageData) Node {
+	form, open := data.rejected("add")
+	return todoDialog("add-dialog", open, AddTodoForm(data, form))
+}
+
+// todoDialog wraps a todo form in its modal. A form rejected on a plain post
+// renders open, so the page shows it without a script.
+func todoDialog(id string, open bool, form Node) Node {
+	if open {
+		return Dialog(Id(id), Class("modal"), Custom("open", "open"), Child(form))
+	}
+	return Dialog(Id(id), Class("modal"), Child(form))
+}
+
+// AddTodoForm is the form inside the add dialog. It is re-rendered on its
+// own, with the submitted values and errors, when a submission is rejected.
+func AddTodoForm(data PageData, form TodoForm) Node {
+	return Form(
+		Id("add-form"),
+		Class("space-y-4 p-6"),
+		Custom("hx-post", "/todos/create"),
+		Custom("hx-target", "#todo-results"),
+		Custom("hx-swap", "outerHTML"),
+		Custom("hx-include", viewStateInclude),
+		Custom("hx-on::afterRequest", "if(event.detail.successful){ todoDialogs.closeDialog('add-dialog'); this.reset(); }"),
+		H2(Class("text-xl font-semibold"), T("Add New Task")),
+		titleField("add", form),
+		descriptionField("add", form),
+		priorityField("add", form),
+		dueField("add", form),
+		remindField("add", form),
+		tagsField("add", form),
+		repeatField("add", form),
+		projectField("add", data, form.value("project"), form.Errors["project"]),
+		Input(InputType("hidden"), InputName("filter"), InputValue(string(data.Filter))),
+		Div(
+			Class("flex gap-2 pt-2"),
+			Button(
+				ButtonType("button"),
+				Class("flex-1 rounded-lg border border-border px-4 py-2 text-sm font-medium hover:bg-muted"),
+				Data("close-dialog", "add-dialog"),
+				T("Cancel"),
+			),
+			Button(
+				ButtonType("submit"),
+				Class("flex-1 rounded-lg bg-primary px-4 py-2 text-sm font-medium text-primary-foreground hover:bg-primary/90"),
+				T("Add Task"),
 			),
 		),
 	)
 }
 
-func EditTodoDialog(filter store.Filter) Node {
-	return Dialog(
-		Id("edit-dialog"),
-		Class("modal"),
-		Child(
-			Form(
-				Id("edit-form"),
-				Class("space-y-4 p-6"),
-				Custom("hx-post", "/todos/update"),
-				Custom("hx-target", "#todo-app"),
-				Custom("hx-swap", "outerHTML"),
-				Custom("hx-include", "#todo-current-filter"),
-				Custom("hx-on::afterRequest", "if(event.detail.successful){ todoDialogs.closeDialog('edit-dialog'); }"),
-				Input(InputType("hidden"), Id("edit-id"), InputName("id")),
-				Input(InputType("hidden"), Id("edit-filter"), InputName("filter"), InputValue(string(filter))),
-				H2(Class("text-xl font-semibold"), T("Edit Task")),
-				FormLabel(
-					Class("block space-y-2"),
-					For("edit-title"),
-					Span(Class("text-sm font-medium"), T("Title")),
-					Input(
-						Id("edit-title"),
-						InputName("title"),
-						Required(),
-						Placeholder("What needs to be done?"),
-						Class("w-full rounded-lg border bg-background px-3 py-2 text-sm focus:outline-none focus:ring-2 focus:ring-primary"),
-					),
-				),
-				FormLabel(
-					Class("block space-y-2"),
-					For("edit-description"),
-					Span(Class("text-sm font-medium"), T("Description")),
-					Textarea(
-						Id("edit-description"),
-						TextareaName("description"),
-						Rows(3),
-						Placeholder("Add more details... (optional)"),
-						Class("w-full rounded-lg border bg-background px-3 py-2 text-sm focus:outline-none focus:ring-2 focus:ring-primary"),
-					),
-				),
-				FormLabel(
-					Class("block space-y-2"),
-					For("edit-priority"),
-					Span(Class("text-sm font-medium"), T("Priority")),
-					Select(
-						Id("edit-priority"),
-						Custom("name", "priority"),
-						Child(Option(Custom("value", string(store.PriorityLow)), T("Low"))),
-						Child(Option(Custom("value", string(store.PriorityMedium)), T("Medium"))),
-						Child(Option(Custom("value", string(store.PriorityHigh)), T("High"))),
-						Class("w-full rounded-lg border bg-background px-3 py-2 text-sm"),
-					),
-				),
-				Div(
-					Class("flex gap-2 pt-2"),
-					Button(
-						ButtonType("button"),
-						Class("flex-1 rounded-lg border border-border px-4 py-2 text-sm font-medium hover:bg-muted"),
-						Data("close-dialog", "edit-dialog"),
-						T("Cancel"),
-					),
-					Button(
-						ButtonType("submit"),
-						Class("flex-1 rounded-lg bg-primary px-4 py-2 text-sm font-medium text-primary-foreground hover:bg-primary/90"),
-						T("Save Changes"),
-					),
-				),
+func EditTodoDialog(data PageData) Node {
+	form, open := data.rejected("edit")
+	return todoDialog("edit-dialog", open, EditTodoForm(data, form))
+}
+
+// EditTodoForm is the form inside the edit dialog; see AddTodoForm. The
+// dialog controller fills it in from the card being edited, including the
+// todo's update path; a form shown again after a rejected edit has it from
+// the "id" value.
+func EditTodoForm(data PageData, form TodoForm) Node {
+	action := ""
+	if id := form.value("id"); id != "" {
+		action = todoPath(id, "update")
+	}
+	return Form(
+		Id("edit-form"),
+		Class("space-y-4 p-6"),
+		Custom("hx-post", action),
+		Custom("hx-target", "#todo-results"),
+		Custom("hx-swap", "outerHTML"),
+		Custom("hx-include", viewStateInclude),
+		Custom("hx-on::afterRequest", "if(event.detail.successful){ todoDialogs.closeDialog('edit-dialog'); }"),
+		Input(InputType("hidden"), Id("edit-version"), InputName("version"), InputValue(form.value("version"))),
+		Input(InputType("hidden"), Id("edit-filter"), InputName("filter"), InputValue(string(data.Filter))),
+		H2(Class("text-xl font-semibold"), T("Edit Task")),
+		titleField("edit", form),
+		descriptionField("edit", form),
+		priorityField("edit", form),
+		dueField("edit", form),
+		remindField("edit", form),
+		tagsField("edit", form),
+		repeatField("edit", form),
+		projectField("edit", data, form.value("project"), form.Errors["project"]),
+		Div(
+			Class("flex gap-2 pt-2"),
+			Button(
+				ButtonType("button"),
+				Class("flex-1 rounded-lg border border-border px-4 py-2 text-sm font-medium hover:bg-muted"),
+				Data("close-dialog", "edit-dialog"),
+				T("Cancel"),
+			),
+			Button(
+				ButtonType("submit"),
+				Class("flex-1 rounded-lg bg-primary px-4 py-2 text-sm font-medium text-primary-foreground hover:bg-primary/90"),
+				T("Save Changes"),
 			),
 		),
 	)
@@ -451,3 +659,11 @@ func RenderFullPage(data PageData) string {
 func RenderAppShell(data PageData) string {
 	return Render(appShell(data))
 }
+
+func RenderTodoList(data PageData) string {
+	return Render(TodoListSection(data))
+}
+
+func RenderTodoCard(todo store.Todo, data PageData) string {
+	return Render(todoCard(todo, data))
+}
@@ -0,0 +1,224 @@
+package views
+
+import (
+	"fmt"
+
+	"modern_todo_plain/internal/store"
+
+	. "github.com/plainkit/html"
+	icons "github.com/plainkit/icons/lucide"
+)
+
+var formatLabels = map[store.Format]string{
+	store.FormatJSON:     "JSON",
+	store.FormatCSV:      "CSV",
+	store.FormatTodoTxt:  "todo.txt",
+	store.FormatMarkdown: "Markdown",
+	store.FormatICS:      "iCalendar",
+}
+
+// exportURL downloads the current view in the given format.
+func exportURL(query store.Query, format store.Format) string {
+	values := viewValues(query)
+	values.Set("format", string(format))
+	if query.ProjectID != "" {
+		values.Set("list", query.ProjectID)
+	}
+	return "/todos/export?" + values.Encode()
+}
+
+// TransferDialog offers downloads of the current view and an upload form with
+// a dry-run preview.
+func TransferDialog(data PageData) Node {
+	links := []DivArg{Class("grid grid-cols-2 gap-2")}
+	for _, format := range store.Formats {
+		links = append(links,
+			Child(
+				A(
+					Custom("href", exportURL(data.query(), format)),
+					Custom("download", ""),
+					Class("inline-flex items-center justify-center gap-2 rounded-lg border border-border px-3 py-2 text-sm font-medium hover:bg-muted"),
+					icons.Download(icons.Size("14")),
+					T(formatLabels[format]),
+				),
+			),
+		)
+	}
+
+	formatArgs := []SelectArg{
+		Id("import-format"),
+		Custom("name", "format"),
+		Class("w-full rounded-lg border bg-background px-3 py-2 text-sm"),
+		Child(Option(Custom("value", ""), Selected(), T("Detect from file name"))),
+	}
+	for _, format := range store.Formats {
+		formatArgs = append(formatArgs, Child(Option(Custom("value", string(format)), T(formatLabels[format]))))
+	}
+
+	return Dialog(
+		Id("transfer-dialog"),
+		Class("modal"),
+		Child(
+			Div(
+				Class("space-y-5 p-6"),
+				Div(
+					Class("flex items-center gap-2"),
+					icons.ArrowDownUp(icons.Size("20"), Class("text-muted-foreground")),
+					H2(Class("text-xl font-semibold"), T("Import & Export")),
+				),
+				Div(
+					Class("space-y-2"),
+					H3(Class("text-sm font-medium"), T("Export this view")),
+					Div(links...),
+				),
+				calendarFeed(data.FeedURL),
+				Form(
+					Id("import-form"),
+					Class("space-y-3 border-t border-border pt-4"),
+					Custom("enctype", "multipart/form-data"),
+					Custom("hx-encoding", "multipart/form-data"),
+					Custom("hx-post", "/todos/import"),
+					Custom("hx-target", "#todo-app"),
+					Custom("hx-swap", "outerHTML"),
+					Custom("hx-include", viewStateInclude),
+					Custom("hx-on::afterRequest", "if(event.detail.elt === this && event.detail.successful){ todoDialogs.closeDialog('transfer-dialog'); }"),
+					H3(Class("text-sm font-medium"), T("Import")),
+					Input(
+						InputType("file"),
+						InputName("file"),
+						Required(),
+						Custom("accept", ".json,.csv,.txt,.md,.ics"),
+						Aria("label", "File to import"),
+						Class("w-full text-sm"),
+					),
+					FormLabel(
+						Class("block space-y-2"),
+						For("import-format"),
+						Span(Class("text-sm font-medium"), T("Format")),
+						Select(formatArgs...),
+					),
+					projectField("import", data, "", ""),
+					Input(InputType("hidden"), Id("import-due-zone"), InputName("due_zone")),
+					Div(Id("import-preview"), Class("space-y-2")),
+					Div(
+						Class("flex gap-2 pt-2"),
+						Button(
+							ButtonType("button"),
+							Class("flex-1 rounded-lg border border-border px-4 py-2 text-sm font-medium hover:bg-muted"),
+							Custom("hx-post", "/todos/import?dry_run=1"),
+							Custom("hx-target", "#import-preview"),
+							Custom("hx-swap", "innerHTML"),
+							T("Preview"),
+						),
+						Button(
+							ButtonType("submit"),
+							Class("flex-1 rounded-lg bg-primary px-4 py-2 text-sm font-medium text-primary-foreground hover:bg-primary/90"),
+							T("Import"),
+						),
+					),
+				),
+				Div(
+					Class("flex"),
+					Button(
+						ButtonType("button"),
+						Class("flex-1 rounded-lg border border-border px-4 py-2 text-sm font-medium hover:bg-muted"),
+						Data("close-dialog", "transfer-dialog"),
+						T("Done"),
+					),
+				),
+			),
+		),
+	)
+}
+
+// calendarFeed shows the secret feed URL for calendar apps. The field expands
+// the path to an absolute URL when focused so it can be copied.
+func calendarFeed(feedURL string) Node {
+	return Div(
+		Class("space-y-2 border-t border-border pt-4"),
+		H3(Class("text-sm font-medium"), T("Calendar feed")),
+		P(Class("text-xs text-muted-foreground"), T("Subscribe to this private URL in your calendar app to see your tasks. Anyone with the link can read them.")),
+		Div(
+			Class("flex items-center gap-2"),
+			Input(
+				Id("calendar-feed-url"),
+				InputValue(feedURL),
+				Custom("readonly", "readonly"),
+				Aria("label", "Calendar feed URL"),
+				Custom("onfocus", "this.value = new URL(this.value, location.href).href; this.select();"),
+				Class("flex-1 rounded-lg border bg-muted px-3 py-1.5 text-xs font-mono"),
+			),
+			Button(
+				ButtonType("button"),
+				Class("inline-flex items-center gap-1 rounded-lg border border-border px-3 py-1.5 text-xs font-medium hover:bg-muted"),
+				Custom("hx-post", "/calendar/rotate"),
+				Custom("hx-target", "#todo-app"),
+				Custom("hx-swap", "outerHTML"),
+				Custom("hx-confirm", "Create a new feed URL? Calendars subscribed to the old one will stop updating."),
+				Custom("hx-include", viewStateInclude),
+				icons.RefreshCw(icons.Size("12")),
+				T("New URL"),
+			),
+		),
+	)
+}
+
+// importPreview lists what a dry-run import would add and skip.
+func importPreview(result store.ImportResult) Node {
+	summary := fmt.Sprintf("%d new, %d duplicates", len(result.Added), len(result.Duplicates))
+
+	rows := []UlArg{Class("max-h-48 space-y-1 overflow-y-auto text-sm")}
+	for _, todo := range result.Added {
+		rows = append(rows, Child(importRow(todo, false)))
+	}
+	for _, todo := range result.Duplicates {
+		rows = append(rows, Child(importRow(todo, true)))
+	}
+
+	return Div(
+		Class("space-y-2 rounded-lg border border-border p-3"),
+		P(Class("text-sm font-medium"), T(summary)),
+		Ul(rows...),
+	)
+}
+
+func importRow(todo store.Todo, duplicate bool) Node {
+	status := Span(Class("text-xs font-medium text-primary"), T("new"))
+	if duplicate {
+		status = Span(Class("text-xs font-medium text-muted-foreground"), T("duplicate, skipped"))
+	}
+
+	meta := capitalize(string(todo.Priority))
+	if due, ok := todo.DueLocal(); ok {
+		meta += " · due " + due.Format("Jan 02")
+	}
+	if todo.Completed {
+		meta += " · done"
+	}
+
+	return Li(
+		Class("flex items-center justify-between gap-3"),
+		Span(
+			Class("min-w-0 truncate"),
+			T(todo.Title),
+			Span(Class("ml-2 text-xs text-muted-foreground"), T(meta)),
+		),
+		status,
+	)
+}
+
+func importError(message string) Node {
+	return P(
+		Class("rounded-lg border border-destructive/40 bg-destructive/10 px-3 py-2 text-sm text-destructive"),
+		Role("alert"),
+		T(message),
+	)
+}
+
+func RenderImportPreview(result store.ImportResult) string {
+	return Render(importPreview(result))
+}
+
+func RenderImportError(message string) string {
+	return Render(importError(message))
+}
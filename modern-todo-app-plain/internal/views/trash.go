@@ -0,0 +1,57 @@
+package views
+
+import (
+	"modern_todo_plain/internal/store"
+
+	. "github.com/plainkit/html"
+	icons "github.com/plainkit/icons/lucide"
+)
+
+// trashActions replaces the edit and delete buttons on trashed cards.
+func trashActions(todo store.Todo) Node {
+	deleted := ""
+	if todo.DeletedAt != nil {
+		deleted = "Deleted " + todo.DeletedAt.Local().Format("Jan 02, 15:04")
+	}
+
+	return Div(
+		Class("flex items-center gap-1"),
+		Span(Class("mr-2 text-xs text-muted-foreground"), T(deleted)),
+		activityButton(todo),
+		Button(
+			ButtonType("button"),
+			Class("inline-flex h-8 items-center gap-1 rounded-lg px-2 text-xs font-medium text-muted-foreground hover:bg-muted"),
+			Custom("hx-post", todoPath(todo.ID, "restore")),
+			Custom("hx-target", "#"+CardID(todo.ID)),
+			Custom("hx-swap", "outerHTML"),
+			Custom("hx-include", viewStateInclude),
+			icons.ArchiveRestore(icons.Size("14")),
+			T("Restore"),
+		),
+		Button(
+			ButtonType("button"),
+			Class("inline-flex h-8 w-8 items-center justify-center rounded-lg text-muted-foreground hover:bg-destructive/10 hover:text-destructive"),
+			Aria("label", "Delete forever"),
+			Custom("hx-post", todoPath(todo.ID, "purge")),
+			Custom("hx-target", "#"+CardID(todo.ID)),
+			Custom("hx-swap", "outerHTML"),
+			Custom("hx-confirm", "Delete this task forever? This cannot be undone."),
+			Custom("hx-include", viewStateInclude),
+			icons.Trash2(icons.Size("16")),
+		),
+	)
+}
+
+func emptyTrashButton() Node {
+	return Button(
+		ButtonType("button"),
+		Class("inline-flex items-center gap-1 rounded-lg border border-border px-3 py-1.5 text-sm font-medium text-destructive hover:bg-destructive/10"),
+		Custom("hx-post", "/todos/trash/empty"),
+		Custom("hx-target", "#todo-app"),
+		Custom("hx-swap", "outerHTML"),
+		Custom("hx-confirm", "Permanently delete every task in the trash?"),
+		Custom("hx-include", viewStateInclude),
+		icons.Trash2(icons.Size("14")),
+		T("Empty trash"),
+	)
+}
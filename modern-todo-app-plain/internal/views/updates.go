@@ -0,0 +1,159 @@
+package views
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"modern_todo_plain/internal/store"
+
+	. "github.com/plainkit/html"
+)
+
+// oob marks an element in a partial response for an out-of-band swap: htmx
+// replaces the element with the same id wherever it is on the page.
+var oob = Custom("hx-swap-oob", "true")
+
+// divArgs turns the extra attributes a fragment takes, such as oob, into
+// arguments for its root div.
+func divArgs(attrs ...Global) []DivArg {
+	args := make([]DivArg, len(attrs))
+	for i, attr := range attrs {
+		args[i] = attr
+	}
+	return args
+}
+
+// spanArgs is divArgs for a root span.
+func spanArgs(attrs ...Global) []SpanArg {
+	args := make([]SpanArg, len(attrs))
+	for i, attr := range attrs {
+		args[i] = attr
+	}
+	return args
+}
+
+func filterCount(key store.Filter, count int, attrs ...Global) Node {
+	args := []SpanArg{
+		Id(fmt.Sprintf("count-%s", key)),
+		Class("filter-count"),
+		T(fmt.Sprintf("%d", count)),
+	}
+	args = append(args, spanArgs(attrs...)...)
+	return Span(args...)
+}
+
+// projectCount shows a list's active todos; the empty id is "All lists".
+func projectCount(id string, active int, attrs ...Global) Node {
+	elementID := "count-lists"
+	if id != "" {
+		elementID = "count-list-" + id
+	}
+	args := []SpanArg{Id(elementID), Class("tag-count"), T(fmt.Sprintf("%d", active))}
+	args = append(args, spanArgs(attrs...)...)
+	return Span(args...)
+}
+
+// resultCount is the number of todos shown next to the list title.
+func resultCount(count int, attrs ...Global) Node {
+	args := []SpanArg{
+		Id("todo-count"),
+		Class("ml-2 text-sm font-normal text-muted-foreground"),
+		T(fmt.Sprintf("%d", count)),
+	}
+	args = append(args, spanArgs(attrs...)...)
+	return Span(args...)
+}
+
+func completionMeter(stats store.Stats, attrs ...Global) Node {
+	percent := completionPercent(stats)
+	args := []DivArg{
+		Id("completion-meter"),
+		Class("space-y-2 border-t border-sidebar-muted pt-4"),
+		Div(
+			Class("flex items-center justify-between text-sm"),
+			Span(Class("text-sidebar-foreground"), T("Completion")),
+			Span(
+				Id("completion-label"),
+				Class("text-sidebar-accent font-semibold"),
+				T(fmt.Sprintf("%d%%", percent)),
+			),
+		),
+		Div(
+			Class("h-2 w-full rounded-full bg-sidebar-muted"),
+			Div(
+				Id("progress-bar"),
+				Class("h-2 rounded-full bg-sidebar-accent transition-all"),
+				Style(fmt.Sprintf("width: %d%%", percent)),
+			),
+		),
+	}
+	args = append(args, divArgs(attrs...)...)
+	return Div(args...)
+}
+
+// revisionMarker records the store revision the page shows, for live updates.
+func revisionMarker(revision uint64, attrs ...Global) Node {
+	args := []SpanArg{
+		Id("todo-revision"),
+		Class("hidden"),
+		Data("revision", strconv.FormatUint(revision, 10)),
+	}
+	args = append(args, spanArgs(attrs...)...)
+	return Span(args...)
+}
+
+// pageUpdates re-renders everything outside the list section that a mutation
+// can change, for responses that replace only part of the page: the sidebar
+// counts, tags and progress bar, the reminders, the clear completed button,
+// the undo toast and the revision.
+func pageUpdates(data PageData) []Node {
+	nodes := []Node{
+		filterCount(store.FilterAll, data.Stats.Total, oob),
+		filterCount(store.FilterActive, data.Stats.Active, oob),
+		filterCount(store.FilterCompleted, data.Stats.Completed, oob),
+		filterCount(store.FilterOverdue, data.Stats.Overdue, oob),
+		filterCount(store.FilterThisWeek, data.Stats.ThisWeek, oob),
+		filterCount(store.FilterTrash, data.Stats.Trashed, oob),
+	}
+
+	total := 0
+	for _, project := range data.Projects {
+		total += project.Stats.Active
+		nodes = append(nodes, projectCount(project.ID, project.Stats.Active, oob))
+	}
+	return append(nodes,
+		projectCount("", total, oob),
+		tagSidebarSection(data, oob),
+		completionMeter(data.Stats, oob),
+		remindersPanel(data, oob),
+		clearCompletedButton(data, oob),
+		historyControls(data, oob),
+		revisionMarker(data.Revision, oob),
+	)
+}
+
+// RenderCardUpdate renders the card of todo, or nothing when it has left the
+// view, followed by the result count and the out-of-band page updates.
+func RenderCardUpdate(todo store.Todo, inView bool, data PageData) string {
+	var b strings.Builder
+	if inView {
+		b.WriteString(Render(todoCard(todo, data)))
+	}
+	b.WriteString(Render(resultCount(data.Total, oob)))
+	for _, node := range pageUpdates(data) {
+		b.WriteString(Render(node))
+	}
+	return b.String()
+}
+
+// RenderResultsUpdate renders the list section followed by the out-of-band
+// page updates. The list section carries its own result count.
+func RenderResultsUpdate(data PageData) string {
+	var b strings.Builder
+	b.WriteString(Render(TodoListSection(data)))
+	for _, node := range pageUpdates(data) {
+		b.WriteString(Render(node))
+	}
+	return b.String()
+}